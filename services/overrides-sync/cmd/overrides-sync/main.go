@@ -2,17 +2,21 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/AkshayDubey29/mimir-edge-enforcement/services/overrides-sync/internal/controller"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -24,15 +28,56 @@ var (
 
 	// Mimir configuration
 	mimirNamespace     = flag.String("mimir-namespace", "mimir", "Namespace where Mimir is deployed")
-	overridesConfigMap = flag.String("overrides-configmap", "mimir-overrides", "Name of the overrides ConfigMap")
+	overridesConfigMap = flag.String("overrides-configmap", "mimir-overrides", "Name of the overrides ConfigMap; a comma-separated list is merged in order, later ones winning")
+
+	// 🔧 NEW: Where the overrides are read from
+	overridesSource       = flag.String("source", "configmap", "Overrides source: configmap, file, http or mimir")
+	overridesFile         = flag.String("overrides-file", "", "Path of the Mimir runtime config file for -source=file; a comma-separated list is merged in order")
+	overridesURL          = flag.String("overrides-url", "", "URL of the runtime config for -source=http, e.g. http://mimir-distributor.mimir:8080/runtime_config")
+	overridesURLTokenFile = flag.String("overrides-url-auth-token-file", "", "Path to a file containing a bearer token sent to -overrides-url and -mimir-url")
+	mimirURL              = flag.String("mimir-url", "", "Base URL of a Mimir component for -source=mimir, e.g. http://mimir-distributor.mimir:8080; effective limits are read from /config and /runtime_config?mode=diff")
+	sourcePollInterval    = flag.Duration("source-poll-interval", 15*time.Second, "How often file and http sources are re-read for changes; file sources also follow file-system notifications")
 
 	// RLS configuration
 	rlsHost      = flag.String("rls-host", "mimir-rls.mimir-edge-enforcement.svc.cluster.local", "RLS service host")
 	rlsAdminPort = flag.String("rls-admin-port", "8082", "RLS admin port")
+	// 🔧 NEW: Bearer token for RLS admin API authentication
+	rlsAuthTokenFile = flag.String("rls-auth-token-file", "", "Path to a file containing the bearer token for the RLS admin API")
+	// 🔧 NEW: Send the limits to every RLS replica rather than to one behind the Service
+	rlsEndpoints   = flag.String("rls-endpoints", "", "namespace/name of the RLS Service whose ready endpoints each receive the limits on -rls-admin-port; empty sends to -rls-host only")
+	resyncInterval = flag.Duration("resync-interval", time.Minute, "How often the last limits are re-sent to replicas that do not have them yet, and the ConfigMap informers' resync period (0 disables)")
 
 	// Controller configuration
 	pollFallbackSeconds = flag.Int("poll-fallback-seconds", 30, "Poll interval in seconds when watch fails")
 
+	// 🔧 NEW: Leader election so several replicas can run with only one syncing
+	leaderElect               = flag.Bool("leader-elect", false, "Sync only while holding a Lease, so several replicas can run for HA")
+	leaderElectionNamespace   = flag.String("leader-election-namespace", "", "Namespace of the leader Lease (default: $POD_NAMESPACE, else mimir-edge-enforcement)")
+	leaderElectionLease       = flag.String("leader-election-lease", "overrides-sync", "Name of the leader Lease")
+	leaderElectionLeaseTime   = flag.Duration("leader-election-lease-duration", 15*time.Second, "How long followers wait before taking over a Lease that is not renewed")
+	leaderElectionRenewTime   = flag.Duration("leader-election-renew-deadline", 10*time.Second, "How long the leader keeps trying to renew the Lease before giving it up")
+	leaderElectionRetryPeriod = flag.Duration("leader-election-retry-period", 2*time.Second, "How often the Lease is tried to be acquired or renewed")
+
+	// 🔧 NEW: Removal of tenants that disappear from the overrides
+	prune              = flag.Bool("prune", false, "Remove tenants from RLS once they disappear from the overrides; without it they are only reported")
+	removalPolicy      = flag.String("removal-policy", "reset", "How removed tenants are handled: reset (fall back to RLS defaults and profile) or delete (forget the tenant)")
+	tombstoneGrace     = flag.Duration("tombstone-grace", 5*time.Minute, "How long a tenant must stay missing from the overrides before it is removed")
+	maxRemovalsPerSync = flag.Int("max-removals-per-sync", 10, "A sync that would remove more tenants than this removes none (0 = unlimited)")
+
+	// 🔧 NEW: Edge-only tenant settings from EdgeEnforcementPolicy resources
+	policiesEnabled = flag.Bool("policies", false, "Watch EdgeEnforcementPolicy resources and send their settings to RLS with the tenant limits (requires the CRD)")
+	policyNamespace = flag.String("policy-namespace", "", "Namespace of the EdgeEnforcementPolicy resources (default: every namespace)")
+
+	// 🔧 NEW: Self-service tenants declared by edge.mimir annotations on Kubernetes objects
+	annotationsEnabled = flag.Bool("annotations", false, "Watch Kubernetes objects for edge.mimir/tenant and edge.mimir/<limit> annotations and sync the tenants they declare")
+	annotationResource = flag.String("annotation-resource", "namespaces", "Resource carrying the annotations, as resource.version.group (e.g. namespaces, configmaps.v1., deployments.v1.apps)")
+	annotationSelector = flag.String("annotation-selector", "", "Label selector for the annotated objects (default: every object of the resource)")
+	annotationBindings = flag.String("annotation-tenant-bindings", "", "Comma-separated namespace=tenant pairs letting objects of a namespace declare tenants not named like it; tenant * allows any")
+
+	// 🔧 NEW: Dry run for CI: print what a sync would change and exit
+	diffMode   = flag.Bool("diff", false, "Compare the overrides with the limits RLS holds for each tenant, print the changes a sync would make and exit without changing anything. Exit code 0: no changes, 1: error, 2: changes, 3: invalid tenant overrides")
+	diffOutput = flag.String("diff-output", "text", "Output of -diff: text or json")
+
 	// Server configuration
 	metricsPort = flag.String("metrics-port", "9090", "Port for metrics HTTP server")
 
@@ -51,30 +96,126 @@ func main() {
 	zerolog.SetGlobalLevel(level)
 	logger := log.With().Str("component", "overrides-sync").Logger()
 
-	// Create Kubernetes client
-	k8sClient, err := createK8sClient()
+	// Load RLS admin API token
+	var rlsAuthToken string
+	if *rlsAuthTokenFile != "" {
+		token, err := os.ReadFile(*rlsAuthTokenFile)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("failed to read RLS auth token file")
+		}
+		rlsAuthToken = strings.TrimSpace(string(token))
+	}
+
+	switch *diffOutput {
+	case "text", "json":
+	default:
+		logger.Fatal().Str("diff_output", *diffOutput).Msg("invalid diff output: expected text or json")
+	}
+
+	switch *removalPolicy {
+	case controller.RemovalPolicyReset, controller.RemovalPolicyDelete:
+	default:
+		logger.Fatal().Str("removal_policy", *removalPolicy).Msg("invalid removal policy: expected reset or delete")
+	}
+
+	// Create the overrides source
+	source, err := createSource()
 	if err != nil {
-		logger.Fatal().Err(err).Msg("failed to create Kubernetes client")
+		logger.Fatal().Err(err).Msg("failed to create overrides source")
+	}
+
+	// Discover RLS replicas
+	var replicas controller.ReplicaDiscovery
+	if *rlsEndpoints != "" {
+		namespace, service, ok := strings.Cut(*rlsEndpoints, "/")
+		if !ok || namespace == "" || service == "" {
+			logger.Fatal().Str("rls_endpoints", *rlsEndpoints).Msg("invalid -rls-endpoints: expected namespace/name")
+		}
+		k8sClient, err := createK8sClient()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("failed to create Kubernetes client for RLS replica discovery")
+		}
+		replicas = controller.NewEndpointsDiscovery(k8sClient, namespace, service, *rlsAdminPort)
+	}
+
+	// Watch EdgeEnforcementPolicies
+	var policies *controller.PolicyWatcher
+	if *policiesEnabled {
+		dynamicClient, err := createDynamicClient()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("failed to create Kubernetes client for EdgeEnforcementPolicies")
+		}
+		policies = controller.NewPolicyWatcher(dynamicClient, *policyNamespace, *resyncInterval)
+	}
+
+	// Watch annotated objects
+	var annotations *controller.AnnotationWatcher
+	if *annotationsEnabled {
+		resource, err := controller.ParseAnnotationResource(*annotationResource)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("invalid -annotation-resource")
+		}
+		dynamicClient, err := createDynamicClient()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("failed to create Kubernetes client for tenant annotations")
+		}
+		bindings, err := controller.ParseTenantBindings(*annotationBindings)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("invalid -annotation-tenant-bindings")
+		}
+		annotations, err = controller.NewAnnotationWatcher(dynamicClient, resource, *annotationSelector, bindings, *resyncInterval)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("invalid -annotation-selector")
+		}
+	}
+
+	if *diffMode {
+		os.Exit(runDiff(controller.NewController(&controller.Config{
+			RLSHost:       *rlsHost,
+			RLSAdminPort:  *rlsAdminPort,
+			RLSAuthToken:  rlsAuthToken,
+			Prune:         *prune,
+			RemovalPolicy: *removalPolicy,
+			Policies:      policies,
+			Annotations:   annotations,
+		}, source, logger), logger))
+	}
+
+	// Configure leader election
+	var leaderElection *controller.LeaderElectionConfig
+	if *leaderElect {
+		leaderElection, err = createLeaderElectionConfig()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("failed to configure leader election")
+		}
 	}
 
 	// Create controller configuration
 	config := &controller.Config{
-		MimirNamespace:      *mimirNamespace,
-		OverridesConfigMap:  *overridesConfigMap,
 		RLSHost:             *rlsHost,
 		RLSAdminPort:        *rlsAdminPort,
+		RLSAuthToken:        rlsAuthToken,
 		PollFallbackSeconds: *pollFallbackSeconds,
+		Prune:               *prune,
+		RemovalPolicy:       *removalPolicy,
+		TombstoneGrace:      *tombstoneGrace,
+		MaxRemovalsPerSync:  *maxRemovalsPerSync,
+		Replicas:            replicas,
+		ResyncInterval:      *resyncInterval,
+		LeaderElection:      leaderElection,
+		Policies:            policies,
+		Annotations:         annotations,
 	}
 
 	// Create controller
-	ctrl := controller.NewController(config, k8sClient, logger)
+	ctrl := controller.NewController(config, source, logger)
 
 	// Create context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	// Start metrics server
-	go startMetricsServer(ctx, *metricsPort, logger)
+	go startMetricsServer(ctx, *metricsPort, ctrl, logger)
 
 	// Start controller
 	go func() {
@@ -102,7 +243,171 @@ func main() {
 	logger.Info().Msg("overrides-sync controller stopped")
 }
 
+// createSource builds the overrides source selected by -source
+func createSource() (controller.Source, error) {
+	switch *overridesSource {
+	case "configmap":
+		k8sClient, err := createK8sClient()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+		}
+		names := splitList(*overridesConfigMap)
+		if len(names) == 0 {
+			return nil, fmt.Errorf("-overrides-configmap is required for -source=configmap")
+		}
+		return controller.NewConfigMapSource(k8sClient, *mimirNamespace, *resyncInterval, names...), nil
+	case "file":
+		paths := splitList(*overridesFile)
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("-overrides-file is required for -source=file")
+		}
+		return controller.NewFileSource(*sourcePollInterval, paths...), nil
+	case "http":
+		if *overridesURL == "" {
+			return nil, fmt.Errorf("-overrides-url is required for -source=http")
+		}
+		token, err := readOverridesURLToken()
+		if err != nil {
+			return nil, err
+		}
+		return controller.NewHTTPSource(*overridesURL, token, *sourcePollInterval), nil
+	case "mimir":
+		if *mimirURL == "" {
+			return nil, fmt.Errorf("-mimir-url is required for -source=mimir")
+		}
+		token, err := readOverridesURLToken()
+		if err != nil {
+			return nil, err
+		}
+		return controller.NewMimirAPISource(*mimirURL, token, *sourcePollInterval), nil
+	default:
+		return nil, fmt.Errorf("invalid source %q: expected configmap, file, http or mimir", *overridesSource)
+	}
+}
+
+// Exit codes of -diff
+const (
+	diffExitUnchanged = 0
+	diffExitError     = 1
+	diffExitChanges   = 2
+	diffExitInvalid   = 3
+)
+
+// runDiff prints what syncing the overrides would change in RLS and returns the exit code
+func runDiff(ctrl *controller.Controller, logger zerolog.Logger) int {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	report, err := ctrl.Diff(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("diff failed")
+		return diffExitError
+	}
+
+	switch *diffOutput {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			logger.Error().Err(err).Msg("failed to write diff")
+			return diffExitError
+		}
+	default:
+		report.WriteText(os.Stdout)
+	}
+
+	switch {
+	case len(report.Invalid) > 0:
+		return diffExitInvalid
+	case len(report.Changes) > 0:
+		return diffExitChanges
+	default:
+		return diffExitUnchanged
+	}
+}
+
+// createLeaderElectionConfig identifies this instance by its hostname, which is the pod name in Kubernetes
+func createLeaderElectionConfig() (*controller.LeaderElectionConfig, error) {
+	k8sClient, err := createK8sClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	identity, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine identity: %w", err)
+	}
+	namespace := *leaderElectionNamespace
+	if namespace == "" {
+		namespace = os.Getenv("POD_NAMESPACE")
+	}
+	if namespace == "" {
+		namespace = "mimir-edge-enforcement"
+	}
+	return &controller.LeaderElectionConfig{
+		Client:        k8sClient,
+		Namespace:     namespace,
+		LeaseName:     *leaderElectionLease,
+		Identity:      identity,
+		LeaseDuration: *leaderElectionLeaseTime,
+		RenewDeadline: *leaderElectionRenewTime,
+		RetryPeriod:   *leaderElectionRetryPeriod,
+	}, nil
+}
+
+// readOverridesURLToken reads the bearer token for HTTP sources, if one is configured
+func readOverridesURLToken() (string, error) {
+	if *overridesURLTokenFile == "" {
+		return "", nil
+	}
+	content, err := os.ReadFile(*overridesURLTokenFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read overrides URL auth token file: %w", err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var out []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
 func createK8sClient() (*kubernetes.Clientset, error) {
+	config, err := createRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	return clientset, nil
+}
+
+// createDynamicClient creates a client for custom resources such as EdgeEnforcementPolicy
+func createDynamicClient() (dynamic.Interface, error) {
+	config, err := createRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	return client, nil
+}
+
+// createRESTConfig loads -kubeconfig, or the in-cluster config without it
+func createRESTConfig() (*rest.Config, error) {
 	var config *rest.Config
 	var err error
 
@@ -117,26 +422,40 @@ func createK8sClient() (*kubernetes.Clientset, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create config: %w", err)
 	}
-
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create clientset: %w", err)
-	}
-
-	return clientset, nil
+	return config, nil
 }
 
-func startMetricsServer(ctx context.Context, port string, logger zerolog.Logger) {
-	// This would serve Prometheus metrics
-	// For now, just log that it's started
+func startMetricsServer(ctx context.Context, port string, ctrl *controller.Controller, logger zerolog.Logger) {
 	logger.Info().Str("port", port).Msg("metrics HTTP server started")
 
+	// 🔧 NEW: Prometheus metrics of the controller
+	http.Handle("/metrics", promhttp.Handler())
+
 	// Simple health check endpoint
 	http.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("ok"))
 	})
 
+	// 🔧 NEW: Tenants whose overrides failed validation in the last sync, with the reasons
+	http.HandleFunc("/validation", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"invalid_tenants": ctrl.ValidationErrors(),
+		})
+	})
+
+	// 🔧 NEW: Where RLS and Mimir disagree on tenant limits, as of the last sync
+	http.HandleFunc("/reconciliation", func(w http.ResponseWriter, r *http.Request) {
+		report := ctrl.Report()
+		if report == nil {
+			http.Error(w, "no sync has completed yet", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	})
+
 	server := &http.Server{
 		Addr: ":" + port,
 	}
@@ -0,0 +1,297 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/overrides-sync/internal/limits"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Annotations that make a Kubernetes object declare a tenant. Every other annotation with the prefix names a limit,
+// with dashes for underscores: edge.mimir/samples-per-second, edge.mimir/max-global-series-per-user, ...
+const (
+	TenantAnnotationPrefix = "edge.mimir/"
+	TenantAnnotation       = TenantAnnotationPrefix + "tenant"
+)
+
+// ParseAnnotationResource parses a resource as resource.version.group, e.g. "namespaces", "configmaps.v1." or
+// "deployments.v1.apps". Without a version the core v1 API is assumed.
+func ParseAnnotationResource(resource string) (schema.GroupVersionResource, error) {
+	if strings.TrimSpace(resource) == "" {
+		return schema.GroupVersionResource{}, fmt.Errorf("resource must not be empty")
+	}
+	gvr, gr := schema.ParseResourceArg(resource)
+	if gvr != nil {
+		return *gvr, nil
+	}
+	if gr.Group != "" {
+		return schema.GroupVersionResource{}, fmt.Errorf("resource %q needs a version, e.g. %s.v1.%s", resource, gr.Resource, gr.Group)
+	}
+	return schema.GroupVersionResource{Version: "v1", Resource: gr.Resource}, nil
+}
+
+// ParseTenantBindings parses namespace=tenant pairs, e.g. "platform=tenant-a,platform=tenant-b,ops=*". A namespace
+// bound to * may declare any tenant.
+func ParseTenantBindings(bindings string) (map[string]map[string]bool, error) {
+	parsed := make(map[string]map[string]bool)
+	for _, binding := range strings.Split(bindings, ",") {
+		binding = strings.TrimSpace(binding)
+		if binding == "" {
+			continue
+		}
+		namespace, tenant, ok := strings.Cut(binding, "=")
+		namespace, tenant = strings.TrimSpace(namespace), strings.TrimSpace(tenant)
+		if !ok || namespace == "" || tenant == "" {
+			return nil, fmt.Errorf("invalid tenant binding %q, want namespace=tenant", binding)
+		}
+		if parsed[namespace] == nil {
+			parsed[namespace] = make(map[string]bool)
+		}
+		parsed[namespace][tenant] = true
+	}
+	return parsed, nil
+}
+
+// AnnotatedTenant is a tenant declared by the edge.mimir annotations of one object
+type AnnotatedTenant struct {
+	Tenant            string
+	Namespace         string // namespace the object belongs to: its own, or its name for a Namespace
+	Source            string // resource/namespace/name of the object
+	CreationTimestamp metav1.Time
+	Limits            limits.MimirLimits
+	Errors            limits.ValidationErrors
+}
+
+// AnnotationWatcher keeps the objects of one resource that match a label selector and reads tenants from their annotations.
+//
+// Whoever may annotate a watched object may set a tenant's limits, so an object may only declare a tenant bound to its
+// namespace: the tenant named like the namespace, or one the bindings map the namespace to. This keeps a team from
+// claiming, or raising the limits of, another team's tenant.
+type AnnotationWatcher struct {
+	client   dynamic.Interface
+	resource schema.GroupVersionResource
+	selector string                     // "" watches every object of the resource
+	bindings map[string]map[string]bool // namespace -> tenants it may declare besides its own name; "*" allows any
+	resync   time.Duration
+
+	mu     sync.RWMutex
+	lister cache.GenericLister // nil until the informer has synced
+}
+
+// NewAnnotationWatcher creates a watcher for the objects of resource, in every namespace, that match selector
+func NewAnnotationWatcher(client dynamic.Interface, resource schema.GroupVersionResource, selector string, bindings map[string]map[string]bool, resync time.Duration) (*AnnotationWatcher, error) {
+	if _, err := labels.Parse(selector); err != nil {
+		return nil, fmt.Errorf("invalid label selector %q: %w", selector, err)
+	}
+	return &AnnotationWatcher{client: client, resource: resource, selector: selector, bindings: bindings, resync: resync}, nil
+}
+
+// Bound splits the tenants into those their object's namespace may declare and those it may not
+func (w *AnnotationWatcher) Bound(tenants []AnnotatedTenant) (bound, unbound []AnnotatedTenant) {
+	for _, tenant := range tenants {
+		allowed := w.bindings[tenant.Namespace]
+		if tenant.Namespace != "" && (tenant.Tenant == tenant.Namespace || allowed[tenant.Tenant] || allowed["*"]) {
+			bound = append(bound, tenant)
+			continue
+		}
+		unbound = append(unbound, tenant)
+	}
+	return bound, unbound
+}
+
+// Start runs the informer until ctx is cancelled and waits for its cache; onChange is called for every event
+func (w *AnnotationWatcher) Start(ctx context.Context, syncTimeout time.Duration, onChange func()) error {
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(w.client, w.resync, metav1.NamespaceAll, func(options *metav1.ListOptions) {
+		options.LabelSelector = w.selector
+	})
+	informer := factory.ForResource(w.resource)
+	if _, err := informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { onChange() },
+		UpdateFunc: func(interface{}, interface{}) { onChange() },
+		DeleteFunc: func(interface{}) { onChange() },
+	}); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", w.resource.Resource, err)
+	}
+	factory.Start(ctx.Done())
+
+	syncCtx, cancel := context.WithTimeout(ctx, syncTimeout)
+	defer cancel()
+	if !cache.WaitForCacheSync(syncCtx.Done(), informer.Informer().HasSynced) {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%s informer did not sync within %s - can overrides-sync list them?", w.resource.String(), syncTimeout)
+	}
+
+	w.mu.Lock()
+	w.lister = informer.Lister()
+	w.mu.Unlock()
+	return nil
+}
+
+// List returns the tenants declared by the watched objects, from the informer's cache once it has synced and from
+// the API server before that (e.g. for --diff)
+func (w *AnnotationWatcher) List(ctx context.Context) ([]AnnotatedTenant, error) {
+	w.mu.RLock()
+	lister := w.lister
+	w.mu.RUnlock()
+
+	var objects []runtime.Object
+	if lister != nil {
+		var err error
+		objects, err = lister.List(labels.Everything())
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", w.resource.Resource, err)
+		}
+	} else {
+		list, err := w.client.Resource(w.resource).List(ctx, metav1.ListOptions{LabelSelector: w.selector})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", w.resource.Resource, err)
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	}
+
+	var tenants []AnnotatedTenant
+	for _, obj := range objects {
+		accessor, ok := obj.(metav1.Object)
+		if !ok {
+			continue
+		}
+		if tenant, ok := w.tenantOf(accessor); ok {
+			tenants = append(tenants, tenant)
+		}
+	}
+	return tenants, nil
+}
+
+// tenantOf reads the tenant an object declares; objects without the tenant annotation declare none
+func (w *AnnotationWatcher) tenantOf(obj metav1.Object) (AnnotatedTenant, bool) {
+	annotations := obj.GetAnnotations()
+	tenantID := strings.TrimSpace(annotations[TenantAnnotation])
+	if tenantID == "" {
+		return AnnotatedTenant{}, false
+	}
+
+	// Other cluster-scoped objects belong to no namespace and so can declare no tenant
+	source, namespace := w.resource.Resource+"/"+obj.GetName(), ""
+	if w.resource.Group == "" && w.resource.Resource == "namespaces" {
+		namespace = obj.GetName()
+	}
+	if obj.GetNamespace() != "" {
+		source, namespace = w.resource.Resource+"/"+obj.GetNamespace()+"/"+obj.GetName(), obj.GetNamespace()
+	}
+	tenant := AnnotatedTenant{Tenant: tenantID, Namespace: namespace, Source: source, CreationTimestamp: obj.GetCreationTimestamp()}
+	tenant.Limits, tenant.Errors = decodeLimitAnnotations(annotations)
+	return tenant, true
+}
+
+// decodeLimitAnnotations decodes the limit annotations of an object the way a tenant's Mimir overrides are decoded.
+// Errors name the annotation; an unknown limit is an error rather than ignored, since it is most likely a typo.
+func decodeLimitAnnotations(annotations map[string]string) (limits.MimirLimits, limits.ValidationErrors) {
+	var errs limits.ValidationErrors
+	fields := make(map[string]interface{})
+	keyOf := make(map[string]string) // canonical field -> annotation
+	for key, value := range annotations {
+		if !strings.HasPrefix(key, TenantAnnotationPrefix) || key == TenantAnnotation {
+			continue
+		}
+		field := limits.CanonicalFieldName(strings.ReplaceAll(strings.TrimPrefix(key, TenantAnnotationPrefix), "-", "_"))
+		switch {
+		case !limits.IsKnownField(field):
+			errs = append(errs, limits.FieldError{Field: key, Message: "unknown limit"})
+		case keyOf[field] != "":
+			first, second := keyOf[field], key
+			if second < first {
+				first, second = second, first
+			}
+			errs = append(errs, limits.FieldError{Field: second, Message: fmt.Sprintf("sets the same limit as %s", first)})
+		default:
+			fields[field] = value
+			keyOf[field] = key
+		}
+	}
+
+	decoded, _, decodeErrs := limits.DecodeMimirLimits(fields)
+	for _, err := range decodeErrs {
+		if key, ok := keyOf[err.Field]; ok {
+			err.Field = key
+		}
+		errs = append(errs, err)
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return decoded, errs
+}
+
+// resolveAnnotatedTenants picks one object per tenant: the oldest, then the first by source. The others are returned
+// as conflicts, by source.
+func resolveAnnotatedTenants(tenants []AnnotatedTenant) (map[string]AnnotatedTenant, map[string]string) {
+	sort.Slice(tenants, func(i, j int) bool {
+		a, b := tenants[i], tenants[j]
+		if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+			return a.CreationTimestamp.Before(&b.CreationTimestamp)
+		}
+		return a.Source < b.Source
+	})
+
+	byTenant := make(map[string]AnnotatedTenant, len(tenants))
+	conflicts := make(map[string]string)
+	for _, tenant := range tenants {
+		if winner, ok := byTenant[tenant.Tenant]; ok {
+			conflicts[tenant.Source] = winner.Source
+			continue
+		}
+		byTenant[tenant.Tenant] = tenant
+	}
+	return byTenant, conflicts
+}
+
+// mergeAnnotatedTenants adds the annotated tenants to the parsed overrides. For each limit the tenant's Mimir
+// overrides take precedence over its annotations; Mimir's global limits and the RLS defaults are resolved by RLS.
+// Invalid annotations of a tenant with valid Mimir overrides are ignored and returned apart, so the tenant keeps its
+// Mimir limits. Any other tenant with invalid annotations is not synced, like one with invalid overrides.
+func mergeAnnotatedTenants(parsed *parsedOverrides, annotated map[string]AnnotatedTenant) (merged map[string]limits.TenantLimits, invalid, ignored map[string]limits.ValidationErrors) {
+	overrides := make(map[string]limits.TenantLimits, len(parsed.overrides)+len(annotated))
+	for tenantID, tenantLimits := range parsed.overrides {
+		overrides[tenantID] = tenantLimits
+	}
+	invalid = make(map[string]limits.ValidationErrors, len(parsed.invalid))
+	for tenantID, errs := range parsed.invalid {
+		invalid[tenantID] = errs
+	}
+	ignored = make(map[string]limits.ValidationErrors)
+
+	for tenantID, tenant := range annotated {
+		if len(tenant.Errors) > 0 {
+			annotationErrs := make(limits.ValidationErrors, 0, len(tenant.Errors))
+			for _, err := range tenant.Errors {
+				err.Message = fmt.Sprintf("%s (on %s)", err.Message, tenant.Source)
+				annotationErrs = append(annotationErrs, err)
+			}
+			if _, ok := parsed.overrides[tenantID]; ok {
+				ignored[tenantID] = annotationErrs
+				continue
+			}
+			invalid[tenantID] = append(invalid[tenantID], annotationErrs...)
+			delete(overrides, tenantID)
+			continue
+		}
+		if invalid[tenantID] != nil {
+			continue
+		}
+		overrides[tenantID] = tenant.Limits.Merge(parsed.decoded[tenantID]).Apply(limits.TenantLimits{})
+	}
+	return overrides, invalid, ignored
+}
@@ -0,0 +1,210 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/overrides-sync/internal/limits"
+)
+
+// ReplicaStatus is the outcome of sending the tenant limits to one RLS replica
+type ReplicaStatus struct {
+	Address   string    `json:"address"`
+	Version   string    `json:"version"`            // version the replica confirmed
+	UpToDate  bool      `json:"up_to_date"`         // the replica already had the version; nothing was sent
+	Fallback  bool      `json:"fallback,omitempty"` // the replica has no bulk API and was sent tenant by tenant
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+
+	failedTenants []string // tenants a fallback push could not send
+}
+
+// rlsStatusError is a non-200 response of the RLS admin API
+type rlsStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *rlsStatusError) Error() string {
+	return fmt.Sprintf("RLS API returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// limitsSetVersion hashes the tenant limits, defaults and policies; JSON encoding sorts map keys, so equal sets hash
+// equally. Without defaults or policies (nil) the version does not cover them. It also returns the bulk payload for RLS.
+func limitsSetVersion(desired map[string]limits.TenantLimits, defaults *limits.TenantLimits, policies map[string]*limits.EdgePolicy) (string, []byte, error) {
+	tenants, err := json.Marshal(desired)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal tenant limits: %w", err)
+	}
+	hash := sha256.New()
+	hash.Write(tenants)
+
+	// Mimir's global limits, when known, are sent as the defaults every tenant inherits below its profile
+	var defaultsJSON json.RawMessage
+	if defaults != nil {
+		if defaultsJSON, err = json.Marshal(defaults); err != nil {
+			return "", nil, fmt.Errorf("failed to marshal default limits: %w", err)
+		}
+		hash.Write(defaultsJSON)
+	}
+
+	// An empty map is sent as {} so RLS clears the policies of every tenant
+	var policiesJSON json.RawMessage
+	if policies != nil {
+		if policiesJSON, err = json.Marshal(policies); err != nil {
+			return "", nil, fmt.Errorf("failed to marshal tenant policies: %w", err)
+		}
+		hash.Write(policiesJSON)
+	}
+	version := hex.EncodeToString(hash.Sum(nil))[:16]
+
+	payload, err := json.Marshal(struct {
+		Version  string          `json:"version"`
+		Tenants  json.RawMessage `json:"tenants"`
+		Defaults json.RawMessage `json:"defaults,omitempty"`
+		Policies json.RawMessage `json:"policies,omitempty"`
+	}{Version: version, Tenants: tenants, Defaults: defaultsJSON, Policies: policiesJSON})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal tenant limits set: %w", err)
+	}
+	return version, payload, nil
+}
+
+// pushLimits sends the tenant limits, defaults and policies as one versioned set to every RLS replica that does not
+// have that version yet. Replicas are handled concurrently; a replica without the bulk API is sent the limits tenant
+// by tenant.
+func (c *Controller) pushLimits(ctx context.Context, desired map[string]limits.TenantLimits, defaults *limits.TenantLimits, policies map[string]*limits.EdgePolicy) error {
+	version, payload, err := limitsSetVersion(desired, defaults, policies)
+	if err != nil {
+		return err
+	}
+
+	targets := c.rlsTargets(ctx)
+	statuses := make([]ReplicaStatus, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target string) {
+			defer wg.Done()
+			statuses[i] = c.pushLimitsTo(ctx, target, version, payload, desired)
+		}(i, target)
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	c.replicas = statuses
+	c.mu.Unlock()
+
+	failed := 0
+	failedTenants := make(map[string]bool)
+	for _, status := range statuses {
+		if status.Error != "" {
+			failed++
+		}
+		for _, tenantID := range status.failedTenants {
+			failedTenants[tenantID] = true
+		}
+	}
+	c.metrics.setFailingTenants("push", failedTenants)
+	c.logger.Info().
+		Str("version", version).
+		Int("tenants", len(desired)).
+		Int("replicas", len(targets)).
+		Int("failed_replicas", failed).
+		Msg("pushed tenant limits to RLS replicas")
+	if failed > 0 {
+		return fmt.Errorf("%d of %d RLS replicas did not receive limits version %s", failed, len(targets), version)
+	}
+	return nil
+}
+
+// pushLimitsTo brings one replica to version, doing nothing when it already has it
+func (c *Controller) pushLimitsTo(ctx context.Context, target, version string, payload []byte, desired map[string]limits.TenantLimits) ReplicaStatus {
+	status := ReplicaStatus{Address: target, CheckedAt: time.Now()}
+
+	if body, err := c.doRLSRequestTo(ctx, target, http.MethodGet, "/api/limits", nil); err == nil {
+		var current struct {
+			Version string `json:"version"`
+		}
+		if json.Unmarshal(body, &current) == nil && current.Version == version {
+			status.Version = version
+			status.UpToDate = true
+			return status
+		}
+	}
+
+	_, err := c.doRLSRequestTo(ctx, target, http.MethodPut, "/api/limits", payload)
+	var statusErr *rlsStatusError
+	if errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusNotFound || statusErr.StatusCode == http.StatusMethodNotAllowed) {
+		c.logger.Warn().Str("replica", target).Msg("RLS replica has no bulk limits API - sending tenant by tenant, without defaults and policies")
+		status.Fallback = true
+		status.failedTenants, err = c.pushLimitsPerTenant(ctx, target, desired)
+	}
+	if err != nil {
+		status.Error = err.Error()
+		c.logger.Error().Err(err).Str("replica", target).Str("version", version).Msg("failed to push tenant limits to RLS replica")
+		return status
+	}
+	status.Version = version
+	return status
+}
+
+// pushLimitsPerTenant sends each tenant's limits separately, for RLS versions without the bulk API.
+// It returns the tenants that could not be sent.
+func (c *Controller) pushLimitsPerTenant(ctx context.Context, target string, desired map[string]limits.TenantLimits) ([]string, error) {
+	var failed []string
+	var lastErr error
+	for tenantID, tenantLimits := range desired {
+		if err := c.sendTenantLimitsToRLS(ctx, target, tenantID, tenantLimits); err != nil {
+			failed = append(failed, tenantID)
+			lastErr = err
+			c.logger.Error().
+				Err(err).
+				Str("tenant", tenantID).
+				Str("replica", target).
+				Msg("failed to sync tenant limits to RLS")
+		}
+	}
+	if len(failed) > 0 {
+		return failed, fmt.Errorf("%d of %d tenants failed, last error: %w", len(failed), len(desired), lastErr)
+	}
+	return nil, nil
+}
+
+// Replicas returns the outcome of the last push to each RLS replica
+func (c *Controller) Replicas() []ReplicaStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]ReplicaStatus{}, c.replicas...)
+}
+
+// removeFromReplicas applies a tenant removal to every RLS replica; a replica that no longer knows the tenant counts as done
+func (c *Controller) removeFromReplicas(ctx context.Context, method, tenantID string) error {
+	path := "/api/tenants/" + url.PathEscape(tenantID)
+	var payload []byte
+	if method == http.MethodPut {
+		path += "/limits"
+		payload = []byte("{}")
+	}
+
+	var errs []error
+	for _, target := range c.rlsTargets(ctx) {
+		_, err := c.doRLSRequestTo(ctx, target, method, path, payload)
+		var statusErr *rlsStatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound && method == http.MethodDelete {
+			err = nil
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", target, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ConfigMapSource reads overrides from one or more ConfigMaps in a namespace.
+// With several ConfigMaps their contents are merged in the given order, later ones winning.
+// While watching, each ConfigMap is followed by a shared informer restricted to its name, so reconnects,
+// resource versions and bookmarks are handled by client-go and Get reads from the informer caches.
+type ConfigMapSource struct {
+	client    kubernetes.Interface
+	namespace string
+	names     []string
+	resync    time.Duration
+
+	mu      sync.RWMutex
+	listers []corelisters.ConfigMapNamespaceLister // one per name while the informers are synced, nil otherwise
+}
+
+// NewConfigMapSource creates a source for the named ConfigMaps; resync is the informers' resync period
+func NewConfigMapSource(client kubernetes.Interface, namespace string, resync time.Duration, names ...string) *ConfigMapSource {
+	return &ConfigMapSource{client: client, namespace: namespace, names: names, resync: resync}
+}
+
+// Name implements Source
+func (s *ConfigMapSource) Name() string {
+	return fmt.Sprintf("configmap/%s/%s", s.namespace, strings.Join(s.names, "+"))
+}
+
+// Get implements Source. The version joins the resourceVersions of all ConfigMaps.
+// A ConfigMap that does not exist contributes no overrides, so deleting it removes its tenants.
+func (s *ConfigMapSource) Get(ctx context.Context) (Snapshot, error) {
+	s.mu.RLock()
+	listers := s.listers
+	s.mu.RUnlock()
+
+	docs := make([]map[string]string, 0, len(s.names))
+	versions := make([]string, 0, len(s.names))
+	for i, name := range s.names {
+		var configMap *v1.ConfigMap
+		var err error
+		if listers != nil {
+			configMap, err = listers[i].Get(name)
+		} else {
+			configMap, err = s.client.CoreV1().ConfigMaps(s.namespace).Get(ctx, name, metav1.GetOptions{})
+		}
+		if apierrors.IsNotFound(err) {
+			docs = append(docs, map[string]string{})
+			versions = append(versions, "missing")
+			continue
+		}
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("failed to get ConfigMap %s/%s: %w", s.namespace, name, err)
+		}
+		docs = append(docs, configMap.Data)
+		versions = append(versions, configMap.ResourceVersion)
+	}
+
+	if len(docs) == 1 {
+		return Snapshot{Data: docs[0], Version: versions[0]}, nil
+	}
+	data, err := mergeSnapshotData(docs)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return Snapshot{Data: data, Version: strings.Join(versions, ",")}, nil
+}
+
+// Watch implements Source with one shared informer per ConfigMap. It returns when ctx is cancelled.
+func (s *ConfigMapSource) Watch(ctx context.Context, version string, onChange func(Snapshot)) error {
+	changed := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { notify() },
+		UpdateFunc: func(interface{}, interface{}) { notify() },
+		DeleteFunc: func(interface{}) { notify() },
+	}
+
+	listers := make([]corelisters.ConfigMapNamespaceLister, 0, len(s.names))
+	synced := make([]cache.InformerSynced, 0, len(s.names))
+	factories := make([]informers.SharedInformerFactory, 0, len(s.names))
+	for _, name := range s.names {
+		selector := fields.OneTermEqualSelector("metadata.name", name).String()
+		factory := informers.NewSharedInformerFactoryWithOptions(s.client, s.resync,
+			informers.WithNamespace(s.namespace),
+			informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+				opts.FieldSelector = selector
+			}),
+		)
+		configMaps := factory.Core().V1().ConfigMaps()
+		if _, err := configMaps.Informer().AddEventHandler(handler); err != nil {
+			return fmt.Errorf("failed to watch ConfigMap %s/%s: %w", s.namespace, name, err)
+		}
+		listers = append(listers, configMaps.Lister().ConfigMaps(s.namespace))
+		synced = append(synced, configMaps.Informer().HasSynced)
+		factories = append(factories, factory)
+	}
+
+	informerCtx, stopInformers := context.WithCancel(ctx)
+	defer func() {
+		stopInformers()
+		for _, factory := range factories {
+			factory.Shutdown()
+		}
+	}()
+	for _, factory := range factories {
+		factory.Start(informerCtx.Done())
+	}
+	if !cache.WaitForCacheSync(ctx.Done(), synced...) {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("ConfigMap informers failed to sync")
+	}
+
+	s.mu.Lock()
+	s.listers = listers
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.listers = nil
+		s.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+			// Re-read every member so a merged snapshot is always complete
+			snapshot, err := s.Get(ctx)
+			if err != nil {
+				return err
+			}
+			if snapshot.Version != version {
+				version = snapshot.Version
+				onChange(snapshot)
+			}
+		}
+	}
+}
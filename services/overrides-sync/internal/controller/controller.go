@@ -6,80 +6,78 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"math"
 	"net/http"
-	"strconv"
+	"net/url"
 	"strings"
-
+	"sync"
 	"time"
 
 	"github.com/AkshayDubey29/mimir-edge-enforcement/services/overrides-sync/internal/limits"
 	"github.com/rs/zerolog"
 	"gopkg.in/yaml.v2"
-	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/watch"
-	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/workqueue"
 )
 
-// parseScientificNotation parses a string value that may contain scientific notation
-func parseScientificNotation(value string) (float64, error) {
-	// Handle scientific notation (e.g., "4e6", "1.5e7", "4E6")
-	value = strings.TrimSpace(value)
-	if strings.Contains(strings.ToLower(value), "e") {
-		f, err := strconv.ParseFloat(value, 64)
-		if err != nil {
-			return 0, fmt.Errorf("invalid scientific notation: %s", value)
-		}
-		return f, nil
-	}
-
-	// Handle regular float parsing
-	f, err := strconv.ParseFloat(value, 64)
-	if err != nil {
-		return 0, fmt.Errorf("invalid float: %s", value)
-	}
-	return f, nil
-}
-
-// parseScientificNotationInt64 parses a string value that may contain scientific notation and converts to int64
-func parseScientificNotationInt64(value string) (int64, error) {
-	f, err := parseScientificNotation(value)
-	if err != nil {
-		return 0, err
-	}
-
-	// Check for overflow
-	if f > float64(math.MaxInt64) || f < float64(math.MinInt64) {
-		return 0, fmt.Errorf("value out of range for int64: %s", value)
-	}
-
-	return int64(f), nil
-}
-
 // Config holds the controller configuration
 type Config struct {
-	MimirNamespace      string
-	OverridesConfigMap  string
 	RLSHost             string
 	RLSAdminPort        string
+	RLSAuthToken        string // Bearer token for the RLS admin API; empty when auth is disabled
 	PollFallbackSeconds int
+
+	// 🔧 NEW: Removal of tenants that disappear from the overrides
+	Prune              bool          // remove them; otherwise they are only reported
+	RemovalPolicy      string        // reset or delete
+	TombstoneGrace     time.Duration // how long a tenant must stay missing before it is removed
+	MaxRemovalsPerSync int           // a sync that would remove more tenants removes none (0 = unlimited)
+
+	// 🔧 NEW: Every RLS replica receives the same versioned set of limits
+	Replicas       ReplicaDiscovery // nil sends the limits to RLSHost only
+	ResyncInterval time.Duration    // how often the last limits are re-sent so new replicas catch up (0 = never)
+
+	// 🔧 NEW: Only the holder of a Lease syncs; nil syncs unconditionally
+	LeaderElection *LeaderElectionConfig
+
+	// 🔧 NEW: EdgeEnforcementPolicies merged into the limits and sent to RLS; nil disables them
+	Policies *PolicyWatcher
+
+	// 🔧 NEW: Tenants declared by edge.mimir annotations on Kubernetes objects; nil disables them
+	Annotations *AnnotationWatcher
 }
 
-// Controller watches Mimir overrides ConfigMap and syncs to RLS
+// syncKey is the only workqueue key: every change leads to a sync of the whole overrides
+const syncKey = "overrides"
+
+// Controller watches a source of Mimir overrides and syncs them to RLS
 type Controller struct {
 	config     *Config
-	k8sClient  *kubernetes.Clientset
+	source     Source
 	httpClient *http.Client
 	logger     zerolog.Logger
 
+	metrics *Metrics
+
 	// State
-	lastResourceVersion string
-	stopChan            chan struct{}
+	stopChan chan struct{}
+	queue    workqueue.RateLimitingInterface
+
+	// Owned by the single worker
+	parsed         *parsedOverrides // overrides of the last snapshot
+	lastSetVersion string           // version of the limits set of the last sync
+
+	pendingMu sync.Mutex
+	pending   *Snapshot // newest snapshot from the watch, not yet synced
+
+	mu               sync.RWMutex
+	validationErrors map[string]limits.ValidationErrors // tenant -> why its overrides were not synced
+	report           *ReconciliationReport              // RLS versus Mimir as of the last sync
+	tombstones       map[string]time.Time               // tenant -> when it was first seen missing from the overrides
+	replicas         []ReplicaStatus                    // outcome of the last push to each RLS replica
 }
 
 // NewController creates a new controller
-func NewController(config *Config, k8sClient *kubernetes.Clientset, logger zerolog.Logger) *Controller {
+func NewController(config *Config, source Source, logger zerolog.Logger) *Controller {
 	// 🔧 PERFORMANCE FIX: Use connection pooling for better performance
 	transport := &http.Transport{
 		MaxIdleConns:        100,
@@ -95,31 +93,35 @@ func NewController(config *Config, k8sClient *kubernetes.Clientset, logger zerol
 
 	return &Controller{
 		config:     config,
-		k8sClient:  k8sClient,
+		source:     source,
 		httpClient: httpClient,
 		logger:     logger,
+		metrics:    newMetrics(),
 		stopChan:   make(chan struct{}),
 	}
 }
 
-// Run starts the controller
+// Run starts the controller, once it holds the leader lease when leader election is configured
 func (c *Controller) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-c.stopChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
 	c.logger.Info().
-		Str("namespace", c.config.MimirNamespace).
-		Str("configmap", c.config.OverridesConfigMap).
+		Str("source", c.source.Name()).
 		Msg("starting overrides sync controller")
 
-	// 🔧 OPTIMIZED: Reduced startup delay for faster initialization
-	c.logger.Info().Msg("waiting 3 seconds for RLS to be ready...")
-	time.Sleep(3 * time.Second)
-
-	// Initial sync with retry
-	if err := c.syncOverridesWithRetry(); err != nil {
-		c.logger.Error().Err(err).Msg("initial sync failed after retries")
+	if c.config.LeaderElection != nil {
+		return c.runWithLeaderElection(ctx)
 	}
-
-	// Start watching
-	return c.watchOverrides(ctx)
+	c.metrics.Leader.Set(1)
+	return c.run(ctx)
 }
 
 // Stop stops the controller
@@ -127,218 +129,372 @@ func (c *Controller) Stop() {
 	close(c.stopChan)
 }
 
-// watchOverrides watches the overrides ConfigMap for changes
-func (c *Controller) watchOverrides(ctx context.Context) error {
+// run syncs until ctx is cancelled. Changes from the watch, periodic resyncs and retries all go through a
+// rate-limited workqueue drained by a single worker, so syncs never overlap and failures back off.
+func (c *Controller) run(ctx context.Context) error {
+	c.queue = workqueue.NewRateLimitingQueueWithConfig(workqueue.DefaultControllerRateLimiter(), workqueue.RateLimitingQueueConfig{
+		Name: "overrides-sync",
+	})
+	defer c.queue.ShutDown()
+
+	go c.runWorker(ctx)
+
+	// 🔧 NEW: Every policy change leads to a sync; the first sync waits for the policies so none is left out
+	if c.config.Policies != nil {
+		if err := c.config.Policies.Start(ctx, time.Minute, func() { c.queue.Add(syncKey) }); err != nil {
+			return err
+		}
+	}
+	// 🔧 NEW: Likewise for the annotated objects, so no annotated tenant is pruned by the first sync
+	if c.config.Annotations != nil {
+		if err := c.config.Annotations.Start(ctx, time.Minute, func() { c.queue.Add(syncKey) }); err != nil {
+			return err
+		}
+	}
+
+	// Initial sync; a failure is retried with backoff, e.g. while RLS is still starting
+	c.queue.Add(syncKey)
+
+	// 🔧 NEW: Keep replicas that start later on the same limits
+	if c.config.ResyncInterval > 0 {
+		go c.resyncLoop(ctx, c.config.ResyncInterval)
+	}
+
+	return c.watchOverrides(ctx)
+}
+
+// runWorker processes the workqueue until it shuts down
+func (c *Controller) runWorker(ctx context.Context) {
+	for {
+		key, shutdown := c.queue.Get()
+		if shutdown {
+			return
+		}
+		if err := c.syncOverrides(ctx); err != nil {
+			c.logger.Error().
+				Err(err).
+				Int("retries", c.queue.NumRequeues(key)).
+				Msg("failed to sync overrides - retrying with backoff")
+			c.queue.AddRateLimited(key)
+		} else {
+			c.queue.Forget(key)
+		}
+		c.queue.Done(key)
+	}
+}
+
+// resyncLoop queues a sync every interval; with unchanged overrides it only re-sends the last limits to
+// replicas that do not have them, so replicas that started or restarted since catch up
+func (c *Controller) resyncLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
-		case <-c.stopChan:
-			return nil
-		default:
-			if err := c.watchOnce(ctx); err != nil {
-				c.logger.Error().Err(err).Msg("watch failed, falling back to polling")
-				time.Sleep(time.Duration(c.config.PollFallbackSeconds) * time.Second)
-			}
+			return
+		case <-ticker.C:
+			c.queue.Add(syncKey)
 		}
 	}
 }
 
-// watchOnce performs a single watch operation
-func (c *Controller) watchOnce(ctx context.Context) error {
-	watcher, err := c.k8sClient.CoreV1().ConfigMaps(c.config.MimirNamespace).Watch(ctx, metav1.ListOptions{
-		FieldSelector: fmt.Sprintf("metadata.name=%s", c.config.OverridesConfigMap),
-		Watch:         true,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to create watcher: %w", err)
+// watchOverrides watches the source for changes, queueing a full re-read whenever the watch breaks
+func (c *Controller) watchOverrides(ctx context.Context) error {
+	// Stopping the controller is a clean exit; anything else cancelling ctx is reported
+	stopped := func() error {
+		select {
+		case <-c.stopChan:
+			return nil
+		default:
+			return ctx.Err()
+		}
 	}
-	defer watcher.Stop()
 
+	version := ""
 	for {
+		err := c.source.Watch(ctx, version, func(snapshot Snapshot) {
+			c.logger.Info().
+				Str("source", c.source.Name()).
+				Str("version", snapshot.Version).
+				Msg("overrides updated")
+			version = snapshot.Version
+			c.pendingMu.Lock()
+			c.pending = &snapshot
+			c.pendingMu.Unlock()
+			c.queue.Add(syncKey)
+		})
+		if ctx.Err() != nil {
+			return stopped()
+		}
+
+		c.logger.Error().Err(err).Msg("watch failed, re-reading the overrides before watching again")
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
-		case <-c.stopChan:
-			return nil
-		case event, ok := <-watcher.ResultChan():
-			if !ok {
-				return fmt.Errorf("watcher channel closed")
-			}
-
-			if err := c.handleEvent(event); err != nil {
-				c.logger.Error().Err(err).Msg("failed to handle event")
-			}
+			return stopped()
+		case <-time.After(time.Duration(c.config.PollFallbackSeconds) * time.Second):
 		}
+		c.queue.Add(syncKey)
 	}
 }
 
-// handleEvent handles a ConfigMap event
-func (c *Controller) handleEvent(event watch.Event) error {
-	switch event.Type {
-	case watch.Added, watch.Modified:
-		configMap, ok := event.Object.(*v1.ConfigMap)
-		if !ok {
-			return fmt.Errorf("expected ConfigMap, got %T", event.Object)
+// syncOverrides syncs the newest snapshot from the watch, or reads the source when there is none.
+// An unchanged snapshot only re-sends the last limits to replicas that do not have them.
+func (c *Controller) syncOverrides(ctx context.Context) error {
+	start := time.Now()
+	err := c.syncLatest(ctx)
+	c.metrics.SyncDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		c.metrics.SyncsTotal.WithLabelValues("failure").Inc()
+		return err
+	}
+	c.metrics.SyncsTotal.WithLabelValues("success").Inc()
+	c.metrics.LastSuccessfulSync.SetToCurrentTime()
+	return nil
+}
+
+func (c *Controller) syncLatest(ctx context.Context) error {
+	c.pendingMu.Lock()
+	pending := c.pending
+	c.pending = nil
+	c.pendingMu.Unlock()
+
+	var snapshot Snapshot
+	if pending != nil {
+		snapshot = *pending
+	} else {
+		var err error
+		snapshot, err = c.source.Get(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read overrides from %s: %w", c.source.Name(), err)
 		}
+	}
 
-		c.logger.Info().
-			Str("resourceVersion", configMap.ResourceVersion).
-			Msg("ConfigMap updated")
+	if c.parsed == nil || snapshot.Version != c.parsed.snapshot.Version {
+		parsed, err := c.parseSnapshot(snapshot)
+		if err != nil {
+			return err
+		}
+		c.parsed = parsed
+	}
+	return c.syncParsed(ctx, c.parsed)
+}
 
-		return c.syncOverridesFromConfigMap(configMap)
+// parsedOverrides is one snapshot's overrides, parsed and validated
+type parsedOverrides struct {
+	snapshot  Snapshot
+	overrides map[string]limits.TenantLimits
+	decoded   map[string]limits.MimirLimits // each valid tenant's own overrides, in the typed Mimir model
+	invalid   map[string]limits.ValidationErrors
+	base      limits.MimirLimits   // Mimir's global limits; every field unset when the source does not know them
+	defaults  *limits.TenantLimits // base, sent as RLS's synced defaults; nil when the source does not know Mimir's global limits
+}
 
-	case watch.Deleted:
-		c.logger.Warn().Msg("overrides ConfigMap deleted")
-		return nil
+// parseSnapshot parses the overrides of a snapshot
+func (c *Controller) parseSnapshot(snapshot Snapshot) (*parsedOverrides, error) {
+	// Mimir's global limits, when the source knows them, are what every tenant inherits for fields its overrides leave out
+	base, errs := decodeMimirDefaults(snapshot.Defaults)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid Mimir global limits: %w", errs)
+	}
 
-	case watch.Error:
-		return fmt.Errorf("watch error")
+	overrides, decoded, invalid, err := c.parseOverrides(snapshot.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse overrides: %w", err)
+	}
 
-	default:
-		return nil
+	parsed := &parsedOverrides{snapshot: snapshot, overrides: overrides, decoded: decoded, invalid: invalid, base: base}
+	if snapshot.Defaults != nil {
+		d := base.Apply(limits.TenantLimits{})
+		parsed.defaults = &d
 	}
+	return parsed, nil
+}
+
+// desiredState is what a sync sends to RLS
+type desiredState struct {
+	limits   map[string]limits.TenantLimits
+	invalid  map[string]limits.ValidationErrors // tenants left out because their overrides or annotations are invalid
+	defaults *limits.TenantLimits               // Mimir's global limits, nil when the source does not know them
+	set      *policySet                         // nil without policies
+	merged   map[string]metav1.Condition        // how each policy's limits were merged
 }
 
-// syncOverrides performs a full sync of overrides
-func (c *Controller) syncOverrides() error {
-	configMap, err := c.k8sClient.CoreV1().ConfigMaps(c.config.MimirNamespace).Get(
-		context.Background(),
-		c.config.OverridesConfigMap,
-		metav1.GetOptions{},
-	)
+// desiredState merges the annotated tenants and then the EdgeEnforcementPolicies, when enabled, into the parsed
+// overrides
+func (c *Controller) desiredState(ctx context.Context, parsed *parsedOverrides) (*desiredState, error) {
+	state := &desiredState{limits: parsed.overrides, invalid: parsed.invalid, defaults: parsed.defaults}
+	if c.config.Annotations != nil {
+		tenants, err := c.config.Annotations.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// Unbound objects are left out before conflicts are resolved, so they cannot shadow the object that may
+		// declare the tenant
+		tenants, unbound := c.config.Annotations.Bound(tenants)
+		for _, tenant := range unbound {
+			c.logger.Warn().Str("object", tenant.Source).Str("tenant", tenant.Tenant).Str("namespace", tenant.Namespace).Msg("ignoring tenant annotations: the tenant is not bound to the object's namespace")
+		}
+		annotated, conflicts := resolveAnnotatedTenants(tenants)
+		for source, winner := range conflicts {
+			c.logger.Warn().Str("object", source).Str("tenant_declared_by", winner).Msg("ignoring tenant annotations: an older object declares the same tenant")
+		}
+		var ignored map[string]limits.ValidationErrors
+		state.limits, state.invalid, ignored = mergeAnnotatedTenants(parsed, annotated)
+		for tenantID, errs := range ignored {
+			c.logger.Warn().Str("tenant", tenantID).Str("errors", errs.Error()).Msg("ignoring invalid tenant annotations: the tenant keeps its Mimir overrides")
+		}
+		c.metrics.AnnotatedTenants.Set(float64(len(annotated)))
+		c.metrics.AnnotationConflicts.Set(float64(len(conflicts)))
+		c.metrics.UnboundAnnotations.Set(float64(len(unbound)))
+		c.metrics.IgnoredAnnotations.Set(float64(len(ignored)))
+	}
+	if c.config.Policies == nil {
+		return state, nil
+	}
+	policies, err := c.config.Policies.List(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get ConfigMap: %w", err)
+		return nil, err
 	}
-
-	return c.syncOverridesFromConfigMap(configMap)
+	state.set = resolvePolicies(policies)
+	for key, rejected := range state.set.rejected {
+		c.logger.Warn().Str("policy", key).Str("reason", rejected.Reason).Msg(rejected.Message)
+	}
+	state.limits, state.merged = mergePolicyLimits(state.set, state.limits, state.invalid)
+	return state, nil
 }
 
-// syncOverridesWithRetry performs a full sync with retry logic
-func (c *Controller) syncOverridesWithRetry() error {
-	maxRetries := 5
-	retryDelay := 2 * time.Second // 🔧 OPTIMIZED: Reduced from 5s to 2s for faster retries
+// recordInvalid publishes the tenants left out of a sync; each is counted as a failure once per change of the set
+func (c *Controller) recordInvalid(invalid map[string]limits.ValidationErrors) {
+	c.mu.Lock()
+	c.validationErrors = invalid
+	c.mu.Unlock()
+	c.metrics.InvalidTenants.Set(float64(len(invalid)))
+	failing := make(map[string]bool, len(invalid))
+	for tenantID := range invalid {
+		failing[tenantID] = true
+	}
+	c.metrics.setFailingTenants("invalid", failing)
+}
 
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		c.logger.Info().
-			Int("attempt", attempt).
-			Int("max_retries", maxRetries).
-			Msg("attempting to sync overrides to RLS")
+// syncParsed sends the parsed overrides, merged with the annotated tenants and the policies, to every RLS replica.
+// Only when the merged set changed since the last sync are removed tenants pruned and RLS reconciled with Mimir.
+func (c *Controller) syncParsed(ctx context.Context, parsed *parsedOverrides) error {
+	state, err := c.desiredState(ctx, parsed)
+	if err != nil {
+		return err
+	}
+	c.recordInvalid(state.invalid)
+	desired, set := state.limits, state.set
+	var policies map[string]*limits.EdgePolicy
+	if set != nil {
+		policies = set.edgePolicies()
+	}
+	version, _, err := limitsSetVersion(desired, state.defaults, policies)
+	if err != nil {
+		return err
+	}
+	changed := version != c.lastSetVersion
 
-		if err := c.syncOverrides(); err != nil {
-			c.logger.Warn().
-				Err(err).
-				Int("attempt", attempt).
-				Int("max_retries", maxRetries).
-				Msg("sync attempt failed")
-
-			if attempt < maxRetries {
-				c.logger.Info().
-					Dur("retry_delay", retryDelay).
-					Msg("retrying after delay")
-				time.Sleep(retryDelay)
-				continue
-			}
-			return fmt.Errorf("sync failed after %d attempts: %w", maxRetries, err)
-		}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
 
+	// 🔧 NEW: Send all tenants as one versioned set to every RLS replica
+	if changed {
 		c.logger.Info().
-			Int("attempt", attempt).
-			Msg("successfully synced overrides to RLS")
-		return nil
+			Int("tenant_count", len(desired)).
+			Int("policies", len(policies)).
+			Int("invalid_tenants", len(state.invalid)).
+			Msg("syncing overrides to RLS")
+	}
+	pushErr := c.pushLimits(ctx, desired, state.defaults, policies)
+	if pushErr != nil {
+		c.logger.Error().Err(pushErr).Msg("failed to sync overrides to every RLS replica")
+	}
+	// 🔧 NEW: Report on each EdgeEnforcementPolicy whether it reached RLS
+	if set != nil {
+		c.updatePolicyStatuses(ctx, set, state.merged, version, pushErr)
+	}
+	if !changed {
+		return pushErr
+	}
+	c.lastSetVersion = version
+
+	// 🔧 NEW: Remove tenants that disappeared from the overrides
+	var removed []string
+	if actual, err := c.fetchRLSTenants(ctx); err != nil {
+		c.logger.Error().Err(err).Msg("failed to fetch RLS tenants - skipping removal of tenants no longer in overrides")
+	} else {
+		removed = c.pruneRemovedTenants(ctx, desired, state.invalid, actual)
 	}
 
-	return fmt.Errorf("sync failed after %d attempts", maxRetries)
-}
+	// 🔧 NEW: Compare what RLS now enforces with what Mimir applies
+	c.reconcile(ctx, parsed.snapshot, desired, parsed.defaults, removed)
 
-// syncOverridesFromConfigMap syncs overrides from a ConfigMap
-func (c *Controller) syncOverridesFromConfigMap(configMap *v1.ConfigMap) error {
-	c.lastResourceVersion = configMap.ResourceVersion
+	return pushErr
+}
 
-	// Parse overrides from ConfigMap
-	overrides, err := c.parseOverrides(configMap.Data)
+// sendTenantLimitsToRLS sends one tenant's limits to the RLS replica at target via HTTP API
+func (c *Controller) sendTenantLimitsToRLS(ctx context.Context, target, tenantID string, tenantLimits limits.TenantLimits) error {
+	// Marshal limits to JSON
+	limitsJSON, err := json.Marshal(tenantLimits)
 	if err != nil {
-		return fmt.Errorf("failed to parse overrides: %w", err)
+		return fmt.Errorf("failed to marshal tenant limits: %w", err)
 	}
 
-	// Sync to RLS via HTTP API
-	c.logger.Info().
-		Int("tenant_count", len(overrides)).
-		Str("rls_host", c.config.RLSHost).
-		Str("rls_port", c.config.RLSAdminPort).
-		Msg("syncing overrides to RLS")
-
-	// 🔧 OPTIMIZED: Send each tenant's limits to RLS with optimized sequential processing
-	successCount := 0
-	for tenantID, limits := range overrides {
-		if err := c.sendTenantLimitsToRLS(tenantID, limits); err != nil {
-			c.logger.Error().
-				Err(err).
-				Str("tenant", tenantID).
-				Msg("failed to sync tenant limits to RLS")
-		} else {
-			c.logger.Debug().
-				Str("tenant", tenantID).
-				Float64("samples_per_second", limits.SamplesPerSecond).
-				Float64("burst_percent", limits.BurstPercent).
-				Int64("max_body_bytes", limits.MaxBodyBytes).
-				Msg("successfully synced tenant limits to RLS")
-			successCount++
-		}
+	body, err := c.doRLSRequestTo(ctx, target, http.MethodPut, "/api/tenants/"+url.PathEscape(tenantID)+"/limits", limitsJSON)
+	if err != nil {
+		return err
 	}
 
-	c.logger.Info().
-		Int("total_tenants", len(overrides)).
-		Int("successful_syncs", successCount).
-		Int("failed_syncs", len(overrides)-successCount).
-		Msg("completed sync to RLS")
+	c.logger.Debug().
+		Str("tenant", tenantID).
+		Str("response", string(body)).
+		Msg("RLS API response")
 
 	return nil
 }
 
-// sendTenantLimitsToRLS sends tenant limits to RLS via HTTP API
-func (c *Controller) sendTenantLimitsToRLS(tenantID string, tenantLimits limits.TenantLimits) error {
-	// Build RLS URL
-	rlsURL := fmt.Sprintf("http://%s:%s/api/tenants/%s/limits", c.config.RLSHost, c.config.RLSAdminPort, tenantID)
+// doRLSRequest calls the admin API of the RLS host and returns the response body of a 200 response
+func (c *Controller) doRLSRequest(ctx context.Context, method, path string, payload []byte) ([]byte, error) {
+	return c.doRLSRequestTo(ctx, c.defaultRLSTarget(), method, path, payload)
+}
 
-	// Marshal limits to JSON
-	limitsJSON, err := json.Marshal(tenantLimits)
-	if err != nil {
-		return fmt.Errorf("failed to marshal tenant limits: %w", err)
-	}
+// doRLSRequestTo calls the RLS admin API at target (host:port) and returns the response body of a 200 response
+func (c *Controller) doRLSRequestTo(ctx context.Context, target, method, path string, payload []byte) ([]byte, error) {
+	rlsURL := "http://" + target + path
 
 	// Create HTTP request
-	req, err := http.NewRequest(http.MethodPut, rlsURL, bytes.NewBuffer(limitsJSON))
+	req, err := http.NewRequestWithContext(ctx, method, rlsURL, bytes.NewReader(payload))
 	if err != nil {
-		return fmt.Errorf("failed to create HTTP request: %w", err)
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.config.RLSAuthToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.RLSAuthToken)
+	}
 
 	// Send request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	// Check response status
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("RLS API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, &rlsStatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
-
-	c.logger.Debug().
-		Str("tenant", tenantID).
-		Str("response", string(body)).
-		Msg("RLS API response")
-
-	return nil
+	return body, nil
 }
 
 // MimirOverridesConfig represents the structure of Mimir's overrides.yaml
@@ -346,8 +502,18 @@ type MimirOverridesConfig struct {
 	Overrides map[string]map[string]interface{} `yaml:"overrides"`
 }
 
-// parseOverrides parses overrides from ConfigMap data
-func (c *Controller) parseOverrides(data map[string]string) (map[string]limits.TenantLimits, error) {
+// decodeMimirDefaults decodes Mimir's global limits; without them every field of the result is unset
+func decodeMimirDefaults(defaults map[string]interface{}) (limits.MimirLimits, limits.ValidationErrors) {
+	if defaults == nil {
+		return limits.MimirLimits{}, nil
+	}
+	base, _, errs := limits.DecodeMimirLimits(defaults)
+	return base, errs
+}
+
+// parseOverrides parses overrides from ConfigMap data into limits and the decoded overrides of each tenant.
+// Tenants whose overrides fail validation are left out of both and reported in the third return value instead.
+func (c *Controller) parseOverrides(data map[string]string) (map[string]limits.TenantLimits, map[string]limits.MimirLimits, map[string]limits.ValidationErrors, error) {
 	c.logger.Debug().
 		Int("configmap_keys", len(data)).
 		Msg("parsing ConfigMap data")
@@ -363,16 +529,22 @@ func (c *Controller) parseOverrides(data map[string]string) (map[string]limits.T
 	// First, try to parse the Mimir YAML format (overrides.yaml key)
 	if overridesYaml, exists := data["overrides.yaml"]; exists {
 		c.logger.Info().Msg("found overrides.yaml - parsing Mimir YAML format")
-		return c.parseMimirYamlOverrides(overridesYaml)
+		tenants, err := c.parseMimirYamlOverrides(overridesYaml)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		overrides, decoded, invalid := c.buildTenantLimits(tenants)
+		return overrides, decoded, invalid, nil
 	}
 
 	// Fallback to legacy flat format for backward compatibility
 	c.logger.Info().Msg("no overrides.yaml found - trying legacy flat format")
-	return c.parseFlatOverrides(data)
+	overrides, decoded, invalid := c.buildTenantLimits(c.parseFlatOverrides(data))
+	return overrides, decoded, invalid, nil
 }
 
-// parseMimirYamlOverrides parses the actual Mimir overrides.yaml format
-func (c *Controller) parseMimirYamlOverrides(yamlContent string) (map[string]limits.TenantLimits, error) {
+// parseMimirYamlOverrides parses the actual Mimir overrides.yaml format into raw per-tenant fields
+func (c *Controller) parseMimirYamlOverrides(yamlContent string) (map[string]map[string]interface{}, error) {
 	var config MimirOverridesConfig
 
 	c.logger.Debug().
@@ -383,263 +555,115 @@ func (c *Controller) parseMimirYamlOverrides(yamlContent string) (map[string]lim
 		return nil, fmt.Errorf("failed to parse overrides YAML: %w", err)
 	}
 
-	overrides := make(map[string]limits.TenantLimits)
-
 	c.logger.Info().
 		Int("tenants_in_yaml", len(config.Overrides)).
 		Msg("found tenants in overrides YAML")
 
-	for tenantID, tenantConfig := range config.Overrides {
-		c.logger.Debug().
-			Str("tenant", tenantID).
-			Int("config_fields", len(tenantConfig)).
-			Msg("processing tenant overrides")
-
-		// Start with defaults
-		tenantLimits := limits.TenantLimits{
-			SamplesPerSecond:    10000, // Default
-			BurstPercent:        0.2,
-			MaxBodyBytes:        4194304,
-			MaxLabelsPerSeries:  60,
-			MaxLabelValueLength: 2048,
-			MaxSeriesPerRequest: 100000,
-		}
-
-		// Parse each field in the tenant config
-		for fieldName, fieldValue := range tenantConfig {
-			valueStr := fmt.Sprintf("%v", fieldValue)
-
-			c.logger.Debug().
-				Str("tenant", tenantID).
-				Str("field", fieldName).
-				Str("value", valueStr).
-				Msg("parsing tenant field")
-
-			if err := c.parseLimitValue(&tenantLimits, fieldName, valueStr); err != nil {
-				c.logger.Warn().
-					Err(err).
-					Str("tenant", tenantID).
-					Str("field", fieldName).
-					Str("value", valueStr).
-					Msg("failed to parse tenant field - skipping")
-				continue
-			}
-		}
-
-		overrides[tenantID] = tenantLimits
-		c.logger.Info().
-			Str("tenant", tenantID).
-			Interface("limits", tenantLimits).
-			Msg("processed tenant overrides")
-	}
-
-	c.logger.Info().
-		Int("total_tenants", len(overrides)).
-		Msg("completed parsing Mimir YAML overrides")
-
-	return overrides, nil
+	return config.Overrides, nil
 }
 
-// parseFlatOverrides parses the legacy flat key-value format for backward compatibility
-func (c *Controller) parseFlatOverrides(data map[string]string) (map[string]limits.TenantLimits, error) {
-	overrides := make(map[string]limits.TenantLimits)
+// parseFlatOverrides parses the legacy flat key-value format (tenant:limit or tenant.limit keys) into raw per-tenant fields
+func (c *Controller) parseFlatOverrides(data map[string]string) map[string]map[string]interface{} {
+	tenants := make(map[string]map[string]interface{})
 
 	for key, value := range data {
-		var tenantID, limitName string
-
-		// Try colon separator first
-		if strings.Contains(key, ":") {
-			parts := strings.SplitN(key, ":", 2)
-			if len(parts) == 2 {
-				tenantID = strings.TrimSpace(parts[0])
-				limitName = strings.TrimSpace(parts[1])
-			}
-		} else if strings.Contains(key, ".") {
-			// Try dot separator
-			parts := strings.SplitN(key, ".", 2)
-			if len(parts) == 2 {
-				tenantID = strings.TrimSpace(parts[0])
-				limitName = strings.TrimSpace(parts[1])
-			}
-		} else {
-			// Global override or unsupported format - skip
+		tenantID, limitName, ok := splitFlatKey(key)
+		if !ok {
+			// Global override, unsupported format or malformed key - skip
 			c.logger.Debug().
 				Str("key", key).
 				Msg("skipping non-tenant-specific key")
 			continue
 		}
 
-		if tenantID == "" || limitName == "" {
-			c.logger.Debug().
-				Str("key", key).
-				Msg("skipping malformed key")
-			continue
-		}
-
-		// Get or create tenant limits
-		tenantLimits, exists := overrides[tenantID]
-		if !exists {
-			tenantLimits = limits.TenantLimits{
-				SamplesPerSecond:    10000, // Default
-				BurstPercent:        0.2,
-				MaxBodyBytes:        4194304,
-				MaxLabelsPerSeries:  60,
-				MaxLabelValueLength: 2048,
-				MaxSeriesPerRequest: 100000,
-			}
+		if tenants[tenantID] == nil {
+			tenants[tenantID] = make(map[string]interface{})
 		}
-
-		// Parse limit value
-		if err := c.parseLimitValue(&tenantLimits, limitName, value); err != nil {
-			c.logger.Warn().
-				Err(err).
-				Str("tenant", tenantID).
-				Str("limit", limitName).
-				Str("value", value).
-				Msg("failed to parse limit value")
-			continue
-		}
-
-		overrides[tenantID] = tenantLimits
+		tenants[tenantID][limitName] = strings.TrimSpace(value)
 	}
 
 	c.logger.Info().
-		Int("total_tenants", len(overrides)).
+		Int("total_tenants", len(tenants)).
 		Msg("completed parsing flat overrides")
 
-	return overrides, nil
+	return tenants
 }
 
-// parseLimitValue parses a single limit value with scientific notation support
-func (c *Controller) parseLimitValue(limits *limits.TenantLimits, limitName, value string) error {
-	// Normalize limit name to handle different naming conventions
-	normalizedLimitName := strings.ToLower(strings.TrimSpace(limitName))
-
-	c.logger.Debug().
-		Str("original_limit", limitName).
-		Str("normalized_limit", normalizedLimitName).
-		Str("value", value).
-		Msg("parsing limit value")
-
-	switch normalizedLimitName {
-	// Samples per second variations (ingestion_rate is the primary Mimir field)
-	case "samples_per_second", "ingestion_rate", "samples_per_sec", "sps":
-		if val, err := parseScientificNotation(value); err == nil {
-			limits.SamplesPerSecond = val
-			c.logger.Debug().Float64("parsed_value", val).Msg("set samples_per_second")
-		} else {
-			return fmt.Errorf("invalid samples_per_second: %s", value)
-		}
-
-	// Burst size variations (ingestion_burst_size is the primary Mimir field)
-	// Note: Mimir uses absolute burst size, we convert to percentage of ingestion_rate
-	case "burst_percent", "burst_pct", "burst_percentage":
-		if val, err := parseScientificNotation(value); err == nil {
-			limits.BurstPercent = val
-			c.logger.Debug().Float64("parsed_value", val).Msg("set burst_percent")
-		} else {
-			return fmt.Errorf("invalid burst_percent: %s", value)
-		}
+// splitFlatKey splits a legacy flat key into tenant and limit name, trying the colon separator before the dot
+func splitFlatKey(key string) (tenantID, limitName string, ok bool) {
+	sep := ":"
+	if !strings.Contains(key, sep) {
+		sep = "."
+	}
+	parts := strings.SplitN(key, sep, 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	tenantID = strings.TrimSpace(parts[0])
+	limitName = strings.TrimSpace(parts[1])
+	return tenantID, limitName, tenantID != "" && limitName != ""
+}
 
-	case "ingestion_burst_size":
-		if val, err := parseScientificNotation(value); err == nil {
-			// Convert absolute burst size to percentage of ingestion_rate
-			// If ingestion_rate is not set yet, use a reasonable default
-			rate := limits.SamplesPerSecond
-			if rate <= 0 {
-				rate = 10000 // Default rate for percentage calculation
-			}
-			limits.BurstPercent = val / rate
+// buildTenantLimits decodes each tenant's raw overrides into the typed Mimir model, validates it and maps the
+// fields it sets onto RLS limits. A tenant with any invalid field is reported as a whole rather than synced with
+// partial limits.
+func (c *Controller) buildTenantLimits(tenants map[string]map[string]interface{}) (map[string]limits.TenantLimits, map[string]limits.MimirLimits, map[string]limits.ValidationErrors) {
+	overrides := make(map[string]limits.TenantLimits, len(tenants))
+	decoded := make(map[string]limits.MimirLimits, len(tenants))
+	invalid := make(map[string]limits.ValidationErrors)
+
+	for tenantID, fields := range tenants {
+		mimirLimits, ignored, errs := limits.DecodeMimirLimits(fields)
+		if len(ignored) > 0 {
 			c.logger.Debug().
-				Float64("burst_size", val).
-				Float64("ingestion_rate", rate).
-				Float64("calculated_burst_percent", limits.BurstPercent).
-				Msg("converted ingestion_burst_size to burst_percent")
-		} else {
-			return fmt.Errorf("invalid ingestion_burst_size: %s", value)
-		}
-
-	// Max body bytes variations
-	case "max_body_bytes", "max_request_size", "request_rate_limit", "max_request_body_size":
-		if val, err := parseScientificNotationInt64(value); err == nil {
-			limits.MaxBodyBytes = val
-			c.logger.Debug().Int64("parsed_value", val).Msg("set max_body_bytes")
-		} else {
-			return fmt.Errorf("invalid max_body_bytes: %s", value)
-		}
-
-	// Max labels per series variations (max_label_names_per_series is the Mimir field)
-	case "max_labels_per_series", "max_labels_per_metric", "labels_limit", "max_label_names_per_series":
-		if val, err := parseScientificNotation(value); err == nil {
-			limits.MaxLabelsPerSeries = int32(val)
-			c.logger.Debug().Int32("parsed_value", int32(val)).Msg("set max_labels_per_series")
-		} else {
-			return fmt.Errorf("invalid max_labels_per_series: %s", value)
+				Str("tenant", tenantID).
+				Strs("fields", ignored).
+				Msg("ignoring overrides that are not enforced at the edge")
 		}
-
-	// Max label value length variations
-	case "max_label_value_length", "max_label_name_length", "label_length_limit":
-		if val, err := parseScientificNotation(value); err == nil {
-			limits.MaxLabelValueLength = int32(val)
-			c.logger.Debug().Int32("parsed_value", int32(val)).Msg("set max_label_value_length")
-		} else {
-			return fmt.Errorf("invalid max_label_value_length: %s", value)
+		if len(errs) > 0 {
+			invalid[tenantID] = errs
+			c.logger.Warn().
+				Str("tenant", tenantID).
+				Strs("errors", errorStrings(errs)).
+				Msg("tenant overrides failed validation - not syncing tenant")
+			continue
 		}
 
-	// Max series per request variations
-	case "max_series_per_request", "max_series_per_metric", "max_series_per_query", "series_limit":
-		if val, err := parseScientificNotation(value); err == nil {
-			limits.MaxSeriesPerRequest = int32(val)
-			c.logger.Debug().Int32("parsed_value", int32(val)).Msg("set max_series_per_request")
-		} else {
-			return fmt.Errorf("invalid max_series_per_request: %s", value)
-		}
+		// Only what the tenant's overrides set; RLS resolves the rest from the synced defaults, profile and its own defaults
+		tenantLimits := mimirLimits.Apply(limits.TenantLimits{})
+		overrides[tenantID] = tenantLimits
+		decoded[tenantID] = mimirLimits
+		c.logger.Debug().
+			Str("tenant", tenantID).
+			Interface("limits", tenantLimits).
+			Msg("processed tenant overrides")
+	}
 
-	// Mimir global limits - map to RLS tenant-specific limits
-	case "max_global_series_per_user":
-		if val, err := parseScientificNotation(value); err == nil {
-			limits.MaxSeriesPerRequest = int32(val)
-			c.logger.Debug().
-				Str("mimir_field", limitName).
-				Int32("mapped_value", int32(val)).
-				Msg("mapped max_global_series_per_user to max_series_per_request (per-user limit)")
-		} else {
-			return fmt.Errorf("invalid max_global_series_per_user: %s", value)
-		}
+	c.logger.Info().
+		Int("valid_tenants", len(overrides)).
+		Int("invalid_tenants", len(invalid)).
+		Msg("completed mapping tenant overrides")
 
-	case "max_global_series_per_metric":
-		if val, err := parseScientificNotation(value); err == nil {
-			limits.MaxSeriesPerMetric = int32(val)
-			c.logger.Debug().
-				Str("mimir_field", limitName).
-				Int32("mapped_value", int32(val)).
-				Msg("mapped max_global_series_per_metric to max_series_per_metric (per-metric limit)")
-		} else {
-			return fmt.Errorf("invalid max_global_series_per_metric: %s", value)
-		}
+	return overrides, decoded, invalid
+}
 
-	// Additional Mimir fields (log but don't error - these are not part of our core limits yet)
-	case "max_global_metadata_per_user", "max_global_metadata_per_metric", "ingestion_tenant_shard_size",
-		"cardinality_analysis_enabled", "accept_ha_samples", "ha_cluster_label", "ha_replica_label",
-		"max_cache_freshness", "ruler_max_rule_groups_per_tenant", "ruler_max_rules_per_rule_group":
-		c.logger.Debug().
-			Str("field", limitName).
-			Str("value", value).
-			Msg("recognized Mimir field - not mapped to RLS limits yet")
-		// Don't error, just log for future implementation
-		return nil
-
-	default:
-		c.logger.Warn().
-			Str("limit_name", limitName).
-			Str("normalized_name", normalizedLimitName).
-			Str("value", value).
-			Msg("unknown limit type - skipping")
-		// Don't return error for unknown limits, just skip them
-		return nil
+func errorStrings(errs limits.ValidationErrors) []string {
+	out := make([]string, len(errs))
+	for i, err := range errs {
+		out[i] = err.Error()
 	}
+	return out
+}
 
-	return nil
+// ValidationErrors returns the validation errors of each tenant rejected by the last sync
+func (c *Controller) ValidationErrors() map[string]limits.ValidationErrors {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make(map[string]limits.ValidationErrors, len(c.validationErrors))
+	for tenant, errs := range c.validationErrors {
+		out[tenant] = errs
+	}
+	return out
 }
 
 // min returns the minimum of two integers
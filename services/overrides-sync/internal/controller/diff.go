@@ -0,0 +1,200 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/overrides-sync/internal/limits"
+)
+
+// What a sync would do to a tenant in RLS
+const (
+	DiffActionCreate = "create" // RLS does not know the tenant yet
+	DiffActionUpdate = "update" // some limits change
+	DiffActionRemove = "remove" // the tenant left the overrides and --prune removes it after the tombstone grace
+)
+
+// FieldChange is one limit that a sync would change
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"` // nil for a tenant RLS does not know
+	To    interface{} `json:"to"`   // nil for a removed tenant
+}
+
+// TenantChange lists what a sync would change for one tenant
+type TenantChange struct {
+	Tenant string        `json:"tenant"`
+	Action string        `json:"action"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// DiffReport is what syncing the current overrides would change in RLS
+type DiffReport struct {
+	Source        string              `json:"source"`
+	Version       string              `json:"version"`
+	RemovalPolicy string              `json:"removal_policy"`
+	Prune         bool                `json:"prune"`
+	Changes       []TenantChange      `json:"changes"`
+	Unchanged     int                 `json:"unchanged"`
+	Orphaned      []string            `json:"orphaned"` // tenants that left the overrides but keep their limits without --prune
+	Invalid       map[string][]string `json:"invalid"`  // tenants whose overrides fail validation and would not be synced
+}
+
+// Diff reads the overrides once, parses and merges them with the policies as a sync would and compares the result with the limits RLS
+// holds for each tenant itself, without changing anything. Profiles, defaults and temporary overrides are not part of a sync,
+// so they never show up as changes.
+func (c *Controller) Diff(ctx context.Context) (*DiffReport, error) {
+	snapshot, err := c.source.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overrides from %s: %w", c.source.Name(), err)
+	}
+	parsed, err := c.parseSnapshot(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	// Annotated tenants and limits set by EdgeEnforcementPolicies are part of what a sync sends
+	state, err := c.desiredState(ctx, parsed)
+	if err != nil {
+		return nil, err
+	}
+	desired, invalid := state.limits, state.invalid
+	actual, err := c.fetchRLSTenants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch RLS tenants: %w", err)
+	}
+
+	report := &DiffReport{
+		Source:        c.source.Name(),
+		Version:       snapshot.Version,
+		RemovalPolicy: c.config.RemovalPolicy,
+		Prune:         c.config.Prune,
+		Changes:       []TenantChange{},
+		Orphaned:      []string{},
+		Invalid:       make(map[string][]string, len(invalid)),
+	}
+	for tenantID, errs := range invalid {
+		report.Invalid[tenantID] = errorStrings(errs)
+	}
+
+	for tenantID, want := range desired {
+		got, ok := actual[tenantID]
+		if !ok {
+			report.Changes = append(report.Changes, TenantChange{
+				Tenant: tenantID,
+				Action: DiffActionCreate,
+				Fields: limitsAsChanges(want),
+			})
+			continue
+		}
+		diffs := diffLimits(tenantID, want, got.Overrides, nil, false)
+		if len(diffs) == 0 {
+			report.Unchanged++
+			continue
+		}
+		change := TenantChange{Tenant: tenantID, Action: DiffActionUpdate}
+		for _, diff := range diffs {
+			change.Fields = append(change.Fields, FieldChange{Field: diff.Field, From: diff.RLS, To: diff.Mimir})
+		}
+		sortFieldChanges(change.Fields)
+		report.Changes = append(report.Changes, change)
+	}
+
+	for tenantID, tenant := range actual {
+		_, wanted := desired[tenantID]
+		_, rejected := invalid[tenantID]
+		if wanted || rejected || !tenant.HasTenantLimits {
+			continue
+		}
+		if c.config.Prune {
+			report.Changes = append(report.Changes, TenantChange{Tenant: tenantID, Action: DiffActionRemove})
+		} else {
+			report.Orphaned = append(report.Orphaned, tenantID)
+		}
+	}
+	sort.Strings(report.Orphaned)
+
+	sort.Slice(report.Changes, func(i, j int) bool {
+		return report.Changes[i].Tenant < report.Changes[j].Tenant
+	})
+	return report, nil
+}
+
+// limitsAsChanges lists every limit of a tenant RLS does not know yet
+func limitsAsChanges(want limits.TenantLimits) []FieldChange {
+	raw, _ := json.Marshal(want)
+	var fields map[string]interface{}
+	_ = json.Unmarshal(raw, &fields)
+
+	changes := make([]FieldChange, 0, len(fields))
+	for field, value := range fields {
+		changes = append(changes, FieldChange{Field: field, To: value})
+	}
+	sortFieldChanges(changes)
+	return changes
+}
+
+func sortFieldChanges(changes []FieldChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+}
+
+// WriteText writes the report as a human-readable diff: + created, ~ updated, - removed, ? orphaned, ! invalid
+func (r *DiffReport) WriteText(w io.Writer) {
+	created, updated, removed := 0, 0, 0
+	for _, change := range r.Changes {
+		switch change.Action {
+		case DiffActionCreate:
+			created++
+			fmt.Fprintf(w, "+ %s\n", change.Tenant)
+			for _, field := range change.Fields {
+				fmt.Fprintf(w, "    %s: %s\n", field.Field, formatDiffValue(field.To))
+			}
+		case DiffActionUpdate:
+			updated++
+			fmt.Fprintf(w, "~ %s\n", change.Tenant)
+			for _, field := range change.Fields {
+				fmt.Fprintf(w, "    %s: %s -> %s\n", field.Field, formatDiffValue(field.From), formatDiffValue(field.To))
+			}
+		case DiffActionRemove:
+			removed++
+			fmt.Fprintf(w, "- %s (no longer in the overrides; %s after the tombstone grace)\n", change.Tenant, r.RemovalPolicy)
+		}
+	}
+	for _, tenantID := range r.Orphaned {
+		fmt.Fprintf(w, "? %s (no longer in the overrides; keeps its limits in RLS without --prune)\n", tenantID)
+	}
+
+	invalid := make([]string, 0, len(r.Invalid))
+	for tenantID := range r.Invalid {
+		invalid = append(invalid, tenantID)
+	}
+	sort.Strings(invalid)
+	for _, tenantID := range invalid {
+		fmt.Fprintf(w, "! %s: %s\n", tenantID, strings.Join(r.Invalid[tenantID], "; "))
+	}
+
+	if len(r.Changes) > 0 || len(r.Orphaned) > 0 || len(invalid) > 0 {
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "%d tenants change (%d created, %d updated, %d removed), %d unchanged, %d orphaned, %d invalid\n",
+		len(r.Changes), created, updated, removed, r.Unchanged, len(r.Orphaned), len(invalid))
+}
+
+func formatDiffValue(v interface{}) string {
+	if v == nil {
+		return "<unset>"
+	}
+	switch value := v.(type) {
+	case string:
+		return strconv.Quote(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// fileSettleDelay coalesces the burst of events one update causes, e.g. the several renames of a ConfigMap swap
+const fileSettleDelay = 100 * time.Millisecond
+
+// FileSource reads Mimir runtime config files from disk, e.g. a mounted volume or a local dev checkout.
+// Several files are merged in order, later ones winning, as Mimir does for -runtime-config.file=a.yaml,b.yaml.
+// Changes are picked up from filesystem notifications, with the files re-read every interval as well in case an
+// event is missed.
+type FileSource struct {
+	paths    []string
+	interval time.Duration
+}
+
+// NewFileSource creates a source for the given runtime config files
+func NewFileSource(interval time.Duration, paths ...string) *FileSource {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	return &FileSource{paths: paths, interval: interval}
+}
+
+// Name implements Source
+func (s *FileSource) Name() string {
+	return "file/" + strings.Join(s.paths, "+")
+}
+
+// Get implements Source. The version is a hash of the merged content.
+func (s *FileSource) Get(ctx context.Context) (Snapshot, error) {
+	docs := make([]map[string]string, 0, len(s.paths))
+	for _, path := range s.paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("failed to read runtime config %s: %w", path, err)
+		}
+		docs = append(docs, map[string]string{"overrides.yaml": string(content)})
+	}
+	data, err := mergeSnapshotData(docs)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return Snapshot{Data: data, Version: contentVersion(data)}, nil
+}
+
+// Watch implements Source with fsnotify on the files and their directories. Kubernetes updates a mounted ConfigMap
+// by swapping the ..data symlink in the directory, which never writes to the files themselves, so only the
+// directory watch sees it. Without notifications (e.g. inotify limits reached) Watch falls back to polling.
+func (s *FileSource) Watch(ctx context.Context, version string, onChange func(Snapshot)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return pollSource(ctx, s, s.interval, version, onChange)
+	}
+	defer watcher.Close()
+
+	for _, dir := range s.dirs() {
+		if err := watcher.Add(dir); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+	}
+	s.watchFiles(watcher)
+
+	check := func() error {
+		snapshot, err := s.Get(ctx)
+		if err != nil {
+			return err
+		}
+		// A swapped symlink leaves the watch on the old target behind
+		s.watchFiles(watcher)
+		if snapshot.Version != version {
+			version = snapshot.Version
+			onChange(snapshot)
+		}
+		return nil
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	var settle <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return fmt.Errorf("file watcher closed")
+			}
+			if settle == nil {
+				settle = time.After(fileSettleDelay)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return fmt.Errorf("file watcher closed")
+			}
+			return fmt.Errorf("file watch failed: %w", err)
+		case <-settle:
+			settle = nil
+			if err := check(); err != nil {
+				return err
+			}
+		case <-ticker.C:
+			if err := check(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// dirs returns the directories holding the files, each once
+func (s *FileSource) dirs() []string {
+	seen := make(map[string]bool, len(s.paths))
+	dirs := make([]string, 0, len(s.paths))
+	for _, path := range s.paths {
+		dir := filepath.Dir(path)
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// watchFiles (re-)adds a watch on each file, which catches in-place writes the directory watch does not report
+// on every platform. A file that is missing right now is still covered by its directory.
+func (s *FileSource) watchFiles(watcher *fsnotify.Watcher) {
+	for _, path := range s.paths {
+		watcher.Add(path)
+	}
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxRuntimeConfigBytes bounds the runtime config read from an HTTP endpoint
+const maxRuntimeConfigBytes = 64 << 20
+
+// HTTPSource polls a runtime config endpoint, typically Mimir's /runtime_config
+type HTTPSource struct {
+	url       string
+	authToken string
+	interval  time.Duration
+	client    *http.Client
+}
+
+// NewHTTPSource creates a source for the given URL. authToken is sent as a bearer token when set.
+func NewHTTPSource(url, authToken string, interval time.Duration) *HTTPSource {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &HTTPSource{
+		url:       url,
+		authToken: authToken,
+		interval:  interval,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name implements Source
+func (s *HTTPSource) Name() string {
+	return "http/" + s.url
+}
+
+// Get implements Source. The version is a hash of the response body.
+func (s *HTTPSource) Get(ctx context.Context) (Snapshot, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	if s.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.authToken)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to fetch runtime config: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRuntimeConfigBytes))
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to read runtime config: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Snapshot{}, fmt.Errorf("runtime config endpoint returned status %d", resp.StatusCode)
+	}
+
+	data := map[string]string{"overrides.yaml": string(body)}
+	return Snapshot{Data: data, Version: contentVersion(data)}, nil
+}
+
+// Watch implements Source by polling
+func (s *HTTPSource) Watch(ctx context.Context, version string, onChange func(Snapshot)) error {
+	return pollSource(ctx, s, s.interval, version, onChange)
+}
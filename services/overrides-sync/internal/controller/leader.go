@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// LeaderElectionConfig lets several overrides-sync instances run with only the holder of a Lease syncing
+type LeaderElectionConfig struct {
+	Client        kubernetes.Interface
+	Namespace     string // namespace of the Lease
+	LeaseName     string
+	Identity      string // this instance, usually the pod name
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+}
+
+// runWithLeaderElection waits for the Lease and syncs while holding it. Losing the Lease returns an error
+// so the process restarts as a follower instead of syncing alongside the new leader.
+func (c *Controller) runWithLeaderElection(ctx context.Context) error {
+	le := c.config.LeaderElection
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      le.LeaseName,
+			Namespace: le.Namespace,
+		},
+		Client: le.Client.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: le.Identity,
+		},
+	}
+
+	c.logger.Info().
+		Str("lease", le.Namespace+"/"+le.LeaseName).
+		Str("identity", le.Identity).
+		Msg("waiting for leader lease")
+
+	var runErr error
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		Name:            le.LeaseName,
+		LeaseDuration:   le.LeaseDuration,
+		RenewDeadline:   le.RenewDeadline,
+		RetryPeriod:     le.RetryPeriod,
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(leaderCtx context.Context) {
+				c.metrics.Leader.Set(1)
+				c.logger.Info().Str("identity", le.Identity).Msg("acquired leader lease - syncing overrides")
+				runErr = c.run(leaderCtx)
+			},
+			OnStoppedLeading: func() {
+				c.metrics.Leader.Set(0)
+				c.logger.Warn().Str("identity", le.Identity).Msg("no longer the leader - stopped syncing overrides")
+			},
+			OnNewLeader: func(identity string) {
+				if identity != le.Identity {
+					c.logger.Info().Str("leader", identity).Msg("another instance holds the leader lease")
+				}
+			},
+		},
+	})
+
+	if ctx.Err() != nil {
+		return runErr
+	}
+	return fmt.Errorf("lost leader lease %s/%s", le.Namespace, le.LeaseName)
+}
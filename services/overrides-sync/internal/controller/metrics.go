@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Metrics holds the controller's Prometheus metrics
+type Metrics struct {
+	LastSuccessfulSync prometheus.Gauge
+	SyncDuration       prometheus.Histogram
+	SyncsTotal         *prometheus.CounterVec
+	TenantFailures     *prometheus.CounterVec
+	FailingTenants     *prometheus.GaugeVec
+	InvalidTenants     prometheus.Gauge
+	Leader             prometheus.Gauge
+
+	AnnotatedTenants    prometheus.Gauge
+	AnnotationConflicts prometheus.Gauge
+	UnboundAnnotations  prometheus.Gauge
+	IgnoredAnnotations  prometheus.Gauge
+
+	failingMu sync.Mutex
+	failing   map[string]map[string]bool // reason -> tenants currently failing for it
+}
+
+// newMetrics creates and registers the controller's metrics
+func newMetrics() *Metrics {
+	return &Metrics{
+		LastSuccessfulSync: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: "overrides_sync_last_successful_sync_timestamp_seconds",
+			Help: "Unix time of the last sync that reached every RLS replica",
+		}),
+		SyncDuration: promauto.NewHistogram(prometheus.HistogramOpts{
+			Name:    "overrides_sync_sync_duration_seconds",
+			Help:    "Time taken to sync the overrides to RLS, including retries of failed replicas",
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 14),
+		}),
+		SyncsTotal: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "overrides_sync_syncs_total",
+			Help: "Syncs of the overrides to RLS by result (success, failure)",
+		}, []string{"result"}),
+		TenantFailures: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "overrides_sync_tenant_failures_total",
+			Help: "Times a tenant started failing to sync, by reason (invalid, push, remove)",
+		}, []string{"reason"}),
+		FailingTenants: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "overrides_sync_tenant_failing",
+			Help: "1 for each tenant currently failing to sync, by reason (invalid, push, remove); a tenant's series is dropped once it recovers",
+		}, []string{"tenant", "reason"}),
+		InvalidTenants: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: "overrides_sync_invalid_tenants",
+			Help: "Tenants whose overrides failed validation in the last sync",
+		}),
+		Leader: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: "overrides_sync_leader",
+			Help: "1 while this instance holds the leader lease and syncs to RLS, 0 otherwise",
+		}),
+		AnnotatedTenants: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: "overrides_sync_annotated_tenants",
+			Help: "Tenants declared by edge.mimir annotations in the last sync",
+		}),
+		AnnotationConflicts: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: "overrides_sync_annotation_conflicts",
+			Help: "Objects whose edge.mimir annotations are ignored because an older object declares the same tenant",
+		}),
+		UnboundAnnotations: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: "overrides_sync_unbound_annotations",
+			Help: "Objects whose edge.mimir annotations are ignored because their namespace may not declare the tenant",
+		}),
+		IgnoredAnnotations: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: "overrides_sync_ignored_annotations",
+			Help: "Tenants whose invalid edge.mimir annotations are ignored in favour of their valid Mimir overrides",
+		}),
+		failing: make(map[string]map[string]bool),
+	}
+}
+
+// setFailingTenants replaces the tenants failing for a reason. Only tenants failing right now have a series,
+// so the tenant label stays bounded; a tenant that starts failing is counted once in TenantFailures.
+func (m *Metrics) setFailingTenants(reason string, tenants map[string]bool) {
+	m.failingMu.Lock()
+	defer m.failingMu.Unlock()
+
+	previous := m.failing[reason]
+	for tenantID := range previous {
+		if !tenants[tenantID] {
+			m.FailingTenants.DeleteLabelValues(tenantID, reason)
+		}
+	}
+	current := make(map[string]bool, len(tenants))
+	for tenantID := range tenants {
+		if !previous[tenantID] {
+			m.TenantFailures.WithLabelValues(reason).Inc()
+			m.FailingTenants.WithLabelValues(tenantID, reason).Set(1)
+		}
+		current[tenantID] = true
+	}
+	m.failing[reason] = current
+}
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// MimirAPISource reads the overrides and the global limits Mimir actually runs with from its HTTP API:
+// per-tenant overrides from /runtime_config?mode=diff and the defaults from the limits block of /config.
+// Tenants therefore sync with Mimir's effective limits rather than the overrides alone.
+type MimirAPISource struct {
+	baseURL   string
+	authToken string
+	interval  time.Duration
+	client    *http.Client
+}
+
+// NewMimirAPISource creates a source for a Mimir component reachable at baseURL, e.g. http://mimir-distributor.mimir:8080
+func NewMimirAPISource(baseURL, authToken string, interval time.Duration) *MimirAPISource {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &MimirAPISource{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		authToken: authToken,
+		interval:  interval,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name implements Source
+func (s *MimirAPISource) Name() string {
+	return "mimir/" + s.baseURL
+}
+
+// Get implements Source. The version is a hash of the overrides and the global limits.
+func (s *MimirAPISource) Get(ctx context.Context) (Snapshot, error) {
+	configBody, err := s.fetch(ctx, "/config")
+	if err != nil {
+		return Snapshot{}, err
+	}
+	var config struct {
+		Limits map[string]interface{} `yaml:"limits"`
+	}
+	if err := yaml.Unmarshal(configBody, &config); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to parse Mimir config: %w", err)
+	}
+	if config.Limits == nil {
+		return Snapshot{}, fmt.Errorf("Mimir config has no limits block")
+	}
+
+	runtimeBody, err := s.fetch(ctx, "/runtime_config?mode=diff")
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	defaults, err := yaml.Marshal(config.Limits)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to encode Mimir limits: %w", err)
+	}
+	data := map[string]string{"overrides.yaml": string(runtimeBody)}
+	return Snapshot{
+		Data:     data,
+		Defaults: config.Limits,
+		Version:  contentVersion(map[string]string{"overrides.yaml": string(runtimeBody), "limits": string(defaults)}),
+	}, nil
+}
+
+// Watch implements Source by polling
+func (s *MimirAPISource) Watch(ctx context.Context, version string, onChange func(Snapshot)) error {
+	return pollSource(ctx, s, s.interval, version, onChange)
+}
+
+func (s *MimirAPISource) fetch(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	if s.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.authToken)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Mimir %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRuntimeConfigBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Mimir %s: %w", path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Mimir %s returned status %d", path, resp.StatusCode)
+	}
+	return body, nil
+}
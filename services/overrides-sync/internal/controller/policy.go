@@ -0,0 +1,421 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/overrides-sync/internal/limits"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// PolicyGVR is the resource of EdgeEnforcementPolicy objects
+var PolicyGVR = schema.GroupVersionResource{
+	Group:    "mimir-edge-enforcement.io",
+	Version:  "v1alpha1",
+	Resource: "edgeenforcementpolicies",
+}
+
+// Status conditions of an EdgeEnforcementPolicy
+const (
+	// PolicyConditionApplied is True once every RLS replica enforces the policy
+	PolicyConditionApplied = "Applied"
+	// PolicyConditionLimitsMerged is True once the policy's limits are merged with the tenant's Mimir-derived limits
+	PolicyConditionLimitsMerged = "LimitsMerged"
+)
+
+// EdgeEnforcementPolicy declares the edge-only settings of one tenant, which Mimir's overrides have no place for
+type EdgeEnforcementPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   EdgeEnforcementPolicySpec   `json:"spec"`
+	Status EdgeEnforcementPolicyStatus `json:"status,omitempty"`
+}
+
+// EdgeEnforcementPolicySpec is the desired edge configuration of a tenant
+type EdgeEnforcementPolicySpec struct {
+	Tenant             string                    `json:"tenant"`
+	ShadowMode         bool                      `json:"shadowMode,omitempty"`
+	Enforcement        *PolicyEnforcement        `json:"enforcement,omitempty"`
+	SelectiveFiltering *PolicySelectiveFiltering `json:"selectiveFiltering,omitempty"`
+	Limits             *PolicyLimits             `json:"limits,omitempty"`
+}
+
+// PolicyEnforcement toggles RLS's checks for the tenant; unset toggles are on
+type PolicyEnforcement struct {
+	Enabled                    *bool    `json:"enabled,omitempty"`
+	BurstPctOverride           *float64 `json:"burstPctOverride,omitempty"`
+	EnforceSamplesPerSecond    *bool    `json:"enforceSamplesPerSecond,omitempty"`
+	EnforceMaxBodyBytes        *bool    `json:"enforceMaxBodyBytes,omitempty"`
+	EnforceMaxLabelsPerSeries  *bool    `json:"enforceMaxLabelsPerSeries,omitempty"`
+	EnforceMaxSeriesPerRequest *bool    `json:"enforceMaxSeriesPerRequest,omitempty"`
+	EnforceMaxSeriesPerMetric  *bool    `json:"enforceMaxSeriesPerMetric,omitempty"`
+	EnforceBytesPerSecond      *bool    `json:"enforceBytesPerSecond,omitempty"`
+}
+
+// PolicySelectiveFiltering is the tenant's selective filtering; unset fields use RLS's global flags
+type PolicySelectiveFiltering struct {
+	Enabled                *bool    `json:"enabled,omitempty"`
+	Strategy               string   `json:"strategy,omitempty"`
+	MetricPriorities       []string `json:"metricPriorities,omitempty"`
+	MaxFilteringPercentage *int64   `json:"maxFilteringPercentage,omitempty"`
+	MinSeriesToKeep        *int64   `json:"minSeriesToKeep,omitempty"`
+}
+
+// PolicyLimits are edge-only limits that take precedence over the ones derived from Mimir
+type PolicyLimits struct {
+	BurstPercent *float64 `json:"burstPercent,omitempty"`
+	MaxBodyBytes *int64   `json:"maxBodyBytes,omitempty"`
+}
+
+// EdgeEnforcementPolicyStatus reports how the policy was applied
+type EdgeEnforcementPolicyStatus struct {
+	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
+	Conditions         []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// Key identifies the policy as namespace/name
+func (p *EdgeEnforcementPolicy) Key() string {
+	return p.Namespace + "/" + p.Name
+}
+
+// Validate reports the first invalid setting of the spec
+func (s *EdgeEnforcementPolicySpec) Validate() error {
+	if s.Tenant == "" {
+		return fmt.Errorf("spec.tenant must be set")
+	}
+	if e := s.Enforcement; e != nil && e.BurstPctOverride != nil && *e.BurstPctOverride < 0 {
+		return fmt.Errorf("spec.enforcement.burstPctOverride must not be negative, got %v", *e.BurstPctOverride)
+	}
+	if sf := s.SelectiveFiltering; sf != nil {
+		switch sf.Strategy {
+		case "", "random", "oldest", "newest", "priority":
+		default:
+			return fmt.Errorf("spec.selectiveFiltering.strategy must be random, oldest, newest or priority, got %q", sf.Strategy)
+		}
+		if sf.MaxFilteringPercentage != nil && (*sf.MaxFilteringPercentage < 0 || *sf.MaxFilteringPercentage > 100) {
+			return fmt.Errorf("spec.selectiveFiltering.maxFilteringPercentage must be between 0 and 100, got %d", *sf.MaxFilteringPercentage)
+		}
+		if sf.MinSeriesToKeep != nil && *sf.MinSeriesToKeep < 0 {
+			return fmt.Errorf("spec.selectiveFiltering.minSeriesToKeep must not be negative, got %d", *sf.MinSeriesToKeep)
+		}
+	}
+	if l := s.Limits; l != nil {
+		if l.BurstPercent != nil && *l.BurstPercent < 0 {
+			return fmt.Errorf("spec.limits.burstPercent must not be negative, got %v", *l.BurstPercent)
+		}
+		if l.MaxBodyBytes != nil && *l.MaxBodyBytes < 0 {
+			return fmt.Errorf("spec.limits.maxBodyBytes must not be negative, got %d", *l.MaxBodyBytes)
+		}
+	}
+	return nil
+}
+
+// edgePolicy converts the spec into the policy RLS enforces
+func (p *EdgeEnforcementPolicy) edgePolicy() *limits.EdgePolicy {
+	policy := &limits.EdgePolicy{
+		Source:     p.Key(),
+		ShadowMode: p.Spec.ShadowMode,
+	}
+	if e := p.Spec.Enforcement; e != nil {
+		on := func(v *bool) bool { return v == nil || *v }
+		policy.Enforcement = &limits.EnforcementConfig{
+			Enabled:                    on(e.Enabled),
+			EnforceSamplesPerSecond:    on(e.EnforceSamplesPerSecond),
+			EnforceMaxBodyBytes:        on(e.EnforceMaxBodyBytes),
+			EnforceMaxLabelsPerSeries:  on(e.EnforceMaxLabelsPerSeries),
+			EnforceMaxSeriesPerRequest: on(e.EnforceMaxSeriesPerRequest),
+			EnforceMaxSeriesPerMetric:  on(e.EnforceMaxSeriesPerMetric),
+			EnforceBytesPerSecond:      on(e.EnforceBytesPerSecond),
+		}
+		if e.BurstPctOverride != nil {
+			policy.Enforcement.BurstPctOverride = *e.BurstPctOverride
+		}
+	}
+	if sf := p.Spec.SelectiveFiltering; sf != nil {
+		policy.SelectiveFiltering = &limits.SelectiveFilteringPolicy{
+			Enabled:                sf.Enabled,
+			Strategy:               sf.Strategy,
+			MetricPriority:         sf.MetricPriorities,
+			MaxFilteringPercentage: sf.MaxFilteringPercentage,
+			MinSeriesToKeep:        sf.MinSeriesToKeep,
+		}
+	}
+	return policy
+}
+
+// applyLimits overrides the edge-only limits the policy sets
+func (p *EdgeEnforcementPolicy) applyLimits(base limits.TenantLimits) limits.TenantLimits {
+	if l := p.Spec.Limits; l != nil {
+		if l.BurstPercent != nil {
+			base.BurstPercent = l.BurstPercent
+		}
+		if l.MaxBodyBytes != nil {
+			base.MaxBodyBytes = l.MaxBodyBytes
+		}
+	}
+	return base
+}
+
+// PolicyWatcher follows EdgeEnforcementPolicy objects with a dynamic shared informer
+type PolicyWatcher struct {
+	client    dynamic.Interface
+	namespace string // "" watches every namespace
+	resync    time.Duration
+
+	mu     sync.RWMutex
+	lister cache.GenericLister // nil until the informer has synced
+}
+
+// NewPolicyWatcher creates a watcher for the policies in namespace, or in every namespace when it is empty
+func NewPolicyWatcher(client dynamic.Interface, namespace string, resync time.Duration) *PolicyWatcher {
+	return &PolicyWatcher{client: client, namespace: namespace, resync: resync}
+}
+
+// Start runs the informer until ctx is cancelled and waits for its cache; onChange is called for every event
+func (w *PolicyWatcher) Start(ctx context.Context, syncTimeout time.Duration, onChange func()) error {
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(w.client, w.resync, w.namespace, nil)
+	informer := factory.ForResource(PolicyGVR)
+	if _, err := informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { onChange() },
+		UpdateFunc: func(interface{}, interface{}) { onChange() },
+		DeleteFunc: func(interface{}) { onChange() },
+	}); err != nil {
+		return fmt.Errorf("failed to watch EdgeEnforcementPolicies: %w", err)
+	}
+	factory.Start(ctx.Done())
+
+	syncCtx, cancel := context.WithTimeout(ctx, syncTimeout)
+	defer cancel()
+	if !cache.WaitForCacheSync(syncCtx.Done(), informer.Informer().HasSynced) {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("EdgeEnforcementPolicy informer did not sync within %s - is the CRD installed?", syncTimeout)
+	}
+
+	w.mu.Lock()
+	w.lister = informer.Lister()
+	w.mu.Unlock()
+	return nil
+}
+
+// List returns the policies from the informer cache, or from the API while the informer is not running
+func (w *PolicyWatcher) List(ctx context.Context) ([]*EdgeEnforcementPolicy, error) {
+	w.mu.RLock()
+	lister := w.lister
+	w.mu.RUnlock()
+
+	var objects []runtime.Object
+	if lister != nil {
+		var err error
+		if w.namespace != "" {
+			objects, err = lister.ByNamespace(w.namespace).List(labels.Everything())
+		} else {
+			objects, err = lister.List(labels.Everything())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list EdgeEnforcementPolicies: %w", err)
+		}
+	} else {
+		list, err := w.client.Resource(PolicyGVR).Namespace(w.namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list EdgeEnforcementPolicies: %w", err)
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	}
+
+	policies := make([]*EdgeEnforcementPolicy, 0, len(objects))
+	for _, object := range objects {
+		u, ok := object.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		policy := &EdgeEnforcementPolicy{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), policy); err != nil {
+			return nil, fmt.Errorf("failed to decode EdgeEnforcementPolicy %s/%s: %w", u.GetNamespace(), u.GetName(), err)
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// updateStatus writes the policy's status subresource
+func (w *PolicyWatcher) updateStatus(ctx context.Context, policy *EdgeEnforcementPolicy) error {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(policy)
+	if err != nil {
+		return fmt.Errorf("failed to encode EdgeEnforcementPolicy %s: %w", policy.Key(), err)
+	}
+	u := &unstructured.Unstructured{Object: content}
+	u.SetGroupVersionKind(PolicyGVR.GroupVersion().WithKind("EdgeEnforcementPolicy"))
+	_, err = w.client.Resource(PolicyGVR).Namespace(policy.Namespace).UpdateStatus(ctx, u, metav1.UpdateOptions{})
+	return err
+}
+
+// policySet is the outcome of resolving the policies against each other
+type policySet struct {
+	all      []*EdgeEnforcementPolicy
+	byTenant map[string]*EdgeEnforcementPolicy // the policy each tenant gets
+	rejected map[string]metav1.Condition       // policy key -> why it is not applied (Invalid, Conflict)
+}
+
+// resolvePolicies validates the policies and picks one per tenant: the oldest, then the first by namespace/name
+func resolvePolicies(policies []*EdgeEnforcementPolicy) *policySet {
+	sort.Slice(policies, func(i, j int) bool {
+		a, b := policies[i], policies[j]
+		if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+			return a.CreationTimestamp.Before(&b.CreationTimestamp)
+		}
+		return a.Key() < b.Key()
+	})
+
+	set := &policySet{
+		all:      policies,
+		byTenant: make(map[string]*EdgeEnforcementPolicy),
+		rejected: make(map[string]metav1.Condition),
+	}
+	for _, policy := range policies {
+		if err := policy.Spec.Validate(); err != nil {
+			set.rejected[policy.Key()] = metav1.Condition{Reason: "Invalid", Message: err.Error()}
+			continue
+		}
+		if winner, ok := set.byTenant[policy.Spec.Tenant]; ok {
+			set.rejected[policy.Key()] = metav1.Condition{
+				Reason:  "Conflict",
+				Message: fmt.Sprintf("tenant %s is already configured by the older policy %s", policy.Spec.Tenant, winner.Key()),
+			}
+			continue
+		}
+		set.byTenant[policy.Spec.Tenant] = policy
+	}
+	return set
+}
+
+// edgePolicies returns the policy RLS should enforce per tenant
+func (s *policySet) edgePolicies() map[string]*limits.EdgePolicy {
+	policies := make(map[string]*limits.EdgePolicy, len(s.byTenant))
+	for tenantID, policy := range s.byTenant {
+		policies[tenantID] = policy.edgePolicy()
+	}
+	return policies
+}
+
+// mergePolicyLimits merges the policies' limits into the Mimir-derived limits, the policy winning for the fields it
+// sets. A tenant without overrides starts from defaults; a tenant with invalid overrides is left alone.
+// It returns the merged limits and the LimitsMerged condition of each tenant's policy.
+func mergePolicyLimits(set *policySet, overrides map[string]limits.TenantLimits, invalid map[string]limits.ValidationErrors) (map[string]limits.TenantLimits, map[string]metav1.Condition) {
+	desired := make(map[string]limits.TenantLimits, len(overrides)+len(set.byTenant))
+	for tenantID, tenantLimits := range overrides {
+		desired[tenantID] = tenantLimits
+	}
+
+	merged := make(map[string]metav1.Condition, len(set.byTenant))
+	for tenantID, policy := range set.byTenant {
+		base, ok := overrides[tenantID]
+		switch {
+		case invalid[tenantID] != nil:
+			merged[tenantID] = metav1.Condition{
+				Status:  metav1.ConditionFalse,
+				Reason:  "InvalidOverrides",
+				Message: fmt.Sprintf("the tenant's Mimir overrides are invalid and not synced: %v", invalid[tenantID]),
+			}
+			continue
+		case policy.Spec.Limits == nil:
+			merged[tenantID] = metav1.Condition{
+				Status:  metav1.ConditionTrue,
+				Reason:  "NoEdgeLimits",
+				Message: "the policy sets no limits; the tenant keeps its Mimir-derived limits",
+			}
+			continue
+		case ok:
+			merged[tenantID] = metav1.Condition{
+				Status:  metav1.ConditionTrue,
+				Reason:  "MimirOverrides",
+				Message: "the policy's limits take precedence over the tenant's Mimir overrides",
+			}
+		default:
+			// Only the policy's limits are sent; RLS resolves the rest from Mimir's global limits and its defaults
+			merged[tenantID] = metav1.Condition{
+				Status:  metav1.ConditionTrue,
+				Reason:  "NoMimirOverrides",
+				Message: "the tenant has no Mimir overrides; only the policy's limits are synced, over the defaults",
+			}
+		}
+		desired[tenantID] = policy.applyLimits(base)
+	}
+	return desired, merged
+}
+
+// updatePolicyStatuses records on every policy whether it was applied and merged; a status that did not change is
+// not written, so the informer event of the write does not lead to another write
+func (c *Controller) updatePolicyStatuses(ctx context.Context, set *policySet, merged map[string]metav1.Condition, version string, pushErr error) {
+	applied := metav1.Condition{
+		Status:  metav1.ConditionTrue,
+		Reason:  "Applied",
+		Message: fmt.Sprintf("applied to every RLS replica as limits version %s", version),
+	}
+	if pushErr != nil {
+		applied = metav1.Condition{Status: metav1.ConditionFalse, Reason: "RLSError", Message: pushErr.Error()}
+	} else {
+		for _, replica := range c.Replicas() {
+			if replica.Fallback {
+				applied = metav1.Condition{
+					Status:  metav1.ConditionFalse,
+					Reason:  "RLSUnsupported",
+					Message: fmt.Sprintf("RLS replica %s has no bulk limits API and cannot receive policies", replica.Address),
+				}
+				break
+			}
+		}
+	}
+
+	for _, policy := range set.all {
+		appliedCondition, mergedCondition := applied, merged[policy.Spec.Tenant]
+		if rejected, ok := set.rejected[policy.Key()]; ok {
+			rejected.Status = metav1.ConditionFalse
+			appliedCondition, mergedCondition = rejected, rejected
+		}
+
+		status := EdgeEnforcementPolicyStatus{
+			ObservedGeneration: policy.Generation,
+			Conditions:         append([]metav1.Condition{}, policy.Status.Conditions...),
+		}
+		for _, condition := range []metav1.Condition{
+			withType(appliedCondition, PolicyConditionApplied),
+			withType(mergedCondition, PolicyConditionLimitsMerged),
+		} {
+			condition.ObservedGeneration = policy.Generation
+			meta.SetStatusCondition(&status.Conditions, condition)
+		}
+		if reflect.DeepEqual(status, policy.Status) {
+			continue
+		}
+
+		updated := *policy
+		updated.Status = status
+		err := c.config.Policies.updateStatus(ctx, &updated)
+		if err != nil && !apierrors.IsConflict(err) && !apierrors.IsNotFound(err) {
+			c.logger.Error().Err(err).Str("policy", policy.Key()).Msg("failed to update EdgeEnforcementPolicy status")
+		}
+	}
+}
+
+func withType(condition metav1.Condition, conditionType string) metav1.Condition {
+	condition.Type = conditionType
+	return condition
+}
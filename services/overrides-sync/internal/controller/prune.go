@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/overrides-sync/internal/limits"
+)
+
+// What happens to a tenant in RLS once it has disappeared from the overrides
+const (
+	RemovalPolicyReset  = "reset"  // drop the tenant's own limits; it falls back to its profile and RLS's defaults
+	RemovalPolicyDelete = "delete" // forget the tenant in RLS altogether
+)
+
+// rlsTenant is RLS's view of a tenant as returned by GET /api/tenants
+type rlsTenant struct {
+	ID                string                 `json:"id"`
+	Limits            map[string]interface{} `json:"limits"`    // effective limits, with every layer and any temporary override
+	Overrides         map[string]interface{} `json:"overrides"` // the limits set for the tenant itself, as a sync sets them
+	TemporaryOverride map[string]interface{} `json:"temporary_override"`
+	HasTenantLimits   bool                   `json:"has_tenant_limits"`
+}
+
+// fetchRLSTenants returns every tenant RLS knows, keyed by ID
+func (c *Controller) fetchRLSTenants(ctx context.Context) (map[string]rlsTenant, error) {
+	body, err := c.doRLSRequest(ctx, http.MethodGet, "/api/tenants", nil)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Tenants []rlsTenant `json:"tenants"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode RLS tenants: %w", err)
+	}
+	out := make(map[string]rlsTenant, len(response.Tenants))
+	for _, tenant := range response.Tenants {
+		out[tenant.ID] = tenant
+	}
+	return out, nil
+}
+
+// pruneRemovedTenants finds tenants that have limits of their own in RLS but are no longer in the overrides.
+// Each is tombstoned when first seen missing and removed according to the removal policy once the tombstone
+// is older than the grace period, provided pruning is enabled and the sync stays within the removal cap.
+// Tenants whose overrides failed validation are still present in the source and are never removed.
+// It returns the tenants removed.
+func (c *Controller) pruneRemovedTenants(ctx context.Context, desired map[string]limits.TenantLimits, invalid map[string]limits.ValidationErrors, actual map[string]rlsTenant) []string {
+	now := time.Now()
+	failed := make(map[string]bool)
+	defer c.metrics.setFailingTenants("remove", failed)
+
+	c.mu.Lock()
+	if c.tombstones == nil {
+		c.tombstones = make(map[string]time.Time)
+	}
+	var due []string
+	for tenantID, tenant := range actual {
+		_, wanted := desired[tenantID]
+		_, rejected := invalid[tenantID]
+		if wanted || rejected || !tenant.HasTenantLimits {
+			continue
+		}
+		since, ok := c.tombstones[tenantID]
+		if !ok {
+			since = now
+			c.tombstones[tenantID] = now
+			c.logger.Info().
+				Str("tenant", tenantID).
+				Dur("grace", c.config.TombstoneGrace).
+				Msg("tenant no longer in overrides - tombstoned")
+		}
+		if now.Sub(since) >= c.config.TombstoneGrace {
+			due = append(due, tenantID)
+		}
+	}
+	// Forget tombstones of tenants that came back or are gone from RLS
+	for tenantID := range c.tombstones {
+		tenant, inRLS := actual[tenantID]
+		_, wanted := desired[tenantID]
+		_, rejected := invalid[tenantID]
+		if wanted || rejected || !inRLS || !tenant.HasTenantLimits {
+			delete(c.tombstones, tenantID)
+		}
+	}
+	c.mu.Unlock()
+
+	sort.Strings(due)
+	if len(due) == 0 {
+		return nil
+	}
+	if !c.config.Prune {
+		c.logger.Warn().
+			Strs("tenants", due).
+			Msg("tenants removed from overrides still have limits in RLS - enable --prune to remove them")
+		return nil
+	}
+	if c.config.MaxRemovalsPerSync > 0 && len(due) > c.config.MaxRemovalsPerSync {
+		c.logger.Error().
+			Int("tenants", len(due)).
+			Int("max_removals_per_sync", c.config.MaxRemovalsPerSync).
+			Strs("tenant_ids", due).
+			Msg("refusing to remove more tenants than allowed in one sync - check the overrides source")
+		return nil
+	}
+
+	var removed []string
+	for _, tenantID := range due {
+		method := http.MethodPut
+		if c.config.RemovalPolicy == RemovalPolicyDelete {
+			method = http.MethodDelete
+		}
+		err := c.removeFromReplicas(ctx, method, tenantID)
+		if err != nil {
+			failed[tenantID] = true
+			c.logger.Error().
+				Err(err).
+				Str("tenant", tenantID).
+				Str("policy", c.config.RemovalPolicy).
+				Msg("failed to remove tenant from RLS")
+			continue
+		}
+
+		c.mu.Lock()
+		delete(c.tombstones, tenantID)
+		c.mu.Unlock()
+		removed = append(removed, tenantID)
+		c.logger.Info().
+			Str("tenant", tenantID).
+			Str("policy", c.config.RemovalPolicy).
+			Msg("removed tenant that is no longer in overrides from RLS")
+	}
+	return removed
+}
+
+// Tombstones returns the tenants awaiting removal and when each was first seen missing from the overrides
+func (c *Controller) Tombstones() map[string]time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make(map[string]time.Time, len(c.tombstones))
+	for tenantID, since := range c.tombstones {
+		out[tenantID] = since
+	}
+	return out
+}
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/overrides-sync/internal/limits"
+)
+
+// LimitDisagreement is one limit on which RLS and Mimir disagree for a tenant
+type LimitDisagreement struct {
+	Tenant string      `json:"tenant"`
+	Field  string      `json:"field"`
+	Mimir  interface{} `json:"mimir"`
+	RLS    interface{} `json:"rls"`
+}
+
+// ReconciliationReport compares the limits RLS enforces with the limits Mimir applies, as of the last sync
+type ReconciliationReport struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Source      string    `json:"source"`
+	Version     string    `json:"version"`
+	// TenantsCompared counts tenants with overrides plus, when Mimir's global limits are known,
+	// RLS tenants without overrides, which are compared against those global limits
+	TenantsCompared    int                  `json:"tenants_compared"`
+	TenantsInAgreement int                  `json:"tenants_in_agreement"`
+	MissingInRLS       []string             `json:"missing_in_rls"` // tenants with overrides that RLS does not know
+	Disagreements      []LimitDisagreement  `json:"disagreements"`
+	Removed            []string             `json:"removed"`    // tenants removed from RLS by this sync
+	Tombstones         map[string]time.Time `json:"tombstones"` // tenants awaiting removal, with when they were first seen missing
+	Replicas           []ReplicaStatus      `json:"replicas"`   // which limits version each RLS replica has
+	Error              string               `json:"error,omitempty"`
+}
+
+// reconcile fetches RLS's tenants and records where their limits differ from the desired ones.
+// defaults are Mimir's effective limits for tenants without overrides, or nil when the source does not know them.
+func (c *Controller) reconcile(ctx context.Context, snapshot Snapshot, desired map[string]limits.TenantLimits, defaults *limits.TenantLimits, removed []string) {
+	report := &ReconciliationReport{
+		GeneratedAt:   time.Now(),
+		Source:        c.source.Name(),
+		Version:       snapshot.Version,
+		MissingInRLS:  []string{},
+		Disagreements: []LimitDisagreement{},
+		Removed:       append([]string{}, removed...),
+		Tombstones:    c.Tombstones(),
+		Replicas:      c.Replicas(),
+	}
+	defer func() {
+		c.mu.Lock()
+		c.report = report
+		c.mu.Unlock()
+	}()
+
+	actual, err := c.fetchRLSTenants(ctx)
+	if err != nil {
+		report.Error = err.Error()
+		c.logger.Error().Err(err).Msg("failed to fetch RLS tenants for reconciliation")
+		return
+	}
+
+	record := func(diffs []LimitDisagreement) {
+		report.Disagreements = append(report.Disagreements, diffs...)
+		if len(diffs) == 0 {
+			report.TenantsInAgreement++
+		}
+	}
+
+	// Tenants with overrides are compared on the limits set for them, which a temporary override does not change
+	for tenantID, want := range desired {
+		report.TenantsCompared++
+		got, ok := actual[tenantID]
+		if !ok {
+			report.MissingInRLS = append(report.MissingInRLS, tenantID)
+			continue
+		}
+		record(diffLimits(tenantID, want, got.Overrides, edgeOnlyFields, false))
+	}
+	// Tenants without overrides only have the defaults, so their effective limits are compared, minus the fields of a temporary override
+	if defaults != nil {
+		for tenantID, got := range actual {
+			if _, ok := desired[tenantID]; ok {
+				continue
+			}
+			report.TenantsCompared++
+			skip := edgeOnlyFields
+			if len(got.TemporaryOverride) > 0 {
+				skip = make(map[string]bool, len(edgeOnlyFields)+len(got.TemporaryOverride))
+				for field := range edgeOnlyFields {
+					skip[field] = true
+				}
+				for field := range got.TemporaryOverride {
+					skip[field] = true
+				}
+			}
+			record(diffLimits(tenantID, *defaults, got.Limits, skip, true))
+		}
+	}
+
+	sort.Strings(report.MissingInRLS)
+	sort.Slice(report.Disagreements, func(i, j int) bool {
+		a, b := report.Disagreements[i], report.Disagreements[j]
+		if a.Tenant != b.Tenant {
+			return a.Tenant < b.Tenant
+		}
+		return a.Field < b.Field
+	})
+
+	event := c.logger.Info()
+	if len(report.Disagreements) > 0 || len(report.MissingInRLS) > 0 {
+		event = c.logger.Warn()
+	}
+	event.
+		Int("tenants_compared", report.TenantsCompared).
+		Int("tenants_in_agreement", report.TenantsInAgreement).
+		Int("missing_in_rls", len(report.MissingInRLS)).
+		Int("disagreements", len(report.Disagreements)).
+		Msg("reconciled RLS limits with Mimir")
+}
+
+// edgeOnlyFields are RLS limits without a Mimir counterpart, so Mimir cannot disagree on them
+var edgeOnlyFields = map[string]bool{
+	"burst_pct":      true,
+	"max_body_bytes": true,
+}
+
+// diffLimits compares every limit set in want, except the skipped fields, with RLS's JSON view of the tenant.
+// A field missing from the limits set for the tenant is unset (nil). RLS's effective limits omit zero-valued
+// optional limits, so with effective a missing field counts as the zero value.
+func diffLimits(tenantID string, want limits.TenantLimits, got map[string]interface{}, skip map[string]bool, effective bool) []LimitDisagreement {
+	raw, _ := json.Marshal(want)
+	var wantFields map[string]interface{}
+	_ = json.Unmarshal(raw, &wantFields)
+
+	var diffs []LimitDisagreement
+	for field, wantValue := range wantFields {
+		if skip[field] {
+			continue
+		}
+		gotValue, ok := got[field]
+		if !ok && effective {
+			gotValue = reflect.Zero(reflect.TypeOf(wantValue)).Interface()
+		}
+		if !reflect.DeepEqual(wantValue, gotValue) {
+			diffs = append(diffs, LimitDisagreement{Tenant: tenantID, Field: field, Mimir: wantValue, RLS: gotValue})
+		}
+	}
+	return diffs
+}
+
+// Report returns the reconciliation report of the last sync, or nil before the first one
+func (c *Controller) Report() *ReconciliationReport {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.report
+}
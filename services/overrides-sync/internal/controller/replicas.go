@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ReplicaDiscovery finds the RLS replicas that must all receive the tenant limits
+type ReplicaDiscovery interface {
+	// Replicas returns the admin API address (host:port) of every ready replica
+	Replicas(ctx context.Context) ([]string, error)
+}
+
+// EndpointsDiscovery finds RLS replicas through the Endpoints of the RLS Service
+type EndpointsDiscovery struct {
+	client    kubernetes.Interface
+	namespace string
+	service   string
+	port      string
+}
+
+// NewEndpointsDiscovery creates a discovery for the Service namespace/service; every ready address is reached on port
+func NewEndpointsDiscovery(client kubernetes.Interface, namespace, service, port string) *EndpointsDiscovery {
+	return &EndpointsDiscovery{
+		client:    client,
+		namespace: namespace,
+		service:   service,
+		port:      port,
+	}
+}
+
+// Replicas implements ReplicaDiscovery
+func (d *EndpointsDiscovery) Replicas(ctx context.Context) ([]string, error) {
+	endpoints, err := d.client.CoreV1().Endpoints(d.namespace).Get(ctx, d.service, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get endpoints %s/%s: %w", d.namespace, d.service, err)
+	}
+
+	seen := make(map[string]bool)
+	var replicas []string
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			target := net.JoinHostPort(address.IP, d.port)
+			if !seen[target] {
+				seen[target] = true
+				replicas = append(replicas, target)
+			}
+		}
+	}
+	sort.Strings(replicas)
+	return replicas, nil
+}
+
+// rlsTargets returns the RLS admin API addresses to send limits to: every discovered replica, or the RLS host
+// when discovery is not configured, fails or finds no ready replica
+func (c *Controller) rlsTargets(ctx context.Context) []string {
+	if c.config.Replicas != nil {
+		replicas, err := c.config.Replicas.Replicas(ctx)
+		if err == nil && len(replicas) > 0 {
+			return replicas
+		}
+		c.logger.Warn().
+			Err(err).
+			Str("rls_host", c.config.RLSHost).
+			Msg("no RLS replicas discovered - sending limits to the RLS host only")
+	}
+	return []string{c.defaultRLSTarget()}
+}
+
+// defaultRLSTarget is the admin API address of the RLS host, usually the Service
+func (c *Controller) defaultRLSTarget() string {
+	return net.JoinHostPort(c.config.RLSHost, c.config.RLSAdminPort)
+}
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Snapshot is one version of the overrides read from a source
+type Snapshot struct {
+	// Data uses the layout of the overrides ConfigMap: Mimir's runtime config under "overrides.yaml",
+	// or legacy flat tenant:limit keys
+	Data map[string]string
+	// Defaults holds Mimir's global limits (the limits block of its config), which tenants inherit for every
+	// field their overrides leave out; nil when the source cannot tell
+	Defaults map[string]interface{}
+	// Version changes whenever the content changes, e.g. a resourceVersion or a content hash
+	Version string
+}
+
+// Source supplies the Mimir overrides the controller syncs to RLS
+type Source interface {
+	// Name identifies the source in logs and in the RLS audit log
+	Name() string
+	// Get reads the current overrides
+	Get(ctx context.Context) (Snapshot, error)
+	// Watch calls onChange with every snapshot newer than version until ctx is cancelled or watching fails
+	Watch(ctx context.Context, version string, onChange func(Snapshot)) error
+}
+
+// pollSource implements Watch for sources without change notifications by calling Get every interval
+func pollSource(ctx context.Context, s Source, interval time.Duration, version string, onChange func(Snapshot)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			snapshot, err := s.Get(ctx)
+			if err != nil {
+				return err
+			}
+			if snapshot.Version != version {
+				version = snapshot.Version
+				onChange(snapshot)
+			}
+		}
+	}
+}
+
+// mergeSnapshotData merges overrides documents in order, the way Mimir merges several runtime config files:
+// tenants are combined and, for a tenant present in more than one document, later fields replace earlier ones.
+// Legacy flat keys are merged key by key. Once any document has overrides.yaml, only that key is read, so the
+// flat keys are folded into it, after the YAML of their own document.
+func mergeSnapshotData(docs []map[string]string) (map[string]string, error) {
+	merged := make(map[string]string)
+	tenants := make(map[string]map[string]interface{})
+	haveYAML := false
+
+	set := func(tenantID, field string, value interface{}) {
+		if tenants[tenantID] == nil {
+			tenants[tenantID] = make(map[string]interface{})
+		}
+		tenants[tenantID][field] = value
+	}
+
+	for _, doc := range docs {
+		if value, ok := doc["overrides.yaml"]; ok {
+			var config MimirOverridesConfig
+			if err := yaml.Unmarshal([]byte(value), &config); err != nil {
+				return nil, fmt.Errorf("failed to parse overrides YAML: %w", err)
+			}
+			haveYAML = true
+			for tenantID, fields := range config.Overrides {
+				for field, v := range fields {
+					set(tenantID, field, v)
+				}
+			}
+		}
+		for key, value := range doc {
+			if key == "overrides.yaml" {
+				continue
+			}
+			merged[key] = value
+			if tenantID, field, ok := splitFlatKey(key); ok {
+				set(tenantID, field, strings.TrimSpace(value))
+			}
+		}
+	}
+
+	if haveYAML {
+		out, err := yaml.Marshal(MimirOverridesConfig{Overrides: tenants})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode merged overrides: %w", err)
+		}
+		merged = map[string]string{"overrides.yaml": string(out)}
+	}
+	return merged, nil
+}
+
+// contentVersion returns a version derived from the content of data
+func contentVersion(data map[string]string) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(h, "%d:%s%d:%s", len(key), key, len(data[key]), data[key])
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
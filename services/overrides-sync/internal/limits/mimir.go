@@ -0,0 +1,442 @@
+package limits
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Ingestion rate strategies accepted by Mimir
+const (
+	IngestionRateStrategyLocal  = "local"
+	IngestionRateStrategyGlobal = "global"
+)
+
+// MimirLimits is the typed form of one tenant's entry in the overrides block of Mimir's runtime config.
+// It covers the limits that matter for ingestion; nil fields are not set for the tenant.
+// BurstPercent and MaxBodyBytes are edge-only extensions without a Mimir counterpart.
+type MimirLimits struct {
+	// Ingestion rate
+	IngestionRate         *Float  `yaml:"ingestion_rate"`
+	IngestionBurstSize    *Int    `yaml:"ingestion_burst_size"`
+	IngestionRateStrategy *string `yaml:"ingestion_rate_strategy"`
+	RequestRate           *Float  `yaml:"request_rate"`
+	RequestBurstSize      *Int    `yaml:"request_burst_size"`
+
+	// Series, exemplars and metadata
+	MaxGlobalSeriesPerUser     *Int `yaml:"max_global_series_per_user"`
+	MaxGlobalSeriesPerMetric   *Int `yaml:"max_global_series_per_metric"`
+	MaxGlobalExemplarsPerUser  *Int `yaml:"max_global_exemplars_per_user"`
+	MaxGlobalMetadataPerUser   *Int `yaml:"max_global_metadata_per_user"`
+	MaxGlobalMetadataPerMetric *Int `yaml:"max_global_metadata_per_metric"`
+
+	// Series validation
+	MaxLabelNamesPerSeries    *Int `yaml:"max_label_names_per_series"`
+	MaxLabelNameLength        *Int `yaml:"max_label_name_length"`
+	MaxLabelValueLength       *Int `yaml:"max_label_value_length"`
+	MaxMetadataLength         *Int `yaml:"max_metadata_length"`
+	MaxNativeHistogramBuckets *Int `yaml:"max_native_histogram_buckets"`
+
+	// Sample timestamps
+	OutOfOrderTimeWindow *Duration `yaml:"out_of_order_time_window"`
+	CreationGracePeriod  *Duration `yaml:"creation_grace_period"`
+	PastGracePeriod      *Duration `yaml:"past_grace_period"`
+
+	// HA deduplication
+	AcceptHASamples *Bool   `yaml:"accept_ha_samples"`
+	HAClusterLabel  *string `yaml:"ha_cluster_label"`
+	HAReplicaLabel  *string `yaml:"ha_replica_label"`
+	HAMaxClusters   *Int    `yaml:"ha_max_clusters"`
+
+	// Edge-only limits
+	BurstPercent *Float `yaml:"burst_pct"`
+	MaxBodyBytes *Int   `yaml:"max_body_bytes"`
+}
+
+// legacyFieldAliases maps the names accepted by earlier versions of overrides-sync onto the canonical field
+var legacyFieldAliases = map[string]string{
+	"samples_per_second":    "ingestion_rate",
+	"samples_per_sec":       "ingestion_rate",
+	"sps":                   "ingestion_rate",
+	"burst_percent":         "burst_pct",
+	"burst_percentage":      "burst_pct",
+	"max_request_size":      "max_body_bytes",
+	"max_request_body_size": "max_body_bytes",
+	"max_labels_per_series": "max_label_names_per_series",
+	"max_labels_per_metric": "max_label_names_per_series",
+	"labels_limit":          "max_label_names_per_series",
+	// RLS enforces max_series_per_request against the tenant's active series, i.e. Mimir's per-user limit
+	"max_series_per_request": "max_global_series_per_user",
+	"series_limit":           "max_global_series_per_user",
+	"max_series_per_metric":  "max_global_series_per_metric",
+}
+
+// knownFields holds the YAML name of every MimirLimits field
+var knownFields = func() map[string]bool {
+	fields := make(map[string]bool)
+	t := reflect.TypeOf(MimirLimits{})
+	for i := 0; i < t.NumField(); i++ {
+		fields[t.Field(i).Tag.Get("yaml")] = true
+	}
+	return fields
+}()
+
+// CanonicalFieldName normalises a limit name and resolves legacy aliases
+func CanonicalFieldName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if canonical, ok := legacyFieldAliases[name]; ok {
+		return canonical
+	}
+	return name
+}
+
+// IsKnownField reports whether a canonical field name is part of MimirLimits
+func IsKnownField(name string) bool {
+	return knownFields[name]
+}
+
+// DecodeMimirLimits decodes one tenant's overrides. Field names are canonicalised first; fields that are not
+// part of MimirLimits are returned as ignored. Values that cannot be decoded or fail validation are returned as errors.
+func DecodeMimirLimits(fields map[string]interface{}) (MimirLimits, []string, ValidationErrors) {
+	var ml MimirLimits
+	var ignored []string
+	var errs ValidationErrors
+
+	// Decode field by field so that every error names its field
+	for name, value := range fields {
+		field := CanonicalFieldName(name)
+		if !IsKnownField(field) {
+			ignored = append(ignored, name)
+			continue
+		}
+		raw, err := yaml.Marshal(map[string]interface{}{field: value})
+		if err == nil {
+			err = yaml.Unmarshal(raw, &ml)
+		}
+		if err != nil {
+			msg := err.Error()
+			if typeErr, ok := err.(*yaml.TypeError); ok {
+				msg = strings.Join(typeErr.Errors, "; ")
+			}
+			errs = append(errs, FieldError{Field: field, Message: msg})
+		}
+	}
+	sort.Strings(ignored)
+
+	errs = append(errs, ml.Validate()...)
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return ml, ignored, errs
+}
+
+// FieldError is a single invalid limit of a tenant
+type FieldError struct {
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// ValidationErrors lists everything wrong with a tenant's overrides
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	msgs := make([]string, len(v))
+	for i, e := range v {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Validate checks value ranges and the consistency between related limits
+func (m MimirLimits) Validate() ValidationErrors {
+	var errs ValidationErrors
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	nonNegativeFloat := func(field string, v *Float) {
+		if v != nil && (float64(*v) < 0 || math.IsNaN(float64(*v)) || math.IsInf(float64(*v), 0)) {
+			add(field, "must be a non-negative number, got %v", float64(*v))
+		}
+	}
+	nonNegativeInt := func(field string, v *Int, max int64) {
+		if v == nil {
+			return
+		}
+		if *v < 0 {
+			add(field, "must not be negative, got %d", int64(*v))
+		} else if int64(*v) > max {
+			add(field, "must not exceed %d, got %d", max, int64(*v))
+		}
+	}
+	nonNegativeDuration := func(field string, v *Duration) {
+		if v != nil && *v < 0 {
+			add(field, "must not be negative, got %s", time.Duration(*v))
+		}
+	}
+
+	nonNegativeFloat("ingestion_rate", m.IngestionRate)
+	nonNegativeInt("ingestion_burst_size", m.IngestionBurstSize, math.MaxInt64)
+	nonNegativeFloat("request_rate", m.RequestRate)
+	nonNegativeInt("request_burst_size", m.RequestBurstSize, math.MaxInt64)
+	nonNegativeInt("max_global_series_per_user", m.MaxGlobalSeriesPerUser, math.MaxInt32)
+	nonNegativeInt("max_global_series_per_metric", m.MaxGlobalSeriesPerMetric, math.MaxInt32)
+	nonNegativeInt("max_global_exemplars_per_user", m.MaxGlobalExemplarsPerUser, math.MaxInt32)
+	nonNegativeInt("max_global_metadata_per_user", m.MaxGlobalMetadataPerUser, math.MaxInt32)
+	nonNegativeInt("max_global_metadata_per_metric", m.MaxGlobalMetadataPerMetric, math.MaxInt32)
+	nonNegativeInt("max_label_names_per_series", m.MaxLabelNamesPerSeries, math.MaxInt32)
+	nonNegativeInt("max_label_name_length", m.MaxLabelNameLength, math.MaxInt32)
+	nonNegativeInt("max_label_value_length", m.MaxLabelValueLength, math.MaxInt32)
+	nonNegativeInt("max_metadata_length", m.MaxMetadataLength, math.MaxInt32)
+	nonNegativeInt("max_native_histogram_buckets", m.MaxNativeHistogramBuckets, math.MaxInt32)
+	nonNegativeInt("ha_max_clusters", m.HAMaxClusters, math.MaxInt32)
+	nonNegativeInt("max_body_bytes", m.MaxBodyBytes, math.MaxInt64)
+	nonNegativeFloat("burst_pct", m.BurstPercent)
+	nonNegativeDuration("out_of_order_time_window", m.OutOfOrderTimeWindow)
+	nonNegativeDuration("creation_grace_period", m.CreationGracePeriod)
+	nonNegativeDuration("past_grace_period", m.PastGracePeriod)
+
+	if m.IngestionRateStrategy != nil {
+		switch *m.IngestionRateStrategy {
+		case IngestionRateStrategyLocal, IngestionRateStrategyGlobal:
+		default:
+			add("ingestion_rate_strategy", "must be %q or %q, got %q", IngestionRateStrategyLocal, IngestionRateStrategyGlobal, *m.IngestionRateStrategy)
+		}
+	}
+	if m.HAClusterLabel != nil && *m.HAClusterLabel == "" {
+		add("ha_cluster_label", "must not be empty")
+	}
+	if m.HAReplicaLabel != nil && *m.HAReplicaLabel == "" {
+		add("ha_replica_label", "must not be empty")
+	}
+	if m.HAClusterLabel != nil && m.HAReplicaLabel != nil && *m.HAClusterLabel != "" && *m.HAClusterLabel == *m.HAReplicaLabel {
+		add("ha_replica_label", "must differ from ha_cluster_label %q", *m.HAClusterLabel)
+	}
+
+	return errs
+}
+
+// Apply maps the tenant's overrides one-to-one onto base; fields that are not set keep the value from base
+func (m MimirLimits) Apply(base TenantLimits) TenantLimits {
+	l := base
+	if m.IngestionRate != nil {
+		v := float64(*m.IngestionRate)
+		l.SamplesPerSecond = &v
+	}
+	if m.BurstPercent != nil {
+		v := float64(*m.BurstPercent)
+		l.BurstPercent = &v
+	}
+	l.MaxBodyBytes = int64Ptr(m.MaxBodyBytes, l.MaxBodyBytes)
+	l.MaxLabelsPerSeries = int32Ptr(m.MaxLabelNamesPerSeries, l.MaxLabelsPerSeries)
+	l.MaxLabelValueLength = int32Ptr(m.MaxLabelValueLength, l.MaxLabelValueLength)
+	l.MaxSeriesPerRequest = int32Ptr(m.MaxGlobalSeriesPerUser, l.MaxSeriesPerRequest)
+	l.MaxSeriesPerMetric = int32Ptr(m.MaxGlobalSeriesPerMetric, l.MaxSeriesPerMetric)
+
+	l.IngestionBurstSize = int64Ptr(m.IngestionBurstSize, l.IngestionBurstSize)
+	l.IngestionRateStrategy = orElse(m.IngestionRateStrategy, l.IngestionRateStrategy)
+	if m.RequestRate != nil {
+		v := float64(*m.RequestRate)
+		l.RequestRate = &v
+	}
+	l.RequestBurstSize = int64Ptr(m.RequestBurstSize, l.RequestBurstSize)
+	l.MaxGlobalExemplarsPerUser = int32Ptr(m.MaxGlobalExemplarsPerUser, l.MaxGlobalExemplarsPerUser)
+	l.MaxGlobalMetadataPerUser = int32Ptr(m.MaxGlobalMetadataPerUser, l.MaxGlobalMetadataPerUser)
+	l.MaxGlobalMetadataPerMetric = int32Ptr(m.MaxGlobalMetadataPerMetric, l.MaxGlobalMetadataPerMetric)
+	l.MaxLabelNameLength = int32Ptr(m.MaxLabelNameLength, l.MaxLabelNameLength)
+	l.MaxMetadataLength = int32Ptr(m.MaxMetadataLength, l.MaxMetadataLength)
+	l.MaxNativeHistogramBuckets = int32Ptr(m.MaxNativeHistogramBuckets, l.MaxNativeHistogramBuckets)
+	l.OutOfOrderTimeWindowSeconds = secondsPtr(m.OutOfOrderTimeWindow, l.OutOfOrderTimeWindowSeconds)
+	l.CreationGracePeriodSeconds = secondsPtr(m.CreationGracePeriod, l.CreationGracePeriodSeconds)
+	l.PastGracePeriodSeconds = secondsPtr(m.PastGracePeriod, l.PastGracePeriodSeconds)
+	if m.AcceptHASamples != nil {
+		v := bool(*m.AcceptHASamples)
+		l.AcceptHASamples = &v
+	}
+	l.HAClusterLabel = orElse(m.HAClusterLabel, l.HAClusterLabel)
+	l.HAReplicaLabel = orElse(m.HAReplicaLabel, l.HAReplicaLabel)
+	l.HAMaxClusters = int32Ptr(m.HAMaxClusters, l.HAMaxClusters)
+	return l
+}
+
+// Merge returns m with every field that is set in over replaced by the value from over,
+// e.g. Mimir's global limits merged with a tenant's overrides
+func (m MimirLimits) Merge(over MimirLimits) MimirLimits {
+	merged := m
+	mv := reflect.ValueOf(&merged).Elem()
+	ov := reflect.ValueOf(over)
+	for i := 0; i < ov.NumField(); i++ {
+		if !ov.Field(i).IsNil() {
+			mv.Field(i).Set(ov.Field(i))
+		}
+	}
+	return merged
+}
+
+func orElse[T any](v, fallback *T) *T {
+	if v == nil {
+		return fallback
+	}
+	return v
+}
+
+func int64Ptr(v *Int, fallback *int64) *int64 {
+	if v == nil {
+		return fallback
+	}
+	i := int64(*v)
+	return &i
+}
+
+func int32Ptr(v *Int, fallback *int32) *int32 {
+	if v == nil {
+		return fallback
+	}
+	i := int32(*v)
+	return &i
+}
+
+func secondsPtr(v *Duration, fallback *int64) *int64 {
+	if v == nil {
+		return fallback
+	}
+	s := int64(time.Duration(*v) / time.Second)
+	return &s
+}
+
+// Float is a number that may be written in scientific notation or quoted, e.g. 4e6 or "1.5e7"
+type Float float64
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (f *Float) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	v, err := ParseFloat(s)
+	if err != nil {
+		return &yaml.TypeError{Errors: []string{err.Error()}}
+	}
+	*f = Float(v)
+	return nil
+}
+
+// Int is an integer that may be written in scientific notation or quoted, e.g. 1e6 or "150000"
+type Int int64
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (i *Int) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	v, err := ParseInt64(s)
+	if err != nil {
+		return &yaml.TypeError{Errors: []string{err.Error()}}
+	}
+	*i = Int(v)
+	return nil
+}
+
+// Bool is a boolean that may be quoted, as in the legacy flat format
+type Bool bool
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (b *Bool) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	v, err := strconv.ParseBool(strings.TrimSpace(s))
+	if err != nil {
+		return &yaml.TypeError{Errors: []string{fmt.Sprintf("invalid boolean %q", s)}}
+	}
+	*b = Bool(v)
+	return nil
+}
+
+// Duration is a Prometheus-style duration as used by Mimir, e.g. 10m, 1h30m or 7d
+type Duration time.Duration
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	v, err := ParseDuration(s)
+	if err != nil {
+		return &yaml.TypeError{Errors: []string{err.Error()}}
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// ParseFloat parses a number that may use scientific notation
+func ParseFloat(value string) (float64, error) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", value)
+	}
+	return f, nil
+}
+
+// ParseInt64 parses an integer that may use scientific notation
+func ParseInt64(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return i, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer %q", value)
+	}
+	if f != math.Trunc(f) {
+		return 0, fmt.Errorf("invalid integer %q: has a fractional part", value)
+	}
+	if f > float64(math.MaxInt64) || f < float64(math.MinInt64) {
+		return 0, fmt.Errorf("value out of range for int64: %s", value)
+	}
+	return int64(f), nil
+}
+
+var durationRE = regexp.MustCompile(`^(([0-9]+)y)?(([0-9]+)w)?(([0-9]+)d)?(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?$`)
+
+// ParseDuration parses a Prometheus-style duration, which adds d, w and y units to Go's syntax
+func ParseDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "0" {
+		return 0, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return d, nil
+	}
+	matches := durationRE.FindStringSubmatch(value)
+	if value == "" || matches == nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	units := []time.Duration{365 * 24 * time.Hour, 7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second, time.Millisecond}
+	var d time.Duration
+	for i, unit := range units {
+		if n := matches[2*i+2]; n != "" {
+			v, err := strconv.ParseInt(n, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", value)
+			}
+			d += time.Duration(v) * unit
+		}
+	}
+	return d, nil
+}
@@ -0,0 +1,130 @@
+package limits
+
+import (
+	"testing"
+)
+
+func TestCanonicalFieldName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "ingestion_rate", want: "ingestion_rate"},
+		{name: "samples_per_second", want: "ingestion_rate"},
+		{name: "samples_per_sec", want: "ingestion_rate"},
+		{name: " SPS ", want: "ingestion_rate"},
+		{name: "burst_percent", want: "burst_pct"},
+		{name: "burst_percentage", want: "burst_pct"},
+		{name: "max_request_size", want: "max_body_bytes"},
+		{name: "max_request_body_size", want: "max_body_bytes"},
+		{name: "max_labels_per_series", want: "max_label_names_per_series"},
+		{name: "labels_limit", want: "max_label_names_per_series"},
+		{name: "max_series_per_request", want: "max_global_series_per_user"},
+		{name: "series_limit", want: "max_global_series_per_user"},
+		{name: "max_series_per_metric", want: "max_global_series_per_metric"},
+		{name: "compactor_blocks_retention_period", want: "compactor_blocks_retention_period"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanonicalFieldName(tt.name); got != tt.want {
+				t.Fatalf("CanonicalFieldName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeMimirLimitsLegacyAliases(t *testing.T) {
+	ml, ignored, errs := DecodeMimirLimits(map[string]interface{}{
+		"samples_per_second":     "1.5e4",
+		"burst_percent":          0.5,
+		"max_request_size":       "4e6",
+		"max_series_per_request": 2000,
+		"ruler_max_rules":        10,
+	})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(ignored) != 1 || ignored[0] != "ruler_max_rules" {
+		t.Fatalf("ignored = %v, want [ruler_max_rules]", ignored)
+	}
+	if ml.IngestionRate == nil || *ml.IngestionRate != 15000 {
+		t.Errorf("ingestion_rate = %v, want 15000", ml.IngestionRate)
+	}
+	if ml.BurstPercent == nil || *ml.BurstPercent != 0.5 {
+		t.Errorf("burst_pct = %v, want 0.5", ml.BurstPercent)
+	}
+	if ml.MaxBodyBytes == nil || *ml.MaxBodyBytes != 4000000 {
+		t.Errorf("max_body_bytes = %v, want 4000000", ml.MaxBodyBytes)
+	}
+	if ml.MaxGlobalSeriesPerUser == nil || *ml.MaxGlobalSeriesPerUser != 2000 {
+		t.Errorf("max_global_series_per_user = %v, want 2000", ml.MaxGlobalSeriesPerUser)
+	}
+}
+
+func TestDecodeMimirLimitsErrors(t *testing.T) {
+	_, _, errs := DecodeMimirLimits(map[string]interface{}{
+		"ingestion_rate":           "fast",
+		"out_of_order_time_window": "1h30m",
+		"past_grace_period":        "7d",
+	})
+	if len(errs) != 1 || errs[0].Field != "ingestion_rate" {
+		t.Fatalf("errors = %v, want a single ingestion_rate error", errs)
+	}
+}
+
+func TestMimirLimitsValidate(t *testing.T) {
+	floatPtr := func(v float64) *Float { f := Float(v); return &f }
+	intPtr := func(v int64) *Int { i := Int(v); return &i }
+	durationPtr := func(v int64) *Duration { d := Duration(v); return &d }
+	stringPtr := func(v string) *string { return &v }
+
+	tests := []struct {
+		name       string
+		limits     MimirLimits
+		wantFields []string
+	}{
+		{name: "empty", limits: MimirLimits{}},
+		{
+			name: "valid",
+			limits: MimirLimits{
+				IngestionRate:          floatPtr(1e5),
+				MaxGlobalSeriesPerUser: intPtr(150000),
+				IngestionRateStrategy:  stringPtr(IngestionRateStrategyGlobal),
+				HAClusterLabel:         stringPtr("cluster"),
+				HAReplicaLabel:         stringPtr("__replica__"),
+			},
+		},
+		{name: "negative rate", limits: MimirLimits{IngestionRate: floatPtr(-1)}, wantFields: []string{"ingestion_rate"}},
+		{name: "negative count", limits: MimirLimits{MaxLabelNamesPerSeries: intPtr(-1)}, wantFields: []string{"max_label_names_per_series"}},
+		{name: "int32 overflow", limits: MimirLimits{MaxGlobalSeriesPerUser: intPtr(1 << 40)}, wantFields: []string{"max_global_series_per_user"}},
+		{name: "int64 limits allow large values", limits: MimirLimits{MaxBodyBytes: intPtr(1 << 40)}},
+		{name: "negative duration", limits: MimirLimits{PastGracePeriod: durationPtr(-1)}, wantFields: []string{"past_grace_period"}},
+		{name: "unknown strategy", limits: MimirLimits{IngestionRateStrategy: stringPtr("fast")}, wantFields: []string{"ingestion_rate_strategy"}},
+		{name: "empty HA label", limits: MimirLimits{HAClusterLabel: stringPtr("")}, wantFields: []string{"ha_cluster_label"}},
+		{
+			name:       "same HA labels",
+			limits:     MimirLimits{HAClusterLabel: stringPtr("x"), HAReplicaLabel: stringPtr("x")},
+			wantFields: []string{"ha_replica_label"},
+		},
+		{
+			name:       "every error is reported",
+			limits:     MimirLimits{IngestionRate: floatPtr(-1), BurstPercent: floatPtr(-1)},
+			wantFields: []string{"ingestion_rate", "burst_pct"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.limits.Validate()
+			if len(errs) != len(tt.wantFields) {
+				t.Fatalf("errors = %v, want errors for %v", errs, tt.wantFields)
+			}
+			for i, field := range tt.wantFields {
+				if errs[i].Field != field {
+					t.Errorf("error %d is for %s, want %s", i, errs[i].Field, field)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package limits
+
+// EnforcementConfig is RLS's enforcement configuration of a tenant
+type EnforcementConfig struct {
+	Enabled          bool    `json:"enabled"`
+	BurstPctOverride float64 `json:"burst_pct_override"`
+
+	EnforceSamplesPerSecond    bool `json:"enforce_samples_per_second,omitempty"`
+	EnforceMaxBodyBytes        bool `json:"enforce_max_body_bytes,omitempty"`
+	EnforceMaxLabelsPerSeries  bool `json:"enforce_max_labels_per_series,omitempty"`
+	EnforceMaxSeriesPerRequest bool `json:"enforce_max_series_per_request,omitempty"`
+	EnforceMaxSeriesPerMetric  bool `json:"enforce_max_series_per_metric,omitempty"`
+	EnforceBytesPerSecond      bool `json:"enforce_bytes_per_second,omitempty"`
+}
+
+// EdgePolicy holds the edge-only settings of a tenant as RLS expects them
+type EdgePolicy struct {
+	Source             string                    `json:"source,omitempty"` // namespace/name of the EdgeEnforcementPolicy
+	Enforcement        *EnforcementConfig        `json:"enforcement,omitempty"`
+	ShadowMode         bool                      `json:"shadow_mode,omitempty"`
+	SelectiveFiltering *SelectiveFilteringPolicy `json:"selective_filtering,omitempty"`
+}
+
+// SelectiveFilteringPolicy is a tenant's selective filtering configuration; unset fields use RLS's global flags
+type SelectiveFilteringPolicy struct {
+	Enabled                *bool    `json:"enabled,omitempty"`
+	Strategy               string   `json:"strategy,omitempty"`
+	MetricPriority         []string `json:"metric_priority,omitempty"`
+	MaxFilteringPercentage *int64   `json:"max_filtering_percentage,omitempty"`
+	MinSeriesToKeep        *int64   `json:"min_series_to_keep,omitempty"`
+}
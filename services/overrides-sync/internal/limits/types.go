@@ -1,12 +1,32 @@
 package limits
 
-// TenantLimits represents the limits for a tenant
+// TenantLimits represents the limits set for a tenant. Unset (nil) fields are not sent, so RLS resolves them
+// from the tenant's profile and its defaults.
 type TenantLimits struct {
-	SamplesPerSecond    float64 `json:"samples_per_second"`
-	BurstPercent        float64 `json:"burst_pct"`
-	MaxBodyBytes        int64   `json:"max_body_bytes"`
-	MaxLabelsPerSeries  int32   `json:"max_labels_per_series"`
-	MaxLabelValueLength int32   `json:"max_label_value_length"`
-	MaxSeriesPerRequest int32   `json:"max_series_per_request"`
-	MaxSeriesPerMetric  int32   `json:"max_series_per_metric"` // 🔧 NEW: Per-metric series limit
+	SamplesPerSecond    *float64 `json:"samples_per_second,omitempty"`
+	BurstPercent        *float64 `json:"burst_pct,omitempty"`
+	MaxBodyBytes        *int64   `json:"max_body_bytes,omitempty"`
+	MaxLabelsPerSeries  *int32   `json:"max_labels_per_series,omitempty"`
+	MaxLabelValueLength *int32   `json:"max_label_value_length,omitempty"`
+	MaxSeriesPerRequest *int32   `json:"max_series_per_request,omitempty"`
+	MaxSeriesPerMetric  *int32   `json:"max_series_per_metric,omitempty"` // 🔧 NEW: Per-metric series limit
+
+	// 🔧 NEW: Remaining ingestion limits from Mimir's overrides, sent only when set for the tenant
+	IngestionBurstSize          *int64   `json:"ingestion_burst_size,omitempty"`
+	IngestionRateStrategy       *string  `json:"ingestion_rate_strategy,omitempty"`
+	RequestRate                 *float64 `json:"request_rate,omitempty"`
+	RequestBurstSize            *int64   `json:"request_burst_size,omitempty"`
+	MaxGlobalExemplarsPerUser   *int32   `json:"max_global_exemplars_per_user,omitempty"`
+	MaxGlobalMetadataPerUser    *int32   `json:"max_global_metadata_per_user,omitempty"`
+	MaxGlobalMetadataPerMetric  *int32   `json:"max_global_metadata_per_metric,omitempty"`
+	MaxLabelNameLength          *int32   `json:"max_label_name_length,omitempty"`
+	MaxMetadataLength           *int32   `json:"max_metadata_length,omitempty"`
+	MaxNativeHistogramBuckets   *int32   `json:"max_native_histogram_buckets,omitempty"`
+	OutOfOrderTimeWindowSeconds *int64   `json:"out_of_order_time_window_seconds,omitempty"`
+	CreationGracePeriodSeconds  *int64   `json:"creation_grace_period_seconds,omitempty"`
+	PastGracePeriodSeconds      *int64   `json:"past_grace_period_seconds,omitempty"`
+	AcceptHASamples             *bool    `json:"accept_ha_samples,omitempty"`
+	HAClusterLabel              *string  `json:"ha_cluster_label,omitempty"`
+	HAReplicaLabel              *string  `json:"ha_replica_label,omitempty"`
+	HAMaxClusters               *int32   `json:"ha_max_clusters,omitempty"`
 }
@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"math"
@@ -10,18 +13,32 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
+	admin "github.com/AkshayDubey29/mimir-edge-enforcement/protos/admin"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/auth"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/cardinality"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/cluster"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/decisionlog"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/forensics"
 	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/limits"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/notify"
 	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/service"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/tracing"
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/keepalive"
@@ -58,6 +75,23 @@ func parseScientificNotationInt64(value string) (int64, error) {
 	return i, nil
 }
 
+// 🔧 NEW: parseOTLPHeaders parses a comma-separated list of name=value headers sent to an OTLP endpoint
+func parseOTLPHeaders(spec string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid header %q: expected name=value", entry)
+		}
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
 // parseScientificNotationFloat64 parses a string value that may contain scientific notation
 func parseScientificNotationFloat64(value string) (float64, error) {
 	// Handle scientific notation (e.g., "4e6", "1.5e7", "4E6")
@@ -84,6 +118,8 @@ var (
 	rateLimitPort = flag.String("rate-limit-port", "8081", "Port for ratelimit gRPC server")
 	adminPort     = flag.String("admin-port", "8082", "Port for admin HTTP server")
 	metricsPort   = flag.String("metrics-port", "9090", "Port for metrics HTTP server")
+	// 🔧 NEW: gRPC admin API port
+	adminGRPCPort = flag.String("admin-grpc-port", "8083", "Port for admin gRPC server")
 
 	// Configuration
 	tenantHeader       = flag.String("tenant-header", "X-Scope-OrgID", "Header name for tenant identification")
@@ -125,10 +161,127 @@ var (
 	// 🔧 NEW: New tenant leniency configuration
 	newTenantLeniency = flag.Bool("new-tenant-leniency", true, "Enable lenient limits for new tenants (50% of normal limits)")
 
+	// 🔧 NEW: Limit profiles (tiers) that tenants can reference
+	limitProfilesFile = flag.String("limit-profiles-file", "", "Path to a JSON file mapping profile names to limits (e.g. bronze/silver/gold)")
+
+	// 🔧 NEW: Upper bound on temporary limit overrides
+	maxOverrideDuration = flag.Duration("max-override-duration", 7*24*time.Hour, "Longest a temporary limit override may last (0 allows any expiry)")
+
+	// 🔧 NEW: Admin API authentication (HTTP and gRPC)
+	adminAuthEnabled         = flag.Bool("admin-auth-enabled", false, "Require authentication and role-based authorization on the admin APIs")
+	adminAuthTokensFile      = flag.String("admin-auth-tokens-file", "", "Path to a JSON file of static bearer tokens and their roles")
+	adminAuthJWKSFile        = flag.String("admin-auth-oidc-jwks-file", "", "Path to a JWKS file used to validate OIDC bearer JWTs")
+	adminAuthOIDCIssuer      = flag.String("admin-auth-oidc-issuer", "", "Expected issuer (iss) of OIDC JWTs")
+	adminAuthOIDCAudience    = flag.String("admin-auth-oidc-audience", "", "Expected audience (aud) of OIDC JWTs")
+	adminAuthOIDCRoleClaim   = flag.String("admin-auth-oidc-role-claim", "roles", "JWT claim holding the caller's role(s): viewer, operator or admin")
+	adminAuthOIDCTenantClaim = flag.String("admin-auth-oidc-tenant-claim", "tenant", "JWT claim holding the tenant(s) the caller is scoped to")
+	adminAuthMTLSRoles       = flag.String("admin-auth-mtls-roles", "", "Client certificate CN to role mapping for mTLS, e.g. overrides-sync=operator,sre=admin")
+	adminAuthSyncSubjects    = flag.String("admin-auth-overrides-sync-subjects", "overrides-sync", "Comma-separated authenticated subjects (token subject or client certificate CN) whose changes are audited as overrides-sync")
+	adminTLSCertFile         = flag.String("admin-tls-cert-file", "", "TLS certificate for the admin HTTP and gRPC servers")
+	adminTLSKeyFile          = flag.String("admin-tls-key-file", "", "TLS private key for the admin HTTP and gRPC servers")
+	adminTLSClientCAFile     = flag.String("admin-tls-client-ca-file", "", "CA bundle used to verify admin client certificates (enables mTLS)")
+
+	// 🔧 NEW: Decision recording for the admin analytics
+	decisionBufferSize      = flag.Int("decision-buffer-size", 65536, "Capacity of the lock-free decision ring buffers (one for denials, one for allows)")
+	decisionAllowSampleRate = flag.Float64("decision-allow-sample-rate", 1.0, "Fraction of allowed decisions recorded for analytics (0-1); denials are always recorded")
+
+	// 🔧 NEW: Persistence of time-aggregated analytics
+	aggregatorPersistence      = flag.String("aggregator-persistence", "", "Persist aggregated analytics buckets: file, redis or empty to keep them in memory only")
+	aggregatorSnapshotPath     = flag.String("aggregator-snapshot-path", "/var/lib/rls/aggregator.snapshot", "Snapshot file used by the file aggregator persistence")
+	aggregatorSnapshotInterval = flag.Duration("aggregator-snapshot-interval", time.Minute, "How often aggregated analytics are persisted and peer replicas' buckets merged")
+	replicaID                  = flag.String("replica-id", "", "Identity of this replica to its peers and in decision logs (defaults to $POD_NAME or the hostname)")
+	aggregatorReplicaID        = flag.String("aggregator-replica-id", "", "Identity of this replica's aggregated analytics snapshot, which must survive restarts to restore its history (defaults to $POD_NAME of a StatefulSet pod, else the replica ID)")
+
+	// 🔧 NEW: Cluster-wide admin views merged across RLS replicas
+	clusterPeers         = flag.String("cluster-peers", "", "Comma-separated admin host:port of the RLS replicas; this replica is skipped by IP, hostname or a DNS name starting with its replica ID")
+	clusterPeersDNS      = flag.String("cluster-peers-dns", "", "Headless service name resolving to every RLS replica (uses the admin port)")
+	clusterPeerTimeout   = flag.Duration("cluster-peer-timeout", 2*time.Second, "Timeout of each peer request when merging cluster views")
+	clusterPeerCAFile    = flag.String("cluster-peer-ca-file", "", "CA bundle used to verify peers when the admin API is served over TLS")
+	clusterPeerCertFile  = flag.String("cluster-peer-cert-file", "", "Client certificate presented to peers for callers without a bearer token, e.g. those authenticated by mTLS; its CN needs a role in the peers' admin-auth-mtls-roles")
+	clusterPeerKeyFile   = flag.String("cluster-peer-key-file", "", "Private key of cluster-peer-cert-file")
+	clusterFanoutDefault = flag.Bool("cluster-fanout-default", false, "Serve merged cluster views unless a request asks for scope=local")
+
+	// 🔧 NEW: Cardinality alert rules
+	alertEvaluationInterval    = flag.Duration("alert-evaluation-interval", 30*time.Second, "How often cardinality alert rules are evaluated (0 disables alerting)")
+	alertSeriesUtilizationWarn = flag.Float64("alert-series-utilization-warning-pct", 80, "Warn when a tenant's active series reach this percentage of its series limit")
+	alertSeriesUtilizationCrit = flag.Float64("alert-series-utilization-critical-pct", 95, "Critical alert when a tenant's active series reach this percentage of its series limit")
+	alertSeriesGrowthPct       = flag.Float64("alert-series-growth-pct", 50, "Alert when a tenant's active series grow by this percentage within the growth window (0 disables)")
+	alertSeriesGrowthWindow    = flag.Duration("alert-series-growth-window", time.Hour, "Window over which series growth is measured")
+	alertSeriesGrowthMinSeries = flag.Int64("alert-series-growth-min-series", 1000, "Ignore series growth of tenants with fewer series at the start of the window")
+	alertMetricHotSpotPct      = flag.Float64("alert-metric-hotspot-pct", 90, "Alert when a single metric reaches this percentage of the per-metric series limit (0 disables)")
+	alertDenyRatePct           = flag.Float64("alert-deny-rate-pct", 10, "Alert when a tenant's 15m deny rate stays above this percentage (0 disables)")
+	alertDenyRateFor           = flag.Duration("alert-deny-rate-for", 10*time.Minute, "How long the deny rate must stay above the threshold before alerting")
+	alertDenyRateMinRequests   = flag.Int64("alert-deny-rate-min-requests", 100, "Ignore deny rates of tenants with fewer requests in the 15m window")
+	alertResolvedRetention     = flag.Duration("alert-resolved-retention", time.Hour, "How long resolved alerts remain visible")
+	alertmanagerExternalURL    = flag.String("alertmanager-external-url", "", "URL reported as externalURL and generatorURL in Alertmanager-format exports")
+
+	// 🔧 NEW: Outbound notifications of enforcement events
+	notifyWebhooks      = flag.String("notify-webhooks", "", "Comma-separated kind=url notification targets; kind is alertmanager, slack or generic")
+	notifyEvents        = flag.String("notify-events", "", "Comma-separated event types to notify (first_denial, series_threshold, safety_valve, limits_changed, cardinality_alert, cardinality_resolve); empty sends all")
+	notifyBatchInterval = flag.Duration("notify-batch-interval", 10*time.Second, "How often queued notifications are sent")
+	notifyBatchSize     = flag.Int("notify-batch-size", 100, "Send queued notifications as soon as this many are waiting")
+	notifyMaxRetries    = flag.Int("notify-max-retries", 5, "Retries of a failed webhook delivery, with exponential backoff")
+	notifyQuietPeriod   = flag.Duration("notify-quiet-period", 10*time.Minute, "Denial-free time after which a tenant's next denial is notified again")
+	notifyEventTTL      = flag.Duration("notify-event-ttl", 5*time.Minute, "How long one-shot events stay active in Alertmanager")
+	notifyLocalReceiver = flag.Bool("notify-local-receiver", false, "Serve a stand-in webhook receiver at /api/notifications/receiver for local testing")
+
+	// 🔧 NEW: Per-tenant top-K cardinality explorer
+	cardinalitySampleRate     = flag.Float64("cardinality-explorer-sample-rate", 0.1, "Fraction of parsed requests tracked by the cardinality explorer (0 disables it)")
+	cardinalityTopMetrics     = flag.Int("cardinality-explorer-top-metrics", 50, "Metrics monitored per tenant by the cardinality explorer")
+	cardinalityTopLabelNames  = flag.Int("cardinality-explorer-top-label-names", 30, "Label names monitored per tenant by the cardinality explorer")
+	cardinalityTopLabelValues = flag.Int("cardinality-explorer-top-label-values", 10, "Values monitored per label name by the cardinality explorer")
+	cardinalityWindow         = flag.Duration("cardinality-explorer-window", time.Hour, "Window over which the cardinality explorer counts series (reports cover the current and previous window)")
+	cardinalityMaxTenants     = flag.Int("cardinality-explorer-max-tenants", 1000, "Tenants tracked by the cardinality explorer; the least recently seen is dropped beyond this")
+
+	// 🔧 NEW: Opt-in per-tenant forensic capture of offending series
+	forensicsDir                  = flag.String("forensics-dir", "", "Directory holding forensic captures of denied and filtered series (empty disables forensic mode)")
+	forensicsMaxBytes             = flag.Int64("forensics-max-bytes", 256<<20, "Total size of forensic captures; the oldest are removed beyond it")
+	forensicsMaxCapturesPerTenant = flag.Int("forensics-max-captures-per-tenant", 100, "Forensic captures kept per tenant; the oldest are removed beyond it")
+	forensicsMaxSeriesPerCapture  = flag.Int("forensics-max-series-per-capture", 10000, "Offending series stored per forensic capture")
+
+	// 🔧 NEW: Structured decision log stream
+	decisionLogSink              = flag.String("decision-log-sink", "", "Where one record per decision is written: stdout, file or otlp (empty disables the decision log)")
+	decisionLogDecisions         = flag.String("decision-log-decisions", "all", "Decisions written to the decision log: all, allow or deny")
+	decisionLogSampleRate        = flag.Float64("decision-log-sample-rate", 1.0, "Fraction of decisions written to the decision log")
+	decisionLogTenantSampleRates = flag.String("decision-log-tenant-sample-rates", "", "Comma-separated tenant=rate entries overriding --decision-log-sample-rate")
+	decisionLogQueueSize         = flag.Int("decision-log-queue-size", 10000, "Decision records buffered before new ones are dropped")
+	decisionLogFile              = flag.String("decision-log-file", "/var/log/rls/decisions.jsonl", "Decision log file for the file sink")
+	decisionLogFileMaxBytes      = flag.Int64("decision-log-file-max-bytes", 100<<20, "Size at which the decision log file is rotated")
+	decisionLogFileMaxBackups    = flag.Int("decision-log-file-max-backups", 5, "Rotated decision log files kept")
+	decisionLogOTLPEndpoint      = flag.String("decision-log-otlp-endpoint", "", "OTLP/HTTP logs endpoint for the otlp sink (e.g. http://otel-collector:4318/v1/logs)")
+	decisionLogOTLPHeaders       = flag.String("decision-log-otlp-headers", "", "Comma-separated name=value headers sent to the OTLP endpoint")
+
+	// 🔧 NEW: OpenTelemetry tracing
+	tracingOTLPEndpoint = flag.String("tracing-otlp-endpoint", "", "OTLP traces endpoint URL (e.g. http://otel-collector:4318/v1/traces, or http://otel-collector:4317 with grpc); http:// disables TLS; empty disables tracing")
+	tracingOTLPProtocol = flag.String("tracing-otlp-protocol", "http/protobuf", "OTLP protocol for traces: http/protobuf or grpc")
+	tracingOTLPHeaders  = flag.String("tracing-otlp-headers", "", "Comma-separated name=value headers sent to the OTLP traces endpoint")
+	tracingSampleRatio  = flag.Float64("tracing-sample-ratio", 0.1, "Fraction of new traces recorded; requests with a traceparent follow its sampled flag")
+	tracingServiceName  = flag.String("tracing-service-name", "mimir-rls", "service.name reported with exported spans")
+
+	// 🔧 NEW: Label cardinality caps for RLS's own /metrics
+	selfMetricsTenantLabel   = flag.String("self-metrics-tenant-label", "raw", "Tenant label on RLS's own metrics: raw (tenant ID), hash (FNV-1a of the tenant ID) or bucket (one of --self-metrics-tenant-buckets; per-tenant gauges then show the last tenant written)")
+	selfMetricsMaxTenants    = flag.Int("self-metrics-max-tenants", 1000, "Distinct tenant labels on RLS's own metrics; later tenants are reported as __other__ (0 = unlimited)")
+	selfMetricsTenantBuckets = flag.Int("self-metrics-tenant-buckets", 64, "Number of tenant buckets when --self-metrics-tenant-label=bucket")
+	selfMetricsMetricTopK    = flag.Int("self-metrics-metric-series-top-k", 20, "Client metric names per tenant on rls_metric_series_count_gauge, largest first (0 disables the gauge)")
+
+	// 🔧 NEW: HA deduplication for tenants with accept_ha_samples
+	haFailoverTimeout  = flag.Duration("ha-failover-timeout", 30*time.Second, "How long the elected replica of an HA cluster may send nothing before another replica is elected (Mimir's -distributor.ha-tracker.failover-timeout)")
+	haUpdateTimeout    = flag.Duration("ha-update-timeout", 15*time.Second, "How long an HA election read from the store is trusted before it is read, and the elected replica refreshed, again; must be below --ha-failover-timeout")
+	haRejectNonElected = flag.Bool("ha-reject-non-elected", false, "Answer requests of non-elected HA replicas with 202 at the edge instead of forwarding them to Mimir uncounted")
+
 	// Logging
 	logLevel = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
 )
 
+// 🔧 NEW: Stand-in webhook receiver served when --notify-local-receiver is set
+var notifyReceiver *notify.Receiver
+
+// 🔧 NEW: Authenticated subjects audited as overrides-sync rather than the admin API
+var overridesSyncSubjects = make(map[string]bool)
+
+// 🔧 NEW: Peer fan-out used by the admin handlers for cluster-wide views; nil when no peers are configured
+var clusterFanout *cluster.Fanout
+
 func main() {
 	flag.Parse()
 
@@ -156,6 +309,48 @@ func main() {
 	zerolog.SetGlobalLevel(level)
 	logger := log.With().Str("component", "rls").Logger()
 
+	// 🔧 NEW: Load limit profiles
+	limitProfiles, err := loadLimitProfiles(*limitProfilesFile)
+	if err != nil {
+		log.Fatal().Err(err).Str("path", *limitProfilesFile).Msg("invalid limit-profiles-file")
+	}
+
+	// 🔧 NEW: Notification targets
+	notificationConfig, err := buildNotificationConfig()
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid notification configuration")
+	}
+	if *notifyLocalReceiver {
+		notifyReceiver = notify.NewReceiver(0)
+	}
+
+	// 🔧 NEW: Decision log sink and sampling
+	decisionLogConfig, err := buildDecisionLogConfig()
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid decision log configuration")
+	}
+
+	// 🔧 NEW: The elected HA replica must be refreshed before it can fail over
+	if *haUpdateTimeout <= 0 || *haUpdateTimeout >= *haFailoverTimeout {
+		log.Fatal().
+			Dur("ha_update_timeout", *haUpdateTimeout).
+			Dur("ha_failover_timeout", *haFailoverTimeout).
+			Msg("--ha-update-timeout must be positive and below --ha-failover-timeout")
+	}
+
+	// 🔧 NEW: Self-metric tenant label mode
+	switch *selfMetricsTenantLabel {
+	case service.TenantLabelRaw, service.TenantLabelHash, service.TenantLabelBucket:
+	default:
+		log.Fatal().Str("mode", *selfMetricsTenantLabel).Msg("invalid --self-metrics-tenant-label: expected raw, hash or bucket")
+	}
+
+	// 🔧 NEW: Trace export
+	tracingHeaders, err := parseOTLPHeaders(*tracingOTLPHeaders)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid tracing headers")
+	}
+
 	// Log parsed values for debugging
 	logger.Info().
 		Float64("default_samples_per_second", defaultSamplesPerSecond).
@@ -174,6 +369,69 @@ func main() {
 		MimirHost:          *mimirHost,
 		MimirPort:          *mimirPort,
 		NewTenantLeniency:  *newTenantLeniency, // 🔧 NEW: Add new tenant leniency configuration
+		LimitProfiles:      limitProfiles,      // 🔧 NEW: Named limit profiles
+		// 🔧 NEW: Upper bound on temporary overrides
+		MaxOverrideDuration: *maxOverrideDuration,
+		// 🔧 NEW: Decision recording pipeline
+		DecisionBufferSize:      *decisionBufferSize,
+		DecisionAllowSampleRate: *decisionAllowSampleRate,
+		// 🔧 NEW: Aggregated analytics persistence
+		AggregatorPersistence:      *aggregatorPersistence,
+		AggregatorSnapshotPath:     *aggregatorSnapshotPath,
+		AggregatorSnapshotInterval: *aggregatorSnapshotInterval,
+		ReplicaID:                  resolveReplicaID(*replicaID),
+		AggregatorReplicaID:        resolveAggregatorReplicaID(*aggregatorReplicaID, resolveReplicaID(*replicaID)),
+		// 🔧 NEW: Cardinality alert rules
+		AlertRules: service.AlertRulesConfig{
+			EvaluationInterval:           *alertEvaluationInterval,
+			SeriesUtilizationWarningPct:  *alertSeriesUtilizationWarn,
+			SeriesUtilizationCriticalPct: *alertSeriesUtilizationCrit,
+			SeriesGrowthPct:              *alertSeriesGrowthPct,
+			SeriesGrowthWindow:           *alertSeriesGrowthWindow,
+			SeriesGrowthMinSeries:        *alertSeriesGrowthMinSeries,
+			MetricHotSpotPct:             *alertMetricHotSpotPct,
+			DenyRatePct:                  *alertDenyRatePct,
+			DenyRateFor:                  *alertDenyRateFor,
+			DenyRateMinRequests:          *alertDenyRateMinRequests,
+			ResolvedRetention:            *alertResolvedRetention,
+		},
+		// 🔧 NEW: Enforcement notifications
+		Notifications:     notificationConfig,
+		NotifyQuietPeriod: *notifyQuietPeriod,
+		// 🔧 NEW: Cardinality explorer
+		CardinalityExplorer: cardinality.Config{
+			TopMetrics:     *cardinalityTopMetrics,
+			TopLabelNames:  *cardinalityTopLabelNames,
+			TopLabelValues: *cardinalityTopLabelValues,
+			Window:         *cardinalityWindow,
+			MaxTenants:     *cardinalityMaxTenants,
+			SampleRate:     *cardinalitySampleRate,
+		},
+		DecisionLog: decisionLogConfig,
+		SelfMetrics: service.SelfMetricsConfig{
+			TenantLabelMode:  *selfMetricsTenantLabel,
+			MaxTenants:       *selfMetricsMaxTenants,
+			TenantBuckets:    *selfMetricsTenantBuckets,
+			MetricSeriesTopK: *selfMetricsMetricTopK,
+		},
+		HATracker: service.HATrackerConfig{
+			FailoverTimeout:  *haFailoverTimeout,
+			UpdateTimeout:    *haUpdateTimeout,
+			RejectNonElected: *haRejectNonElected,
+		},
+		Tracing: tracing.Config{
+			Endpoint:    *tracingOTLPEndpoint,
+			Protocol:    *tracingOTLPProtocol,
+			Headers:     tracingHeaders,
+			SampleRatio: *tracingSampleRatio,
+			ServiceName: *tracingServiceName,
+		},
+		Forensics: forensics.Config{
+			Dir:                  *forensicsDir,
+			MaxBytes:             *forensicsMaxBytes,
+			MaxCapturesPerTenant: *forensicsMaxCapturesPerTenant,
+			MaxSeriesPerCapture:  *forensicsMaxSeriesPerCapture,
+		},
 		// 🔧 NEW: Add selective filtering configuration
 		SelectiveFiltering: service.SelectiveFilteringConfig{
 			Enabled:                 *selectiveFilteringEnabled,
@@ -201,9 +459,21 @@ func main() {
 		},
 	}
 
+	// 🔧 NEW: Admin API authentication and TLS
+	security, err := buildAdminSecurity()
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid admin authentication configuration")
+	}
+
 	// Create RLS service
 	rls := service.NewRLS(config, logger)
 
+	// 🔧 NEW: Peer fan-out for cluster-wide admin views
+	clusterFanout, err = buildClusterFanout(config.ReplicaID, logger)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid cluster peer configuration")
+	}
+
 	// Create context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -213,15 +483,17 @@ func main() {
 		Str("ext_authz_port", *extAuthzPort).
 		Str("rate_limit_port", *rateLimitPort).
 		Str("admin_port", *adminPort).
+		Str("admin_grpc_port", *adminGRPCPort).
 		Str("metrics_port", *metricsPort).
 		Msg("starting RLS service components")
 
 	// Start gRPC servers
 	go startExtAuthzServer(ctx, rls, *extAuthzPort, logger)
 	go startRateLimitServer(ctx, rls, *rateLimitPort, logger)
+	go startAdminGRPCServer(ctx, rls, *adminGRPCPort, security, logger)
 
 	// Start HTTP servers
-	go startAdminServer(ctx, rls, *adminPort, logger)
+	go startAdminServer(ctx, rls, *adminPort, security, logger)
 	go startMetricsServer(ctx, *metricsPort, logger)
 
 	// 🔧 FIX: Add proper startup validation and graceful shutdown
@@ -237,6 +509,22 @@ func main() {
 	// Trigger graceful shutdown
 	cancel()
 
+	// 🔧 NEW: Deliver notifications still queued
+	rls.StopNotifier()
+
+	// 🔧 NEW: Write decision records still queued
+	rls.StopDecisionLog()
+
+	// 🔧 NEW: Export spans still queued
+	rls.StopTracing()
+
+	// 🔧 NEW: Save aggregated analytics so the next start picks up where this one left off
+	persistCtx, persistCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := rls.PersistAggregator(persistCtx); err != nil {
+		logger.Error().Err(err).Msg("failed to persist aggregated analytics on shutdown")
+	}
+	persistCancel()
+
 	// Wait for all servers to shutdown gracefully
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
@@ -250,6 +538,158 @@ func main() {
 	logger.Info().Msg("RLS service stopped")
 }
 
+// buildClusterFanout creates the peer fan-out client from the cluster flags; it is nil when no peers are configured
+func buildClusterFanout(replica string, logger zerolog.Logger) (*cluster.Fanout, error) {
+	config := cluster.Config{
+		Self:    replica,
+		DNSName: *clusterPeersDNS,
+		Port:    *adminPort,
+		Timeout: *clusterPeerTimeout,
+	}
+	for _, peer := range strings.Split(*clusterPeers, ",") {
+		if peer = strings.TrimSpace(peer); peer != "" {
+			config.StaticPeers = append(config.StaticPeers, peer)
+		}
+	}
+
+	if *adminTLSCertFile != "" {
+		config.Scheme = "https"
+		config.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		if *clusterPeerCAFile != "" {
+			caPEM, err := os.ReadFile(*clusterPeerCAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read cluster peer CA file: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(caPEM) {
+				return nil, fmt.Errorf("no certificates found in %s", *clusterPeerCAFile)
+			}
+			config.TLSConfig.RootCAs = pool
+		}
+	}
+	if *clusterPeerCertFile != "" || *clusterPeerKeyFile != "" {
+		if config.TLSConfig == nil {
+			return nil, fmt.Errorf("cluster-peer-cert-file requires the admin API to be served over TLS (admin-tls-cert-file)")
+		}
+		cert, err := tls.LoadX509KeyPair(*clusterPeerCertFile, *clusterPeerKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load cluster peer client certificate: %w", err)
+		}
+		config.ClientCertificate = &cert
+	}
+
+	fanout := cluster.NewFanout(config, logger.With().Str("replica", replica).Logger())
+	if fanout != nil && *adminAuthEnabled && *adminAuthMTLSRoles != "" && config.ClientCertificate == nil {
+		// A client certificate cannot be forwarded, so peers reject cluster views of callers authenticated by one
+		logger.Warn().Msg("admin mTLS authentication without cluster-peer-cert-file: scope=cluster requests of mTLS callers fail on every peer; they need a bearer token")
+	}
+	if fanout != nil {
+		logger.Info().
+			Strs("static_peers", config.StaticPeers).
+			Str("peers_dns", config.DNSName).
+			Bool("fanout_default", *clusterFanoutDefault).
+			Msg("cluster fan-out enabled for admin views")
+	}
+	return fanout, nil
+}
+
+// buildNotificationConfig assembles the notifier configuration from the notify-* flags
+func buildNotificationConfig() (notify.Config, error) {
+	webhooks, err := notify.ParseWebhooks(*notifyWebhooks)
+	if err != nil {
+		return notify.Config{}, err
+	}
+
+	events := make(map[string]bool)
+	for _, event := range strings.Split(*notifyEvents, ",") {
+		if event = strings.TrimSpace(event); event != "" {
+			events[event] = true
+		}
+	}
+
+	return notify.Config{
+		Webhooks:      webhooks,
+		Events:        events,
+		BatchSize:     *notifyBatchSize,
+		BatchInterval: *notifyBatchInterval,
+		MaxRetries:    *notifyMaxRetries,
+		EventTTL:      *notifyEventTTL,
+		ExternalURL:   *alertmanagerExternalURL,
+	}, nil
+}
+
+// buildDecisionLogConfig assembles the decision log configuration from the decision-log-* flags
+func buildDecisionLogConfig() (decisionlog.Config, error) {
+	tenantRates, err := decisionlog.ParseTenantSampleRates(*decisionLogTenantSampleRates)
+	if err != nil {
+		return decisionlog.Config{}, err
+	}
+	headers, err := parseOTLPHeaders(*decisionLogOTLPHeaders)
+	if err != nil {
+		return decisionlog.Config{}, err
+	}
+
+	return decisionlog.Config{
+		Sink:              *decisionLogSink,
+		Decisions:         *decisionLogDecisions,
+		SampleRate:        *decisionLogSampleRate,
+		TenantSampleRates: tenantRates,
+		QueueSize:         *decisionLogQueueSize,
+		FilePath:          *decisionLogFile,
+		FileMaxBytes:      *decisionLogFileMaxBytes,
+		FileMaxBackups:    *decisionLogFileMaxBackups,
+		OTLPEndpoint:      *decisionLogOTLPEndpoint,
+		OTLPHeaders:       headers,
+	}, nil
+}
+
+// resolveReplicaID returns the configured replica ID, falling back to the pod name and then the hostname
+func resolveReplicaID(configured string) string {
+	if configured != "" {
+		return configured
+	}
+	if podName := os.Getenv("POD_NAME"); podName != "" {
+		return podName
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		return hostname
+	}
+	return "rls"
+}
+
+// statefulSetPodName matches the name of a StatefulSet pod, which ends in its ordinal and is kept across restarts
+var statefulSetPodName = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?-[0-9]+$`)
+
+// resolveAggregatorReplicaID returns the configured aggregator snapshot identity, falling back to the pod name of a
+// StatefulSet pod and then to the replica ID. Pods of a Deployment get a new name on every restart, so their history
+// is only kept by compaction of the snapshots of replicas that stopped writing.
+func resolveAggregatorReplicaID(configured, replicaID string) string {
+	if configured != "" {
+		return configured
+	}
+	if podName := os.Getenv("POD_NAME"); statefulSetPodName.MatchString(podName) {
+		return podName
+	}
+	return replicaID
+}
+
+// loadLimitProfiles reads limit profiles from a JSON file of the form {"gold": {"samples_per_second": 1e5}}
+func loadLimitProfiles(path string) (map[string]limits.LimitOverrides, error) {
+	profiles := make(map[string]limits.LimitOverrides)
+	if path == "" {
+		return profiles, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read limit profiles: %w", err)
+	}
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse limit profiles: %w", err)
+	}
+	return profiles, nil
+}
+
 func startExtAuthzServer(ctx context.Context, rls *service.RLS, port string, logger zerolog.Logger) {
 	lis, err := net.Listen("tcp", ":"+port)
 	if err != nil {
@@ -350,9 +790,57 @@ func startRateLimitServer(ctx context.Context, rls *service.RLS, port string, lo
 	}
 }
 
-func startAdminServer(ctx context.Context, rls *service.RLS, port string, logger zerolog.Logger) {
+func startAdminGRPCServer(ctx context.Context, rls *service.RLS, port string, security adminSecurity, logger zerolog.Logger) {
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		logger.Fatal().Err(err).Str("port", port).Msg("failed to listen for admin gRPC")
+	}
+
+	var opts []grpc.ServerOption
+	if security.tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(security.tlsConfig)))
+	}
+	if security.authenticator != nil {
+		opts = append(opts, grpc.UnaryInterceptor(auth.UnaryServerInterceptor(security.authenticator, adminGRPCRole)))
+	}
+	grpcServer := grpc.NewServer(opts...)
+
+	// Register admin service
+	admin.RegisterAdminServiceServer(grpcServer, service.NewAdminServer(rls))
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("admin.AdminService", grpc_health_v1.HealthCheckResponse_SERVING)
+	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
+
+	// Enable reflection for debugging
+	reflection.Register(grpcServer)
+
+	logger.Info().
+		Str("port", port).
+		Bool("auth_enabled", security.authenticator != nil).
+		Bool("tls_enabled", security.tlsConfig != nil).
+		Msg("admin gRPC server started")
+
+	go func() {
+		<-ctx.Done()
+		logger.Info().Msg("shutting down admin gRPC server")
+		healthServer.SetServingStatus("admin.AdminService", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+		grpcServer.GracefulStop()
+	}()
+
+	if err := grpcServer.Serve(lis); err != nil {
+		logger.Error().Err(err).Msg("admin gRPC server stopped")
+	}
+}
+
+func startAdminServer(ctx context.Context, rls *service.RLS, port string, security adminSecurity, logger zerolog.Logger) {
 	router := mux.NewRouter()
 
+	// 🔧 NEW: Authenticate and authorize admin requests by role
+	if security.authenticator != nil {
+		router.Use(auth.Middleware(security.authenticator, adminHTTPRole))
+	}
+
 	// 🔧 PERFORMANCE FIX: Remove excessive logging middleware - only log errors
 	router.Use(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -402,8 +890,34 @@ func startAdminServer(ctx context.Context, rls *service.RLS, port string, logger
 	router.HandleFunc("/api/overview", handleOverview(rls)).Methods("GET")
 	router.HandleFunc("/api/tenants", handleListTenants(rls)).Methods("GET")
 	router.HandleFunc("/api/tenants/{id}", handleGetTenant(rls)).Methods("GET")
+	router.HandleFunc("/api/tenants/{id}", handleDeleteTenant(rls)).Methods("DELETE")
 	router.HandleFunc("/api/tenants/{id}/enforcement", handleSetEnforcement(rls)).Methods("POST")
 	router.HandleFunc("/api/tenants/{id}/limits", handleSetTenantLimits(rls)).Methods("PUT")
+	router.HandleFunc("/api/limits", handleGetLimitsVersion(rls)).Methods("GET")
+	router.HandleFunc("/api/limits", handleSetAllTenantLimits(rls)).Methods("PUT")
+	router.HandleFunc("/api/tenants/{id}/history", handleTenantHistory(rls)).Methods("GET")
+	router.HandleFunc("/api/tenants/{id}/ha-replicas", handleTenantHAReplicas(rls)).Methods("GET")
+	router.HandleFunc("/api/tenants/{id}/rollback", handleRollbackTenant(rls)).Methods("POST")
+	router.HandleFunc("/api/tenants/{id}/override", handleSetTemporaryOverride(rls)).Methods("PUT")
+	router.HandleFunc("/api/tenants/{id}/override", handleClearTemporaryOverride(rls)).Methods("DELETE")
+	router.HandleFunc("/api/tenants/{id}/limits/effective", handleEffectiveLimits(rls)).Methods("GET")
+	router.HandleFunc("/api/tenants/{id}/profile", handleSetTenantProfile(rls)).Methods("PUT")
+	router.HandleFunc("/api/tenants/{id}/policy", handleSetTenantPolicy(rls)).Methods("PUT")
+	router.HandleFunc("/api/tenants/{id}/policy", handleClearTenantPolicy(rls)).Methods("DELETE")
+	router.HandleFunc("/api/tenants/{id}/cardinality/top", handleTenantCardinalityTop(rls)).Methods("GET")
+	router.HandleFunc("/api/tenants/{id}/forensics", handleGetTenantForensics(rls)).Methods("GET")
+	router.HandleFunc("/api/tenants/{id}/forensics", handleSetTenantForensics(rls)).Methods("PUT")
+	router.HandleFunc("/api/tenants/{id}/forensics", handlePurgeTenantForensics(rls)).Methods("DELETE")
+	router.HandleFunc("/api/tenants/{id}/forensics/{capture}", handleDownloadTenantCapture(rls)).Methods("GET")
+	router.HandleFunc("/api/profiles", handleListProfiles(rls)).Methods("GET")
+
+	// 🔧 NEW: Tenant self-service endpoints, read-only and scoped to the caller's own tenants
+	router.HandleFunc("/api/self/tenants", handleSelfListTenants(rls)).Methods("GET")
+	router.HandleFunc("/api/self/tenants/{id}", handleSelfTenant(rls)).Methods("GET")
+	router.HandleFunc("/api/self/tenants/{id}/denials", handleSelfTenantDenials(rls)).Methods("GET")
+	router.HandleFunc("/api/self/tenants/{id}/series", handleSelfTenantSeries(rls)).Methods("GET")
+	router.HandleFunc("/api/self/tenants/{id}/cardinality/top", handleSelfTenantCardinalityTop(rls)).Methods("GET")
+
 	router.HandleFunc("/api/denials", handleListDenials(rls)).Methods("GET")
 	router.HandleFunc("/api/denials/enhanced", handleEnhancedDenials(rls)).Methods("GET")
 	router.HandleFunc("/api/denials/trends", handleDenialTrends(rls)).Methods("GET")
@@ -480,6 +994,11 @@ func startAdminServer(ctx context.Context, rls *service.RLS, port string, logger
 	router.HandleFunc("/api/cardinality/trends", handleCardinalityTrends(rls)).Methods("GET")
 	router.HandleFunc("/api/cardinality/alerts", handleCardinalityAlerts(rls)).Methods("GET")
 
+	// 🔧 NEW: Stand-in webhook receiver; point --notify-webhooks at it to inspect notifications
+	if notifyReceiver != nil {
+		router.PathPrefix("/api/notifications/receiver").Handler(notifyReceiver)
+	}
+
 	// Time-based aggregated data endpoints
 	router.HandleFunc("/api/aggregated/{timeRange}", handleAggregatedData(rls)).Methods("GET")
 
@@ -506,9 +1025,14 @@ func startAdminServer(ctx context.Context, rls *service.RLS, port string, logger
 		ReadTimeout:  30 * time.Second,  // 🔧 FIX: Increased timeout to prevent 504 errors
 		WriteTimeout: 60 * time.Second,  // 🔧 FIX: Increased timeout for admin API responses
 		IdleTimeout:  120 * time.Second, // Keep connection timeout reasonable
+		TLSConfig:    security.tlsConfig,
 	}
 
-	logger.Info().Str("port", port).Msg("admin HTTP server started")
+	logger.Info().
+		Str("port", port).
+		Bool("auth_enabled", security.authenticator != nil).
+		Bool("tls_enabled", security.tlsConfig != nil).
+		Msg("admin HTTP server started")
 
 	// 🔧 FIX: Add graceful shutdown handling for HTTP server
 	go func() {
@@ -521,11 +1045,138 @@ func startAdminServer(ctx context.Context, rls *service.RLS, port string, logger
 		}
 	}()
 
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	var err error
+	if security.tlsConfig != nil {
+		err = server.ListenAndServeTLS("", "")
+	} else {
+		err = server.ListenAndServe()
+	}
+	if err != nil && err != http.ErrServerClosed {
 		logger.Fatal().Err(err).Msg("failed to serve admin")
 	}
 }
 
+// adminSecurity holds the authentication and TLS settings shared by the admin HTTP and gRPC servers
+type adminSecurity struct {
+	authenticator auth.Authenticator // nil when authentication is disabled
+	tlsConfig     *tls.Config        // nil when TLS is disabled
+}
+
+// buildAdminSecurity builds the admin authenticator chain and TLS configuration from flags
+func buildAdminSecurity() (adminSecurity, error) {
+	var security adminSecurity
+
+	if *adminTLSCertFile != "" || *adminTLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(*adminTLSCertFile, *adminTLSKeyFile)
+		if err != nil {
+			return security, fmt.Errorf("failed to load admin TLS key pair: %w", err)
+		}
+		security.tlsConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+
+		if *adminTLSClientCAFile != "" {
+			caPEM, err := os.ReadFile(*adminTLSClientCAFile)
+			if err != nil {
+				return security, fmt.Errorf("failed to read admin client CA: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(caPEM) {
+				return security, fmt.Errorf("no certificates found in %s", *adminTLSClientCAFile)
+			}
+			security.tlsConfig.ClientCAs = pool
+			// Bearer tokens remain usable for clients without certificates
+			security.tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+
+	if !*adminAuthEnabled {
+		return security, nil
+	}
+	for _, subject := range strings.Split(*adminAuthSyncSubjects, ",") {
+		if subject = strings.TrimSpace(subject); subject != "" {
+			overridesSyncSubjects[subject] = true
+		}
+	}
+
+	var chain auth.Chain
+	if *adminAuthMTLSRoles != "" {
+		if security.tlsConfig == nil || security.tlsConfig.ClientCAs == nil {
+			return security, fmt.Errorf("admin-auth-mtls-roles requires admin-tls-cert-file, admin-tls-key-file and admin-tls-client-ca-file")
+		}
+		mtls, err := auth.NewMTLSAuthenticator(*adminAuthMTLSRoles)
+		if err != nil {
+			return security, err
+		}
+		chain = append(chain, mtls)
+	}
+	if *adminAuthTokensFile != "" {
+		tokens, err := auth.LoadStaticTokens(*adminAuthTokensFile)
+		if err != nil {
+			return security, err
+		}
+		chain = append(chain, tokens)
+	}
+	if *adminAuthJWKSFile != "" {
+		oidc, err := auth.NewJWTAuthenticator(auth.OIDCConfig{
+			JWKSFile:    *adminAuthJWKSFile,
+			Issuer:      *adminAuthOIDCIssuer,
+			Audience:    *adminAuthOIDCAudience,
+			RoleClaim:   *adminAuthOIDCRoleClaim,
+			TenantClaim: *adminAuthOIDCTenantClaim,
+		})
+		if err != nil {
+			return security, err
+		}
+		chain = append(chain, oidc)
+	}
+	if len(chain) == 0 {
+		return security, fmt.Errorf("admin-auth-enabled requires at least one of admin-auth-tokens-file, admin-auth-oidc-jwks-file or admin-auth-mtls-roles")
+	}
+
+	security.authenticator = chain
+	return security, nil
+}
+
+// adminHTTPRole returns the role required for an admin HTTP request
+func adminHTTPRole(r *http.Request) auth.Role {
+	path := r.URL.Path
+	switch {
+	case path == "/healthz" || path == "/readyz" || path == "/api/v1/push":
+		// Probes and the remote write proxy are not admin traffic
+		return auth.RoleNone
+	case strings.HasPrefix(path, "/api/debug/"):
+		return auth.RoleAdmin
+	case notifyReceiver != nil && strings.HasPrefix(path, "/api/notifications/receiver"):
+		// Webhook senders do not authenticate against the stand-in receiver
+		return auth.RoleNone
+	case strings.HasPrefix(path, "/api/self/"):
+		// Handlers narrow tenant-role callers to their own tenants
+		if r.Method != http.MethodGet {
+			return auth.RoleOperator
+		}
+		return auth.RoleTenant
+	case r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions:
+		return auth.RoleViewer
+	default:
+		return auth.RoleOperator
+	}
+}
+
+// adminGRPCRole returns the role required for an admin gRPC method
+func adminGRPCRole(fullMethod string) auth.Role {
+	switch {
+	case strings.HasPrefix(fullMethod, "/admin.AdminService/Set"):
+		return auth.RoleOperator
+	case strings.HasPrefix(fullMethod, "/admin.AdminService/"):
+		return auth.RoleViewer
+	default:
+		// Health checks and reflection
+		return auth.RoleNone
+	}
+}
+
 func startMetricsServer(ctx context.Context, port string, logger zerolog.Logger) {
 	// 🔧 FIX: Implement proper Prometheus metrics server
 	mux := http.NewServeMux()
@@ -597,11 +1248,28 @@ func handleOverview(rls *service.RLS) http.HandlerFunc {
 		// Use time-based aggregated data for stable overview
 		stats := rls.GetOverviewSnapshotWithTimeRange(timeRange)
 
+		// 🔧 NEW: Sum the counters of every replica. Persisted peer aggregates are already included, so skip the fan-out then.
+		var breakdown []replicaView[limits.OverviewStats]
+		if wantClusterScope(r) && !rls.AggregatesIncludePeers() {
+			var views []limits.OverviewStats
+			views, breakdown = fanoutViews(r, stats, func(body []byte) (limits.OverviewStats, error) {
+				var peer struct {
+					Stats limits.OverviewStats `json:"stats"`
+				}
+				err := json.Unmarshal(body, &peer)
+				return peer.Stats, err
+			})
+			stats = cluster.MergeOverview(views)
+		}
+
 		response := map[string]any{
 			"stats":          stats,
 			"time_range":     timeRange,
 			"data_freshness": time.Now().Format(time.RFC3339),
 		}
+		if breakdown != nil {
+			addClusterFields(r, response, breakdown)
+		}
 
 		// 🔧 PERFORMANCE FIX: Only log at debug level to reduce overhead
 		log.Debug().
@@ -643,6 +1311,20 @@ func handleListTenants(rls *service.RLS) http.HandlerFunc {
 		// Use time-based aggregated data for stable tenant metrics
 		tenants := rls.GetTenantsWithTimeRange(timeRange)
 
+		// 🔧 NEW: Merge tenant metrics of every replica unless persisted peer aggregates already include them
+		var breakdown []replicaView[[]limits.TenantInfo]
+		if wantClusterScope(r) && !rls.AggregatesIncludePeers() {
+			var views [][]limits.TenantInfo
+			views, breakdown = fanoutViews(r, tenants, func(body []byte) ([]limits.TenantInfo, error) {
+				var peer struct {
+					Tenants []limits.TenantInfo `json:"tenants"`
+				}
+				err := json.Unmarshal(body, &peer)
+				return peer.Tenants, err
+			})
+			tenants = cluster.MergeTenants(views)
+		}
+
 		// 🔧 PERFORMANCE FIX: Only log at debug level to reduce overhead
 		tenantLogger := log.Debug().
 			Str("time_range", timeRange).
@@ -675,6 +1357,9 @@ func handleListTenants(rls *service.RLS) http.HandlerFunc {
 			"time_range":     timeRange,
 			"data_freshness": time.Now().Format(time.RFC3339),
 		}
+		if breakdown != nil {
+			addClusterFields(r, response, breakdown)
+		}
 		writeJSON(w, http.StatusOK, response)
 	}
 }
@@ -794,7 +1479,7 @@ func handleSetEnforcement(rls *service.RLS) http.HandlerFunc {
 		}
 
 		// Set enforcement configuration in RLS
-		if err := rls.SetTenantEnforcement(id, enforcement); err != nil {
+		if err := rls.SetTenantEnforcement(id, enforcement, changeContextFromRequest(r)); err != nil {
 			log.Error().Err(err).Str("tenant_id", id).Msg("failed to set tenant enforcement")
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -829,8 +1514,8 @@ func handleSetTenantLimits(rls *service.RLS) http.HandlerFunc {
 
 		id := mux.Vars(r)["id"]
 
-		// Parse request body
-		var tenantLimits limits.TenantLimits
+		// Parse request body; fields left out inherit from the tenant's profile and the global defaults
+		var tenantLimits limits.LimitOverrides
 		if err := json.NewDecoder(r.Body).Decode(&tenantLimits); err != nil {
 			log.Error().Err(err).Str("tenant_id", id).Msg("failed to decode tenant limits JSON")
 			http.Error(w, "invalid JSON body", http.StatusBadRequest)
@@ -838,82 +1523,1015 @@ func handleSetTenantLimits(rls *service.RLS) http.HandlerFunc {
 		}
 
 		// Set tenant limits in RLS
-		if err := rls.SetTenantLimits(id, tenantLimits); err != nil {
+		if err := rls.SetTenantLimits(id, tenantLimits, changeContextFromRequest(r)); err != nil {
+			// The limits are only persisted after they are applied, so the one error left is an invalid limit
 			log.Error().Err(err).Str("tenant_id", id).Msg("failed to set tenant limits")
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
+		effective, _ := rls.GetEffectiveLimits(id)
 		log.Info().
 			Str("tenant_id", id).
-			Float64("samples_per_second", tenantLimits.SamplesPerSecond).
-			Float64("burst_percent", tenantLimits.BurstPercent).
-			Int64("max_body_bytes", tenantLimits.MaxBodyBytes).
+			Float64("samples_per_second", effective.Limits.SamplesPerSecond).
+			Float64("burst_percent", effective.Limits.BurstPercent).
+			Int64("max_body_bytes", effective.Limits.MaxBodyBytes).
 			Msg("RLS: tenant limits set via HTTP API from overrides-sync")
 
 		writeJSON(w, http.StatusOK, map[string]any{
-			"success":   true,
-			"tenant_id": id,
-			"limits":    tenantLimits,
+			"success":          true,
+			"tenant_id":        id,
+			"limits":           tenantLimits,
+			"effective_limits": effective.Limits,
 		})
 	}
 }
 
-func handleListDenials(rls *service.RLS) http.HandlerFunc {
+// 🔧 NEW: Set a tenant's edge policy: enforcement, shadow mode and selective filtering
+func handleSetTenantPolicy(rls *service.RLS) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		tenant := r.URL.Query().Get("tenant")
-		sinceParam := r.URL.Query().Get("since")
-		if sinceParam == "" {
-			sinceParam = "1h"
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Interface("panic", r).Msg("panic in handleSetTenantPolicy")
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+		}()
+
+		id := mux.Vars(r)["id"]
+		var policy limits.EdgePolicy
+		if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+			log.Error().Err(err).Str("tenant_id", id).Msg("failed to decode tenant policy JSON")
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
 		}
-		d, _ := time.ParseDuration(sinceParam)
-		denials := rls.RecentDenials(tenant, d)
-		writeJSON(w, http.StatusOK, map[string]any{"denials": denials})
+
+		if err := rls.SetTenantPolicy(id, &policy, changeContextFromRequest(r)); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"success":   true,
+			"tenant_id": id,
+			"policy":    policy,
+		})
 	}
 }
 
-// handleEnhancedDenials returns enriched denial information with context and insights
-func handleEnhancedDenials(rls *service.RLS) http.HandlerFunc {
+// 🔧 NEW: Clear a tenant's edge policy; it falls back to the default enforcement and global filtering settings
+func handleClearTenantPolicy(rls *service.RLS) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		tenant := r.URL.Query().Get("tenant")
-		sinceParam := r.URL.Query().Get("since")
-		if sinceParam == "" {
-			sinceParam = "1h"
-		}
-		d, _ := time.ParseDuration(sinceParam)
-
-		// Get limit parameter for server-side pagination (default to 25 for performance)
-		limitParam := r.URL.Query().Get("limit")
-		limit := 25 // Aggressive limit for performance
-		if limitParam != "" {
-			if parsedLimit, err := strconv.Atoi(limitParam); err == nil && parsedLimit > 0 && parsedLimit <= 100 {
-				limit = parsedLimit
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Interface("panic", r).Msg("panic in handleClearTenantPolicy")
+				http.Error(w, "internal server error", http.StatusInternalServerError)
 			}
-		}
+		}()
 
-		// Start with a shorter time range for better performance
-		adjustedSince := d
-		if d > 15*time.Minute {
-			adjustedSince = 15 * time.Minute // Limit to 15 minutes for performance
+		id := mux.Vars(r)["id"]
+		if err := rls.SetTenantPolicy(id, nil, changeContextFromRequest(r)); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
 		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"success":   true,
+			"tenant_id": id,
+		})
+	}
+}
 
-		// Get basic denials first
-		basicDenials := rls.RecentDenials(tenant, adjustedSince)
-		totalCount := len(basicDenials)
-
-		// Aggressively limit denials for performance
-		if len(basicDenials) > limit {
-			basicDenials = basicDenials[:limit]
-		}
+// maxBulkLimitsBytes bounds the body of a bulk limits request
+const maxBulkLimitsBytes = 64 << 20
 
-		// Convert to enhanced format but skip heavy processing if too many
-		var denials []map[string]any
-		for i, denial := range basicDenials {
-			if i >= limit { // Double check limit
-				break
+// 🔧 NEW: Apply the limits of many tenants at once as one versioned set, e.g. everything in Mimir's overrides
+func handleSetAllTenantLimits(rls *service.RLS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Interface("panic", r).Msg("panic in handleSetAllTenantLimits")
+				http.Error(w, "internal server error", http.StatusInternalServerError)
 			}
+		}()
 
-			// Simplified enhanced denial without heavy processing
+		var request struct {
+			Version  string                           `json:"version"`
+			Tenants  map[string]limits.LimitOverrides `json:"tenants"`
+			Defaults *limits.LimitOverrides           `json:"defaults"` // omitted: the synced defaults are left alone
+			Policies map[string]*limits.EdgePolicy    `json:"policies"` // omitted: policies are left alone
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBulkLimitsBytes)).Decode(&request); err != nil {
+			log.Error().Err(err).Msg("failed to decode bulk tenant limits JSON")
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+
+		result, err := rls.SetAllTenantLimits(request.Version, request.Tenants, request.Defaults, request.Policies, changeContextFromRequest(r))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"success": true,
+			"result":  result,
+		})
+	}
+}
+
+// 🔧 NEW: Version of the last set of tenant limits applied in bulk, so callers can skip sending it again
+func handleGetLimitsVersion(rls *service.RLS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Interface("panic", r).Msg("panic in handleGetLimitsVersion")
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+		}()
+
+		writeJSON(w, http.StatusOK, rls.LimitsVersion())
+	}
+}
+
+// 🔧 NEW: Forget a tenant's configuration, e.g. when it disappears from Mimir's overrides
+func handleDeleteTenant(rls *service.RLS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Interface("panic", r).Msg("panic in handleDeleteTenant")
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+		}()
+
+		id := mux.Vars(r)["id"]
+		if err := rls.DeleteTenant(id, changeContextFromRequest(r)); err != nil {
+			log.Error().Err(err).Str("tenant_id", id).Msg("failed to delete tenant")
+			status := http.StatusInternalServerError
+			if errors.Is(err, service.ErrTenantNotFound) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{
+			"success":   true,
+			"tenant_id": id,
+		})
+	}
+}
+
+func handleTenantHistory(rls *service.RLS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Interface("panic", r).Msg("panic in handleTenantHistory")
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+		}()
+
+		id := mux.Vars(r)["id"]
+		limit := 100
+		if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+			parsed, err := strconv.Atoi(limitParam)
+			if err != nil || parsed < 0 {
+				http.Error(w, "invalid limit", http.StatusBadRequest)
+				return
+			}
+			limit = parsed
+		}
+
+		events, err := rls.GetTenantHistory(id, limit)
+		if err != nil {
+			log.Error().Err(err).Str("tenant_id", id).Msg("failed to load tenant history")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{
+			"tenant_id": id,
+			"events":    events,
+			"total":     len(events),
+		})
+	}
+}
+
+// 🔧 NEW: Elected replica of each HA cluster of a tenant
+func handleTenantHAReplicas(rls *service.RLS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Interface("panic", r).Msg("panic in handleTenantHAReplicas")
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+		}()
+
+		id := mux.Vars(r)["id"]
+		replicas, err := rls.GetHAReplicas(id)
+		if err != nil {
+			log.Error().Err(err).Str("tenant_id", id).Msg("failed to load HA replicas")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{
+			"tenant_id": id,
+			"clusters":  replicas,
+			"total":     len(replicas),
+		})
+	}
+}
+
+func handleRollbackTenant(rls *service.RLS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Interface("panic", r).Msg("panic in handleRollbackTenant")
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+		}()
+
+		id := mux.Vars(r)["id"]
+
+		var req struct {
+			Revision int64 `json:"revision"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Revision <= 0 {
+			http.Error(w, "invalid JSON body: revision is required", http.StatusBadRequest)
+			return
+		}
+
+		restored, err := rls.RollbackTenant(id, req.Revision, changeContextFromRequest(r))
+		if err != nil {
+			log.Error().Err(err).Str("tenant_id", id).Int64("revision", req.Revision).Msg("failed to roll back tenant")
+			status := http.StatusInternalServerError
+			if errors.Is(err, service.ErrRevisionNotFound) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		log.Info().
+			Str("tenant_id", id).
+			Int64("revision", req.Revision).
+			Msg("RLS: tenant rolled back via HTTP API")
+
+		writeJSON(w, http.StatusOK, map[string]any{
+			"success":     true,
+			"tenant_id":   id,
+			"revision":    req.Revision,
+			"limits":      restored.Limits,
+			"enforcement": restored.Enforcement,
+		})
+	}
+}
+
+func handleSetTemporaryOverride(rls *service.RLS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Interface("panic", r).Msg("panic in handleSetTemporaryOverride")
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+		}()
+
+		id := mux.Vars(r)["id"]
+
+		// Either an absolute expires_at or a relative duration (e.g. "4h") must be given
+		var req struct {
+			SamplesPerSecond    *float64  `json:"samples_per_second"`
+			MaxSeriesPerRequest *int32    `json:"max_series_per_request"`
+			Duration            string    `json:"duration"`
+			ExpiresAt           time.Time `json:"expires_at"`
+			Reason              string    `json:"reason"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error().Err(err).Str("tenant_id", id).Msg("failed to decode temporary override JSON")
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+
+		expiresAt := req.ExpiresAt
+		if req.Duration != "" {
+			duration, err := time.ParseDuration(req.Duration)
+			if err != nil || duration <= 0 {
+				http.Error(w, "invalid duration", http.StatusBadRequest)
+				return
+			}
+			expiresAt = time.Now().Add(duration)
+		}
+
+		override := limits.TemporaryOverride{
+			SamplesPerSecond:    req.SamplesPerSecond,
+			MaxSeriesPerRequest: req.MaxSeriesPerRequest,
+			Reason:              req.Reason,
+			ExpiresAt:           expiresAt,
+		}
+		if err := rls.SetTemporaryOverride(id, override, changeContextFromRequest(r)); err != nil {
+			log.Error().Err(err).Str("tenant_id", id).Msg("failed to set temporary override")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		tenant, _ := rls.GetTenantSnapshot(id)
+		writeJSON(w, http.StatusOK, map[string]any{
+			"success":            true,
+			"tenant_id":          id,
+			"temporary_override": tenant.TemporaryOverride,
+			"effective_limits":   tenant.Limits,
+		})
+	}
+}
+
+func handleClearTemporaryOverride(rls *service.RLS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Interface("panic", r).Msg("panic in handleClearTemporaryOverride")
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+		}()
+
+		id := mux.Vars(r)["id"]
+		if err := rls.ClearTemporaryOverride(id, changeContextFromRequest(r)); err != nil {
+			log.Error().Err(err).Str("tenant_id", id).Msg("failed to clear temporary override")
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		tenant, _ := rls.GetTenantSnapshot(id)
+		writeJSON(w, http.StatusOK, map[string]any{
+			"success":          true,
+			"tenant_id":        id,
+			"effective_limits": tenant.Limits,
+		})
+	}
+}
+
+func handleEffectiveLimits(rls *service.RLS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+		effective, ok := rls.GetEffectiveLimits(id)
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]any{
+				"error":     "tenant not found",
+				"tenant_id": id,
+			})
+			return
+		}
+		writeJSON(w, http.StatusOK, effective)
+	}
+}
+
+func handleSetTenantProfile(rls *service.RLS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Interface("panic", r).Msg("panic in handleSetTenantProfile")
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+		}()
+
+		id := mux.Vars(r)["id"]
+
+		var req struct {
+			Profile string `json:"profile"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+
+		if err := rls.SetTenantProfile(id, req.Profile, changeContextFromRequest(r)); err != nil {
+			log.Error().Err(err).Str("tenant_id", id).Str("profile", req.Profile).Msg("failed to set tenant profile")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		effective, _ := rls.GetEffectiveLimits(id)
+		writeJSON(w, http.StatusOK, map[string]any{
+			"success":          true,
+			"tenant_id":        id,
+			"profile":          req.Profile,
+			"effective_limits": effective,
+		})
+	}
+}
+
+func handleListProfiles(rls *service.RLS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		profiles := rls.GetLimitProfiles()
+		writeJSON(w, http.StatusOK, map[string]any{
+			"profiles": profiles,
+			"total":    len(profiles),
+		})
+	}
+}
+
+// changeContextFromRequest identifies the caller of an admin mutation for the audit log.
+// The actor is the authenticated subject, or the remote address with authentication disabled. Changes of
+// the subjects in --admin-auth-overrides-sync-subjects are audited as overrides-sync, any other as the
+// admin API. Neither is taken from request headers, which any caller can set.
+func changeContextFromRequest(r *http.Request) service.ChangeContext {
+	change := service.ChangeContext{Actor: r.RemoteAddr, Source: service.AuditSourceAdminAPI}
+	if identity, ok := auth.IdentityFromContext(r.Context()); ok && identity.Subject != "" {
+		change.Actor = identity.Subject
+		if overridesSyncSubjects[identity.Subject] {
+			change.Source = service.AuditSourceOverridesSync
+		}
+	}
+	return change
+}
+
+// selfServiceTenant returns the tenant in the request path if the caller may read it.
+// With authentication disabled every tenant is readable, matching the rest of the admin API.
+func selfServiceTenant(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := mux.Vars(r)["id"]
+	if identity, ok := auth.IdentityFromContext(r.Context()); ok && !identity.CanAccessTenant(id) {
+		// Do not reveal whether other tenants exist
+		writeJSON(w, http.StatusNotFound, map[string]any{
+			"error":     "tenant not found",
+			"tenant_id": id,
+		})
+		return "", false
+	}
+	return id, true
+}
+
+func handleSelfListTenants(rls *service.RLS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Interface("panic", r).Msg("panic in handleSelfListTenants")
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+		}()
+
+		var candidates []string
+		if identity, ok := auth.IdentityFromContext(r.Context()); ok && identity.Role < auth.RoleViewer {
+			candidates = identity.Tenants
+		} else {
+			for _, t := range rls.ListTenantsWithMetrics() {
+				candidates = append(candidates, t.ID)
+			}
+		}
+
+		tenants := make([]service.EffectiveLimits, 0, len(candidates))
+		for _, id := range candidates {
+			if effective, ok := rls.GetEffectiveLimits(id); ok {
+				tenants = append(tenants, effective)
+			}
+		}
+		sort.Slice(tenants, func(i, j int) bool { return tenants[i].TenantID < tenants[j].TenantID })
+
+		writeJSON(w, http.StatusOK, map[string]any{
+			"tenants": tenants,
+			"total":   len(tenants),
+		})
+	}
+}
+
+func handleSelfTenant(rls *service.RLS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Interface("panic", r).Msg("panic in handleSelfTenant")
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+		}()
+
+		id, ok := selfServiceTenant(w, r)
+		if !ok {
+			return
+		}
+
+		timeRange := r.URL.Query().Get("range")
+		if timeRange == "" {
+			timeRange = "1h"
+		}
+
+		view, ok := rls.GetTenantSelfView(id, timeRange, 10)
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]any{
+				"error":     "tenant not found",
+				"tenant_id": id,
+			})
+			return
+		}
+		writeJSON(w, http.StatusOK, view)
+	}
+}
+
+func handleSelfTenantDenials(rls *service.RLS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Interface("panic", r).Msg("panic in handleSelfTenantDenials")
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+		}()
+
+		id, ok := selfServiceTenant(w, r)
+		if !ok {
+			return
+		}
+
+		since := time.Hour
+		if sinceParam := r.URL.Query().Get("since"); sinceParam != "" {
+			if d, err := time.ParseDuration(sinceParam); err == nil && d > 0 && d <= 24*time.Hour {
+				since = d
+			}
+		}
+		limit := 50
+		if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+			if parsed, err := strconv.Atoi(limitParam); err == nil && parsed > 0 && parsed <= 500 {
+				limit = parsed
+			}
+		}
+
+		denials := rls.EnhancedRecentDenials(id, since)
+		total := len(denials)
+		if len(denials) > limit {
+			denials = denials[:limit]
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{
+			"tenant_id": id,
+			"denials":   denials,
+			"total":     total,
+			"since":     since.String(),
+		})
+	}
+}
+
+func handleSelfTenantSeries(rls *service.RLS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Interface("panic", r).Msg("panic in handleSelfTenantSeries")
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+		}()
+
+		id, ok := selfServiceTenant(w, r)
+		if !ok {
+			return
+		}
+		if _, exists := rls.GetEffectiveLimits(id); !exists {
+			writeJSON(w, http.StatusNotFound, map[string]any{
+				"error":     "tenant not found",
+				"tenant_id": id,
+			})
+			return
+		}
+
+		limit := 20
+		if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+			if parsed, err := strconv.Atoi(limitParam); err == nil && parsed > 0 && parsed <= 1000 {
+				limit = parsed
+			}
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{
+			"tenant_id":  id,
+			"top_series": rls.GetTenantTopSeries(id, limit),
+		})
+	}
+}
+
+func handleSelfTenantCardinalityTop(rls *service.RLS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Interface("panic", r).Msg("panic in handleSelfTenantCardinalityTop")
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+		}()
+
+		id, ok := selfServiceTenant(w, r)
+		if !ok {
+			return
+		}
+		writeCardinalityTop(w, r, rls, id)
+	}
+}
+
+func handleTenantCardinalityTop(rls *service.RLS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Interface("panic", r).Msg("panic in handleTenantCardinalityTop")
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+		}()
+
+		writeCardinalityTop(w, r, rls, mux.Vars(r)["id"])
+	}
+}
+
+// writeCardinalityTop serves the top metrics and label names this replica has seen for a tenant.
+// Label value breakdowns are selected with label_names[] as in Mimir's cardinality API.
+func writeCardinalityTop(w http.ResponseWriter, r *http.Request, rls *service.RLS, id string) {
+	limit := 20
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		if parsed, err := strconv.Atoi(limitParam); err == nil && parsed > 0 && parsed <= 1000 {
+			limit = parsed
+		}
+	}
+	labelNames := append(r.URL.Query()["label_names[]"], r.URL.Query()["label_names"]...)
+
+	report, ok := rls.GetTenantCardinalityTop(id, limit, labelNames)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]any{
+			"error":     "no cardinality data for tenant (explorer disabled or no sampled requests yet)",
+			"tenant_id": id,
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func handleGetTenantForensics(rls *service.RLS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Interface("panic", r).Msg("panic in handleGetTenantForensics")
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+		}()
+
+		id := mux.Vars(r)["id"]
+		status, captures, err := rls.GetTenantForensics(id)
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error(), "tenant_id": id})
+			return
+		}
+		if captures == nil {
+			captures = []forensics.Capture{}
+		}
+		response := map[string]any{
+			"tenant_id": id,
+			"forensics": status,
+			"captures":  captures,
+		}
+
+		// 🔧 NEW: Captures stay on the replica that took them, so list those of every replica
+		if wantClusterScope(r) {
+			_, breakdown := fanoutViews(r, captures, func(body []byte) ([]forensics.Capture, error) {
+				var peer struct {
+					Captures []forensics.Capture `json:"captures"`
+				}
+				err := json.Unmarshal(body, &peer)
+				return peer.Captures, err
+			})
+			merged := []forensics.Capture{}
+			for _, b := range breakdown {
+				for _, capture := range b.View {
+					capture.Replica = b.Replica
+					merged = append(merged, capture)
+				}
+			}
+			sort.SliceStable(merged, func(i, j int) bool { return merged[i].Timestamp.After(merged[j].Timestamp) })
+			response["captures"] = merged
+			addClusterFields(r, response, breakdown)
+		}
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+func handleSetTenantForensics(rls *service.RLS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Interface("panic", r).Msg("panic in handleSetTenantForensics")
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+		}()
+
+		id := mux.Vars(r)["id"]
+
+		// Forensic mode stays on until switched off unless a duration (e.g. "2h") is given
+		var req struct {
+			Enabled  bool   `json:"enabled"`
+			Duration string `json:"duration"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error().Err(err).Str("tenant_id", id).Msg("failed to decode forensics JSON")
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+
+		var duration time.Duration
+		if req.Duration != "" {
+			parsed, err := time.ParseDuration(req.Duration)
+			if err != nil || parsed <= 0 {
+				http.Error(w, "invalid duration", http.StatusBadRequest)
+				return
+			}
+			duration = parsed
+		}
+
+		status, err := rls.SetTenantForensics(id, req.Enabled, duration, changeContextFromRequest(r))
+		if errors.Is(err, service.ErrForensicsDisabled) {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error(), "tenant_id": id})
+			return
+		}
+		if errors.Is(err, forensics.ErrInvalidTenantID) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			log.Error().Err(err).Str("tenant_id", id).Msg("failed to set forensic mode")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"success":   true,
+			"tenant_id": id,
+			"forensics": status,
+		})
+	}
+}
+
+func handlePurgeTenantForensics(rls *service.RLS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Interface("panic", r).Msg("panic in handlePurgeTenantForensics")
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+		}()
+
+		id := mux.Vars(r)["id"]
+		removed, err := rls.PurgeTenantForensics(id)
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error(), "tenant_id": id})
+			return
+		}
+		response := map[string]any{
+			"success":   true,
+			"tenant_id": id,
+			"removed":   removed,
+		}
+
+		// 🔧 NEW: Purge the captures every replica holds
+		if wantClusterScope(r) {
+			breakdown := []replicaView[int]{{Replica: clusterFanout.Self(), View: removed}}
+			responses, err := clusterFanout.Delete(r.Context(), r.URL.Path, r.URL.Query(), r.Header)
+			if err != nil {
+				breakdown = append(breakdown, replicaView[int]{Replica: "peer-discovery", Error: err.Error()})
+			}
+			for _, resp := range responses {
+				var peer struct {
+					Removed int `json:"removed"`
+				}
+				if resp.Err == nil {
+					resp.Err = json.Unmarshal(resp.Body, &peer)
+				}
+				if resp.Err != nil {
+					breakdown = append(breakdown, replicaView[int]{Replica: resp.Peer, Error: resp.Err.Error()})
+					continue
+				}
+				removed += peer.Removed
+				breakdown = append(breakdown, replicaView[int]{Replica: resp.Peer, View: peer.Removed})
+			}
+			response["removed"] = removed
+			addClusterFields(r, response, breakdown)
+		}
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+// handleDownloadTenantCapture serves a capture as a snappy-compressed remote-write request
+// (format=protobuf, the default) that can be replayed against Mimir, or as JSON label sets (format=json)
+func handleDownloadTenantCapture(rls *service.RLS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().Interface("panic", r).Msg("panic in handleDownloadTenantCapture")
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+		}()
+
+		id := mux.Vars(r)["id"]
+		captureID := mux.Vars(r)["capture"]
+
+		switch format := r.URL.Query().Get("format"); format {
+		case "", "protobuf":
+			data, capture, err := rls.ReadTenantCapture(id, captureID)
+			if errors.Is(err, os.ErrNotExist) {
+				// 🔧 NEW: The capture may have been taken by another replica
+				if data, ok := captureFromPeers(r); ok {
+					writeCaptureProtobuf(w, id+"-"+captureID+".pb", data)
+					return
+				}
+			}
+			if err != nil {
+				writeCaptureError(w, id, captureID, err)
+				return
+			}
+			writeCaptureProtobuf(w, capture.TenantID+"-"+capture.ID+".pb", data)
+		case "json":
+			series, capture, err := rls.ReadTenantCaptureJSON(id, captureID)
+			if errors.Is(err, os.ErrNotExist) {
+				if body, ok := captureFromPeers(r); ok {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusOK)
+					w.Write(body)
+					return
+				}
+			}
+			if err != nil {
+				writeCaptureError(w, id, captureID, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{
+				"capture":    capture,
+				"timeseries": series,
+			})
+		default:
+			http.Error(w, "format must be protobuf or json", http.StatusBadRequest)
+		}
+	}
+}
+
+// captureFromPeers requests a capture this replica does not hold from the other replicas and returns the first answer
+func captureFromPeers(r *http.Request) ([]byte, bool) {
+	if !wantClusterScope(r) {
+		return nil, false
+	}
+	responses, err := clusterFanout.Get(r.Context(), r.URL.Path, r.URL.Query(), r.Header)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to discover peers for capture download")
+		return nil, false
+	}
+	for _, resp := range responses {
+		if resp.Err == nil {
+			return resp.Body, true
+		}
+	}
+	return nil, false
+}
+
+// writeCaptureProtobuf serves a capture as a replayable snappy-compressed remote-write request
+func writeCaptureProtobuf(w http.ResponseWriter, filename string, data []byte) {
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Header().Set("Content-Encoding", "snappy")
+	w.Header().Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// writeCaptureError maps capture read failures to HTTP responses
+func writeCaptureError(w http.ResponseWriter, id, captureID string, err error) {
+	if errors.Is(err, service.ErrForensicsDisabled) || errors.Is(err, os.ErrNotExist) || errors.Is(err, forensics.ErrInvalidTenantID) {
+		writeJSON(w, http.StatusNotFound, map[string]any{
+			"error":      "capture not found",
+			"tenant_id":  id,
+			"capture_id": captureID,
+		})
+		return
+	}
+	log.Error().Err(err).Str("tenant_id", id).Str("capture_id", captureID).Msg("failed to read forensic capture")
+	http.Error(w, "failed to read capture", http.StatusInternalServerError)
+}
+
+// wantClusterScope reports whether a request asks for the view merged across all replicas
+func wantClusterScope(r *http.Request) bool {
+	if clusterFanout == nil {
+		return false
+	}
+	switch r.URL.Query().Get(cluster.ScopeParam) {
+	case cluster.ScopeCluster:
+		return true
+	case cluster.ScopeLocal:
+		return false
+	default:
+		return *clusterFanoutDefault
+	}
+}
+
+// replicaView is one replica's contribution to a cluster-wide response
+type replicaView[T any] struct {
+	Replica string `json:"replica"`
+	View    T      `json:"view"`
+	Error   string `json:"error,omitempty"`
+}
+
+// fanoutViews requests the same endpoint from every peer and decodes each answer with extract.
+// It returns the views that could be collected, this replica's first, and a per-replica breakdown.
+func fanoutViews[T any](r *http.Request, local T, extract func(body []byte) (T, error)) ([]T, []replicaView[T]) {
+	views := []T{local}
+	breakdown := []replicaView[T]{{Replica: clusterFanout.Self(), View: local}}
+
+	responses, err := clusterFanout.Get(r.Context(), r.URL.Path, r.URL.Query(), r.Header)
+	if err != nil {
+		breakdown = append(breakdown, replicaView[T]{Replica: "peer-discovery", Error: err.Error()})
+		return views, breakdown
+	}
+
+	for _, resp := range responses {
+		if resp.Err != nil {
+			breakdown = append(breakdown, replicaView[T]{Replica: resp.Peer, Error: resp.Err.Error()})
+			continue
+		}
+		view, err := extract(resp.Body)
+		if err != nil {
+			breakdown = append(breakdown, replicaView[T]{Replica: resp.Peer, Error: err.Error()})
+			continue
+		}
+		views = append(views, view)
+		breakdown = append(breakdown, replicaView[T]{Replica: resp.Peer, View: view})
+	}
+	return views, breakdown
+}
+
+// addClusterFields annotates a merged response with how many replicas contributed and, on request, the breakdown
+func addClusterFields[T any](r *http.Request, response map[string]any, breakdown []replicaView[T]) {
+	failed := 0
+	for _, b := range breakdown {
+		if b.Error != "" {
+			failed++
+		}
+	}
+	response["scope"] = cluster.ScopeCluster
+	response["replicas"] = len(breakdown) - failed
+	response["failed_replicas"] = failed
+	if r.URL.Query().Get("breakdown") == "true" {
+		response["breakdown"] = breakdown
+	}
+}
+
+func handleListDenials(rls *service.RLS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tenant := r.URL.Query().Get("tenant")
+		sinceParam := r.URL.Query().Get("since")
+		if sinceParam == "" {
+			sinceParam = "1h"
+		}
+		d, _ := time.ParseDuration(sinceParam)
+		denials := rls.RecentDenials(tenant, d)
+
+		// 🔧 NEW: Merge the denials recorded by every replica
+		if wantClusterScope(r) {
+			views, breakdown := fanoutViews(r, denials, func(body []byte) ([]limits.DenialInfo, error) {
+				var peer struct {
+					Denials []limits.DenialInfo `json:"denials"`
+				}
+				err := json.Unmarshal(body, &peer)
+				return peer.Denials, err
+			})
+			response := map[string]any{"denials": cluster.MergeDenials(views)}
+			addClusterFields(r, response, breakdown)
+			writeJSON(w, http.StatusOK, response)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{"denials": denials})
+	}
+}
+
+// handleEnhancedDenials returns enriched denial information with context and insights
+func handleEnhancedDenials(rls *service.RLS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tenant := r.URL.Query().Get("tenant")
+		sinceParam := r.URL.Query().Get("since")
+		if sinceParam == "" {
+			sinceParam = "1h"
+		}
+		d, _ := time.ParseDuration(sinceParam)
+
+		// Get limit parameter for server-side pagination (default to 25 for performance)
+		limitParam := r.URL.Query().Get("limit")
+		limit := 25 // Aggressive limit for performance
+		if limitParam != "" {
+			if parsedLimit, err := strconv.Atoi(limitParam); err == nil && parsedLimit > 0 && parsedLimit <= 100 {
+				limit = parsedLimit
+			}
+		}
+
+		// Start with a shorter time range for better performance
+		adjustedSince := d
+		if d > 15*time.Minute {
+			adjustedSince = 15 * time.Minute // Limit to 15 minutes for performance
+		}
+
+		// Get basic denials first
+		basicDenials := rls.RecentDenials(tenant, adjustedSince)
+		totalCount := len(basicDenials)
+
+		// Aggressively limit denials for performance
+		if len(basicDenials) > limit {
+			basicDenials = basicDenials[:limit]
+		}
+
+		// Convert to enhanced format but skip heavy processing if too many
+		var denials []map[string]any
+		for i, denial := range basicDenials {
+			if i >= limit { // Double check limit
+				break
+			}
+
+			// Simplified enhanced denial without heavy processing
 			enhanced := map[string]any{
 				"tenant_id":           denial.TenantID,
 				"reason":              denial.Reason,
@@ -1135,6 +2753,26 @@ func handleCardinalityData(rls *service.RLS) http.HandlerFunc {
 		// Get cardinality data
 		cardinalityData := rls.GetCardinalityData(timeRange, tenant)
 
+		// 🔧 NEW: Merge the cardinality snapshots of every replica
+		if wantClusterScope(r) {
+			views, breakdown := fanoutViews(r, cardinalityData, func(body []byte) (limits.CardinalityData, error) {
+				var peer limits.CardinalityData
+				err := json.Unmarshal(body, &peer)
+				return peer, err
+			})
+			merged := cluster.MergeCardinality(views)
+			response := map[string]any{
+				"metrics":    merged.Metrics,
+				"violations": merged.Violations,
+				"trends":     merged.Trends,
+				"tenants":    merged.Tenants,
+				"alerts":     merged.Alerts,
+			}
+			addClusterFields(r, response, breakdown)
+			writeJSON(w, http.StatusOK, response)
+			return
+		}
+
 		writeJSON(w, http.StatusOK, cardinalityData)
 	}
 }
@@ -1199,9 +2837,32 @@ func handleCardinalityAlerts(rls *service.RLS) http.HandlerFunc {
 		// Get cardinality alerts
 		alerts := rls.GetCardinalityAlerts()
 
-		writeJSON(w, http.StatusOK, map[string]interface{}{
-			"alerts": alerts,
-		})
+		// 🔧 NEW: Merge the alerts of every replica
+		response := map[string]interface{}{}
+		if wantClusterScope(r) {
+			views, breakdown := fanoutViews(r, alerts, func(body []byte) ([]limits.CardinalityAlert, error) {
+				var peer struct {
+					Alerts []limits.CardinalityAlert `json:"alerts"`
+				}
+				err := json.Unmarshal(body, &peer)
+				return peer.Alerts, err
+			})
+			alerts = cluster.MergeAlerts(views)
+			addClusterFields(r, response, breakdown)
+		}
+
+		// 🔧 NEW: Alertmanager webhook format for external receivers
+		if r.URL.Query().Get("format") == "alertmanager" {
+			receiver := r.URL.Query().Get("receiver")
+			if receiver == "" {
+				receiver = "mimir-edge-enforcement"
+			}
+			writeJSON(w, http.StatusOK, service.NewAlertmanagerWebhook(alerts, receiver, *alertmanagerExternalURL))
+			return
+		}
+
+		response["alerts"] = alerts
+		writeJSON(w, http.StatusOK, response)
 	}
 }
 
@@ -1307,12 +2968,17 @@ func handleRemoteWrite(rls *service.RLS) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
+		// 🔧 NEW: Continue the client's trace and pass it on to Mimir
+		ctx, span := tracing.Start(tracing.Extract(r.Context(), propagation.HeaderCarrier(r.Header)), "rls.RemoteWrite", trace.SpanKindServer)
+		defer span.End()
+
 		// Extract tenant ID from header
 		tenantID := r.Header.Get("X-Scope-OrgID")
 		if tenantID == "" {
 			http.Error(w, "missing X-Scope-OrgID header", http.StatusBadRequest)
 			return
 		}
+		span.SetAttributes(attribute.String("tenant.id", tenantID))
 
 		// Read request body
 		body, err := io.ReadAll(r.Body)
@@ -1323,7 +2989,8 @@ func handleRemoteWrite(rls *service.RLS) http.HandlerFunc {
 		defer r.Body.Close()
 
 		// Check limits using RLS logic with selective filtering
-		decision, filteredBody := rls.CheckRemoteWriteLimitsWithFiltering(tenantID, body, r.Header.Get("Content-Encoding"))
+		decision, filteredBody := rls.CheckRemoteWriteLimitsWithFiltering(ctx, tenantID, body, r.Header.Get("Content-Encoding"))
+		span.SetAttributes(attribute.Bool("rls.allowed", decision.Allowed), attribute.String("rls.reason", decision.Reason))
 
 		if !decision.Allowed {
 			// Return appropriate error based on decision
@@ -1355,13 +3022,20 @@ func handleRemoteWrite(rls *service.RLS) http.HandlerFunc {
 		}
 
 		// Forward to Mimir
+		forwardCtx, forwardSpan := tracing.Start(ctx, "mimir.Push", trace.SpanKindClient,
+			attribute.String("http.url", mimirURL), attribute.Int64("http.request_content_length", int64(len(bodyToSend))))
+		tracing.Inject(forwardCtx, mimirReq.Header)
 		client := &http.Client{Timeout: 30 * time.Second}
 		resp, err := client.Do(mimirReq)
 		if err != nil {
+			tracing.RecordError(forwardSpan, err)
+			forwardSpan.End()
 			http.Error(w, "failed to forward request to Mimir", http.StatusInternalServerError)
 			return
 		}
 		defer resp.Body.Close()
+		forwardSpan.SetAttributes(attribute.Int64("http.status_code", int64(resp.StatusCode)))
+		forwardSpan.End()
 
 		// Copy response
 		w.WriteHeader(resp.StatusCode)
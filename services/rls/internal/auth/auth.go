@@ -0,0 +1,311 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Role grants access to a class of admin endpoints. Higher roles include lower ones.
+type Role int
+
+const (
+	RoleNone     Role = iota
+	RoleTenant        // self-service endpoints of the caller's own tenants
+	RoleViewer        // read endpoints
+	RoleOperator      // limit and enforcement mutations
+	RoleAdmin         // debug endpoints
+)
+
+// ParseRole converts a role name into a Role
+func ParseRole(name string) (Role, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "tenant":
+		return RoleTenant, nil
+	case "viewer":
+		return RoleViewer, nil
+	case "operator":
+		return RoleOperator, nil
+	case "admin":
+		return RoleAdmin, nil
+	default:
+		return RoleNone, fmt.Errorf("unknown role: %s", name)
+	}
+}
+
+func (r Role) String() string {
+	switch r {
+	case RoleTenant:
+		return "tenant"
+	case RoleViewer:
+		return "viewer"
+	case RoleOperator:
+		return "operator"
+	case RoleAdmin:
+		return "admin"
+	default:
+		return "none"
+	}
+}
+
+// ErrNoCredentials is returned by an Authenticator when the request carries no credentials it understands
+var ErrNoCredentials = errors.New("no credentials")
+
+// Identity is an authenticated caller of the admin API
+type Identity struct {
+	Subject string   `json:"subject"`
+	Role    Role     `json:"-"`
+	Tenants []string `json:"tenants,omitempty"` // tenants the caller is scoped to, if any
+	Method  string   `json:"method"`            // token, oidc or mtls
+}
+
+// CanAccessTenant reports whether the identity may read the given tenant's data.
+// Viewers and above see every tenant; tenant-role callers only see their own.
+func (i *Identity) CanAccessTenant(tenantID string) bool {
+	if i.Role >= RoleViewer {
+		return true
+	}
+	return i.Role == RoleTenant && contains(i.Tenants, tenantID)
+}
+
+// Credentials are the raw credentials presented by a caller
+type Credentials struct {
+	BearerToken      string
+	PeerCertificates []*x509.Certificate // verified client certificate chain
+}
+
+// Authenticator turns credentials into an identity
+type Authenticator interface {
+	Authenticate(creds Credentials) (*Identity, error)
+}
+
+// Chain tries each authenticator in order until one recognises the credentials
+type Chain []Authenticator
+
+// Authenticate implements Authenticator
+func (c Chain) Authenticate(creds Credentials) (*Identity, error) {
+	for _, authenticator := range c {
+		identity, err := authenticator.Authenticate(creds)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return identity, err
+	}
+	return nil, ErrNoCredentials
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a context carrying the identity
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the identity stored in the context, if any
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok && identity != nil
+}
+
+// StaticToken describes a bearer token accepted by StaticTokenAuthenticator
+type StaticToken struct {
+	Token   string   `json:"token"`
+	Subject string   `json:"subject"`
+	Role    string   `json:"role"`
+	Tenants []string `json:"tenants,omitempty"`
+}
+
+// StaticTokenAuthenticator accepts a fixed set of bearer tokens
+type StaticTokenAuthenticator struct {
+	tokens []staticToken
+}
+
+type staticToken struct {
+	token    []byte
+	identity Identity
+}
+
+// LoadStaticTokens reads tokens from a JSON file of the form {"tokens": [{"token": "...", "subject": "...", "role": "operator"}]}
+func LoadStaticTokens(path string) (*StaticTokenAuthenticator, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tokens file: %w", err)
+	}
+
+	var file struct {
+		Tokens []StaticToken `json:"tokens"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse tokens file: %w", err)
+	}
+	return NewStaticTokenAuthenticator(file.Tokens)
+}
+
+// NewStaticTokenAuthenticator creates an authenticator for the given tokens
+func NewStaticTokenAuthenticator(tokens []StaticToken) (*StaticTokenAuthenticator, error) {
+	a := &StaticTokenAuthenticator{}
+	for i, t := range tokens {
+		if t.Token == "" {
+			return nil, fmt.Errorf("token %d has no value", i)
+		}
+		role, err := ParseRole(t.Role)
+		if err != nil {
+			return nil, fmt.Errorf("token %d (%s): %w", i, t.Subject, err)
+		}
+		a.tokens = append(a.tokens, staticToken{
+			token:    []byte(t.Token),
+			identity: Identity{Subject: t.Subject, Role: role, Tenants: t.Tenants, Method: "token"},
+		})
+	}
+	return a, nil
+}
+
+// Authenticate implements Authenticator
+func (a *StaticTokenAuthenticator) Authenticate(creds Credentials) (*Identity, error) {
+	if creds.BearerToken == "" || looksLikeJWT(creds.BearerToken) {
+		return nil, ErrNoCredentials
+	}
+	presented := []byte(creds.BearerToken)
+	for _, t := range a.tokens {
+		if subtle.ConstantTimeCompare(presented, t.token) == 1 {
+			identity := t.identity
+			return &identity, nil
+		}
+	}
+	return nil, errors.New("invalid bearer token")
+}
+
+// MTLSAuthenticator maps verified client certificates to roles by common name
+type MTLSAuthenticator struct {
+	roles map[string]Role
+}
+
+// NewMTLSAuthenticator parses a role map of the form "cn1=operator,cn2=admin"
+func NewMTLSAuthenticator(roleMap string) (*MTLSAuthenticator, error) {
+	a := &MTLSAuthenticator{roles: make(map[string]Role)}
+	for _, entry := range strings.Split(roleMap, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid mTLS role mapping: %s", entry)
+		}
+		role, err := ParseRole(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		a.roles[strings.TrimSpace(parts[0])] = role
+	}
+	return a, nil
+}
+
+// Authenticate implements Authenticator
+func (a *MTLSAuthenticator) Authenticate(creds Credentials) (*Identity, error) {
+	if len(creds.PeerCertificates) == 0 {
+		return nil, ErrNoCredentials
+	}
+	cn := creds.PeerCertificates[0].Subject.CommonName
+	role, ok := a.roles[cn]
+	if !ok {
+		// Let token-based authenticators decide if the client also sent one
+		return nil, ErrNoCredentials
+	}
+	return &Identity{Subject: cn, Role: role, Method: "mtls"}, nil
+}
+
+// HTTPCredentials extracts credentials from an HTTP request
+func HTTPCredentials(r *http.Request) Credentials {
+	creds := Credentials{BearerToken: bearerToken(r.Header.Get("Authorization"))}
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		creds.PeerCertificates = r.TLS.VerifiedChains[0]
+	}
+	return creds
+}
+
+// GRPCCredentials extracts credentials from an incoming gRPC context
+func GRPCCredentials(ctx context.Context) Credentials {
+	var creds Credentials
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			creds.BearerToken = bearerToken(values[0])
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.VerifiedChains) > 0 {
+			creds.PeerCertificates = tlsInfo.State.VerifiedChains[0]
+		}
+	}
+	return creds
+}
+
+// Middleware authenticates HTTP requests and enforces the role returned by requiredRole.
+// Requests whose required role is RoleNone are passed through unauthenticated.
+func Middleware(authenticator Authenticator, requiredRole func(r *http.Request) Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			required := requiredRole(r)
+			if required == RoleNone {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			identity, err := authenticator.Authenticate(HTTPCredentials(r))
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="rls-admin"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			if identity.Role < required {
+				http.Error(w, fmt.Sprintf("forbidden: %s role required", required), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), identity)))
+		})
+	}
+}
+
+// UnaryServerInterceptor authenticates gRPC calls and enforces the role returned by requiredRole
+func UnaryServerInterceptor(authenticator Authenticator, requiredRole func(fullMethod string) Role) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		required := requiredRole(info.FullMethod)
+		if required == RoleNone {
+			return handler(ctx, req)
+		}
+
+		identity, err := authenticator.Authenticate(GRPCCredentials(ctx))
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+		}
+		if identity.Role < required {
+			return nil, status.Errorf(codes.PermissionDenied, "%s role required", required)
+		}
+		return handler(WithIdentity(ctx, identity), req)
+	}
+}
+
+func bearerToken(header string) string {
+	const prefix = "bearer "
+	if len(header) > len(prefix) && strings.EqualFold(header[:len(prefix)], prefix) {
+		return strings.TrimSpace(header[len(prefix):])
+	}
+	return ""
+}
+
+func looksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2 && strings.HasPrefix(token, "ey")
+}
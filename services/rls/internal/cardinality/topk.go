@@ -0,0 +1,103 @@
+package cardinality
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// topKEntry is a key monitored by a topK, with the payload tracked alongside it
+type topKEntry[P any] struct {
+	key     string
+	count   int64
+	err     int64 // overestimation inherited from the evicted entry
+	payload *P
+	index   int
+}
+
+// topK is a Space-Saving summary: it monitors at most k keys, and a key that is not monitored
+// replaces the one with the smallest count, inheriting that count as its error.
+// Every key with a true count above total/k is guaranteed to be monitored.
+type topK[P any] struct {
+	k       int
+	entries map[string]*topKEntry[P]
+	heap    topKHeap[P]
+	reset   func(*P) // clears a payload taken over by a new key
+}
+
+func newTopK[P any](k int, reset func(*P)) *topK[P] {
+	return &topK[P]{k: k, entries: make(map[string]*topKEntry[P], k), reset: reset}
+}
+
+// add counts key weight times and returns its payload
+func (t *topK[P]) add(key string, weight int64) *P {
+	if e, ok := t.entries[key]; ok {
+		e.count += weight
+		heap.Fix(&t.heap, e.index)
+		return e.payload
+	}
+
+	if len(t.entries) < t.k {
+		e := &topKEntry[P]{key: key, count: weight, payload: new(P)}
+		t.entries[key] = e
+		heap.Push(&t.heap, e)
+		return e.payload
+	}
+
+	// Replace the entry with the smallest count
+	e := t.heap[0]
+	delete(t.entries, e.key)
+	e.key = key
+	e.err = e.count
+	e.count += weight
+	if t.reset != nil {
+		t.reset(e.payload)
+	}
+	t.entries[key] = e
+	heap.Fix(&t.heap, 0)
+	return e.payload
+}
+
+// get returns the entry of a monitored key
+func (t *topK[P]) get(key string) (*topKEntry[P], bool) {
+	e, ok := t.entries[key]
+	return e, ok
+}
+
+// sorted returns the monitored entries, largest count first
+func (t *topK[P]) sorted() []*topKEntry[P] {
+	out := make([]*topKEntry[P], 0, len(t.entries))
+	for _, e := range t.entries {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].count != out[j].count {
+			return out[i].count > out[j].count
+		}
+		return out[i].key < out[j].key
+	})
+	return out
+}
+
+// topKHeap is a min-heap of entries by count
+type topKHeap[P any] []*topKEntry[P]
+
+func (h topKHeap[P]) Len() int           { return len(h) }
+func (h topKHeap[P]) Less(i, j int) bool { return h[i].count < h[j].count }
+func (h topKHeap[P]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *topKHeap[P]) Push(x any) {
+	e := x.(*topKEntry[P])
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *topKHeap[P]) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
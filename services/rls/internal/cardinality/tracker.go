@@ -0,0 +1,442 @@
+package cardinality
+
+import (
+	"hash/fnv"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/parser"
+)
+
+// Config bounds the memory used by the cardinality explorer
+type Config struct {
+	TopMetrics     int           // metrics monitored per tenant
+	TopLabelNames  int           // label names monitored per tenant
+	TopLabelValues int           // values monitored per label name
+	Window         time.Duration // series are counted over the current and the previous window
+	MaxTenants     int           // tenants tracked; the least recently seen tenant is dropped beyond this
+	QueueSize      int           // requests waiting to be tracked before new ones are dropped
+	SampleRate     float64       // fraction of requests tracked (0 disables tracking)
+}
+
+type metricStats struct {
+	series hyperLogLog
+}
+
+type labelStats struct {
+	values    hyperLogLog
+	series    hyperLogLog
+	topValues *topK[struct{}]
+}
+
+// generation holds the summaries of one window
+type generation struct {
+	start    time.Time
+	requests int64
+	series   hyperLogLog
+	metrics  *topK[metricStats]
+	labels   *topK[labelStats]
+}
+
+func newGeneration(config Config, start time.Time) *generation {
+	return &generation{
+		start:   start,
+		metrics: newTopK(config.TopMetrics, func(m *metricStats) { *m = metricStats{} }),
+		labels:  newTopK(config.TopLabelNames, func(l *labelStats) { *l = labelStats{} }),
+	}
+}
+
+// tracker is the explorer state of a single tenant
+type tracker struct {
+	mu       sync.Mutex
+	current  *generation
+	previous *generation
+	lastSeen time.Time
+}
+
+func (t *tracker) observe(config Config, series []parser.SeriesLabels, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if age := now.Sub(t.current.start); age >= config.Window {
+		t.previous = t.current
+		if age >= 2*config.Window {
+			// Nothing was seen during the last full window
+			t.previous = nil
+		}
+		t.current = newGeneration(config, now)
+	}
+	t.lastSeen = now
+
+	g := t.current
+	g.requests++
+	for _, s := range series {
+		seriesHash := hashSeries(s)
+		g.series.add(seriesHash)
+		g.metrics.add(s.MetricName, 1).series.add(seriesHash)
+
+		for _, label := range s.Labels {
+			if label.Name == "__name__" {
+				continue
+			}
+			l := g.labels.add(label.Name, 1)
+			l.values.add(hashString(label.Value))
+			l.series.add(seriesHash)
+			if l.topValues == nil {
+				l.topValues = newTopK[struct{}](config.TopLabelValues, nil)
+			}
+			l.topValues.add(label.Value, 1)
+		}
+	}
+}
+
+func hashSeries(s parser.SeriesLabels) uint64 {
+	h := fnv.New64a()
+	for _, label := range s.Labels {
+		h.Write([]byte(label.Name))
+		h.Write([]byte{0xff})
+		h.Write([]byte(label.Value))
+		h.Write([]byte{0xff})
+	}
+	return h.Sum64()
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// Explorer tracks the top metrics and label names of every tenant off the request path
+type Explorer struct {
+	config   Config
+	mu       sync.RWMutex
+	tenants  map[string]*tracker
+	queue    chan observation
+	every    uint64
+	sequence atomic.Uint64
+	dropped  prometheus.Counter
+}
+
+type observation struct {
+	tenantID string
+	series   []parser.SeriesLabels
+	at       time.Time
+}
+
+// NewExplorer creates an explorer. It returns nil when tracking is disabled.
+func NewExplorer(config Config, dropped prometheus.Counter) *Explorer {
+	if config.SampleRate <= 0 || config.TopMetrics <= 0 {
+		return nil
+	}
+	if config.SampleRate > 1 {
+		config.SampleRate = 1
+	}
+	if config.TopLabelNames <= 0 {
+		config.TopLabelNames = config.TopMetrics
+	}
+	if config.TopLabelValues <= 0 {
+		config.TopLabelValues = 10
+	}
+	if config.Window <= 0 {
+		config.Window = time.Hour
+	}
+	if config.MaxTenants <= 0 {
+		config.MaxTenants = 1000
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 256
+	}
+	return &Explorer{
+		config:  config,
+		tenants: make(map[string]*tracker),
+		queue:   make(chan observation, config.QueueSize),
+		every:   uint64(1/config.SampleRate + 0.5),
+		dropped: dropped,
+	}
+}
+
+// Observe queues the series of a request without blocking; only one in every 1/SampleRate requests is kept.
+// It is safe to call on a nil explorer.
+func (e *Explorer) Observe(tenantID string, series []parser.SeriesLabels) {
+	if e == nil || len(series) == 0 {
+		return
+	}
+	if e.every > 1 && e.sequence.Add(1)%e.every != 0 {
+		return
+	}
+	select {
+	case e.queue <- observation{tenantID: tenantID, series: series, at: time.Now()}:
+	default:
+		if e.dropped != nil {
+			e.dropped.Inc()
+		}
+	}
+}
+
+// Run tracks queued requests until the process exits
+func (e *Explorer) Run() {
+	for o := range e.queue {
+		e.trackerFor(o.tenantID, o.at).observe(e.config, o.series, o.at)
+	}
+}
+
+func (e *Explorer) trackerFor(tenantID string, now time.Time) *tracker {
+	e.mu.RLock()
+	t, ok := e.tenants[tenantID]
+	e.mu.RUnlock()
+	if ok {
+		return t
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if t, ok := e.tenants[tenantID]; ok {
+		return t
+	}
+	if len(e.tenants) >= e.config.MaxTenants {
+		e.evictOldest()
+	}
+	t = &tracker{current: newGeneration(e.config, now), lastSeen: now}
+	e.tenants[tenantID] = t
+	return t
+}
+
+// evictOldest drops the least recently seen tenant; callers hold e.mu
+func (e *Explorer) evictOldest() {
+	var oldestID string
+	var oldest time.Time
+	for id, t := range e.tenants {
+		t.mu.Lock()
+		seen := t.lastSeen
+		t.mu.Unlock()
+		if oldestID == "" || seen.Before(oldest) {
+			oldestID, oldest = id, seen
+		}
+	}
+	delete(e.tenants, oldestID)
+}
+
+// MetricCardinality is the estimated series count of one metric
+type MetricCardinality struct {
+	Metric               string `json:"metric"`
+	SeriesCount          int64  `json:"series_count"`
+	CurrentWindowSeries  int64  `json:"current_window_series"`
+	PreviousWindowSeries int64  `json:"previous_window_series"`
+	Observations         int64  `json:"observations"`
+}
+
+// LabelNameCardinality is the number of distinct values of one label name, as in Mimir's label_names API
+type LabelNameCardinality struct {
+	LabelName        string `json:"label_name"`
+	LabelValuesCount int64  `json:"label_values_count"`
+	SeriesCount      int64  `json:"series_count"`
+}
+
+// LabelValueCardinality is how often one value of a label was seen
+type LabelValueCardinality struct {
+	LabelValue   string `json:"label_value"`
+	Observations int64  `json:"observations"`
+}
+
+// LabelValuesBreakdown is the top values of one label name, as in Mimir's label_values API
+type LabelValuesBreakdown struct {
+	LabelName        string                  `json:"label_name"`
+	LabelValuesCount int64                   `json:"label_values_count"`
+	SeriesCount      int64                   `json:"series_count"`
+	Cardinality      []LabelValueCardinality `json:"cardinality"`
+}
+
+// Report is the cardinality explorer view of one tenant.
+// Series and value counts are HyperLogLog estimates; observations count series occurrences in tracked requests.
+type Report struct {
+	TenantID         string                 `json:"tenant_id"`
+	WindowStart      time.Time              `json:"window_start"`
+	TrackedRequests  int64                  `json:"tracked_requests"`
+	SampleRate       float64                `json:"sample_rate"`
+	SeriesCountTotal int64                  `json:"series_count_total"`
+	Metrics          []MetricCardinality    `json:"metrics"`
+	LabelNamesCount  int                    `json:"label_names_count"`
+	LabelValuesTotal int64                  `json:"label_values_count_total"`
+	LabelNames       []LabelNameCardinality `json:"label_names"`
+	LabelValues      []LabelValuesBreakdown `json:"label_values"`
+}
+
+// Report returns the top metrics and label names of a tenant over the current and previous window.
+// Label value breakdowns are returned for labelNames, or for the top three label names when none are given.
+func (e *Explorer) Report(tenantID string, limit int, labelNames []string) (Report, bool) {
+	if e == nil {
+		return Report{}, false
+	}
+	e.mu.RLock()
+	t, ok := e.tenants[tenantID]
+	e.mu.RUnlock()
+	if !ok {
+		return Report{}, false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	generations := []*generation{t.current}
+	if t.previous != nil {
+		generations = append(generations, t.previous)
+	}
+
+	report := Report{
+		TenantID:    tenantID,
+		WindowStart: generations[len(generations)-1].start,
+		SampleRate:  e.config.SampleRate,
+	}
+
+	var total hyperLogLog
+	for _, g := range generations {
+		report.TrackedRequests += g.requests
+		total.merge(&g.series)
+	}
+	report.SeriesCountTotal = total.estimate()
+
+	report.Metrics = mergeMetrics(t.current, t.previous)
+	names := mergeLabelNames(generations)
+	report.LabelNamesCount = len(names)
+	for _, n := range names {
+		report.LabelValuesTotal += n.LabelValuesCount
+	}
+
+	if len(labelNames) == 0 {
+		for i := 0; i < len(names) && i < 3; i++ {
+			labelNames = append(labelNames, names[i].LabelName)
+		}
+	}
+	report.LabelValues = make([]LabelValuesBreakdown, 0, len(labelNames))
+	for _, name := range labelNames {
+		report.LabelValues = append(report.LabelValues, labelValues(generations, name, limit))
+	}
+
+	if limit > 0 && len(report.Metrics) > limit {
+		report.Metrics = report.Metrics[:limit]
+	}
+	if limit > 0 && len(names) > limit {
+		names = names[:limit]
+	}
+	report.LabelNames = names
+	return report, true
+}
+
+func mergeMetrics(current, previous *generation) []MetricCardinality {
+	type merged struct {
+		series       hyperLogLog
+		current      int64
+		previous     int64
+		observations int64
+	}
+	byMetric := make(map[string]*merged)
+	for _, g := range []*generation{current, previous} {
+		if g == nil {
+			continue
+		}
+		for _, e := range g.metrics.sorted() {
+			m, ok := byMetric[e.key]
+			if !ok {
+				m = &merged{}
+				byMetric[e.key] = m
+			}
+			m.series.merge(&e.payload.series)
+			m.observations += e.count
+			if g == current {
+				m.current = e.payload.series.estimate()
+			} else {
+				m.previous = e.payload.series.estimate()
+			}
+		}
+	}
+
+	out := make([]MetricCardinality, 0, len(byMetric))
+	for metric, m := range byMetric {
+		out = append(out, MetricCardinality{
+			Metric:               metric,
+			SeriesCount:          m.series.estimate(),
+			CurrentWindowSeries:  m.current,
+			PreviousWindowSeries: m.previous,
+			Observations:         m.observations,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].SeriesCount != out[j].SeriesCount {
+			return out[i].SeriesCount > out[j].SeriesCount
+		}
+		return out[i].Metric < out[j].Metric
+	})
+	return out
+}
+
+func mergeLabelNames(generations []*generation) []LabelNameCardinality {
+	values := make(map[string]*hyperLogLog)
+	series := make(map[string]*hyperLogLog)
+	for _, g := range generations {
+		for _, e := range g.labels.sorted() {
+			if values[e.key] == nil {
+				values[e.key] = &hyperLogLog{}
+				series[e.key] = &hyperLogLog{}
+			}
+			values[e.key].merge(&e.payload.values)
+			series[e.key].merge(&e.payload.series)
+		}
+	}
+
+	out := make([]LabelNameCardinality, 0, len(values))
+	for name, v := range values {
+		out = append(out, LabelNameCardinality{
+			LabelName:        name,
+			LabelValuesCount: v.estimate(),
+			SeriesCount:      series[name].estimate(),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].LabelValuesCount != out[j].LabelValuesCount {
+			return out[i].LabelValuesCount > out[j].LabelValuesCount
+		}
+		return out[i].LabelName < out[j].LabelName
+	})
+	return out
+}
+
+func labelValues(generations []*generation, name string, limit int) LabelValuesBreakdown {
+	breakdown := LabelValuesBreakdown{LabelName: name, Cardinality: []LabelValueCardinality{}}
+	var values, series hyperLogLog
+	observations := make(map[string]int64)
+	for _, g := range generations {
+		e, ok := g.labels.get(name)
+		if !ok {
+			continue
+		}
+		values.merge(&e.payload.values)
+		series.merge(&e.payload.series)
+		if e.payload.topValues == nil {
+			continue
+		}
+		for _, v := range e.payload.topValues.sorted() {
+			observations[v.key] += v.count
+		}
+	}
+	breakdown.LabelValuesCount = values.estimate()
+	breakdown.SeriesCount = series.estimate()
+
+	for value, count := range observations {
+		breakdown.Cardinality = append(breakdown.Cardinality, LabelValueCardinality{LabelValue: value, Observations: count})
+	}
+	sort.Slice(breakdown.Cardinality, func(i, j int) bool {
+		if breakdown.Cardinality[i].Observations != breakdown.Cardinality[j].Observations {
+			return breakdown.Cardinality[i].Observations > breakdown.Cardinality[j].Observations
+		}
+		return breakdown.Cardinality[i].LabelValue < breakdown.Cardinality[j].LabelValue
+	})
+	if limit > 0 && len(breakdown.Cardinality) > limit {
+		breakdown.Cardinality = breakdown.Cardinality[:limit]
+	}
+	return breakdown
+}
@@ -0,0 +1,243 @@
+package cluster
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ScopeParam is the query parameter selecting between a replica's own view ("local") and the merged view ("cluster")
+const ScopeParam = "scope"
+
+// Scope values
+const (
+	ScopeLocal   = "local"
+	ScopeCluster = "cluster"
+)
+
+// Config describes how peers are discovered and queried
+type Config struct {
+	Self        string        // this replica's name, reported in per-replica breakdowns
+	StaticPeers []string      // host:port of every replica; this replica is skipped if listed
+	DNSName     string        // headless service resolving to every replica's pod IP
+	Port        string        // admin HTTP port of this replica and of the peers found via DNSName
+	Scheme      string        // http or https
+	TLSConfig   *tls.Config   // client TLS configuration for https peers
+	Timeout     time.Duration // per-peer request timeout
+	// ClientCertificate is presented to peers on requests without an Authorization header to forward,
+	// e.g. of callers authenticated by their own client certificate, which cannot be forwarded
+	ClientCertificate *tls.Certificate
+}
+
+// PeerResponse is the raw answer of one peer
+type PeerResponse struct {
+	Peer string
+	Body []byte
+	Err  error
+}
+
+// Fanout queries every other RLS replica's admin API
+type Fanout struct {
+	config     Config
+	client     *http.Client
+	certClient *http.Client // presents ClientCertificate; nil without one
+	logger     zerolog.Logger
+}
+
+// NewFanout creates a fan-out client. It returns nil if no peers are configured.
+func NewFanout(config Config, logger zerolog.Logger) *Fanout {
+	if len(config.StaticPeers) == 0 && config.DNSName == "" {
+		return nil
+	}
+	if config.Scheme == "" {
+		config.Scheme = "http"
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 2 * time.Second
+	}
+	fanout := &Fanout{
+		config: config,
+		client: newPeerClient(config.TLSConfig, config.Timeout),
+		logger: logger,
+	}
+	if config.ClientCertificate != nil {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if config.TLSConfig != nil {
+			tlsConfig = config.TLSConfig.Clone()
+		}
+		tlsConfig.Certificates = []tls.Certificate{*config.ClientCertificate}
+		fanout.certClient = newPeerClient(tlsConfig, config.Timeout)
+	}
+	return fanout
+}
+
+// newPeerClient creates the HTTP client of peer requests. Connections with and without a client
+// certificate use separate clients, so a bearer token is never sent over a connection carrying the
+// replica's own certificate, which peers would authenticate first.
+func newPeerClient(tlsConfig *tls.Config, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig, MaxIdleConnsPerHost: 4},
+	}
+}
+
+// Self returns the name of this replica
+func (f *Fanout) Self() string {
+	return f.config.Self
+}
+
+// Peers returns the address of every other replica
+func (f *Fanout) Peers(ctx context.Context) ([]string, error) {
+	local := localAddresses()
+
+	var candidates []string
+	if f.config.DNSName != "" {
+		ips, err := net.DefaultResolver.LookupHost(ctx, f.config.DNSName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve peers from %s: %w", f.config.DNSName, err)
+		}
+		for _, ip := range ips {
+			candidates = append(candidates, net.JoinHostPort(ip, f.config.Port))
+		}
+	}
+	candidates = append(candidates, f.config.StaticPeers...)
+
+	seen := make(map[string]bool, len(candidates))
+	peers := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		host, port, err := net.SplitHostPort(candidate)
+		if err != nil || seen[candidate] || f.isSelf(ctx, host, port, local) {
+			continue
+		}
+		seen[candidate] = true
+		peers = append(peers, candidate)
+	}
+	sort.Strings(peers)
+	return peers, nil
+}
+
+// Get requests path with the given query from every peer concurrently, forcing the local scope so peers do not fan out again.
+// The caller's Authorization header is forwarded; without one the replica's client certificate, if any, authenticates the request.
+func (f *Fanout) Get(ctx context.Context, path string, query url.Values, header http.Header) ([]PeerResponse, error) {
+	return f.fanout(ctx, http.MethodGet, path, query, header)
+}
+
+// 🔧 NEW: Delete sends a DELETE of path to every peer, like Get, for state every replica keeps on its own
+func (f *Fanout) Delete(ctx context.Context, path string, query url.Values, header http.Header) ([]PeerResponse, error) {
+	return f.fanout(ctx, http.MethodDelete, path, query, header)
+}
+
+func (f *Fanout) fanout(ctx context.Context, method, path string, query url.Values, header http.Header) ([]PeerResponse, error) {
+	peers, err := f.Peers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q := url.Values{}
+	for key, values := range query {
+		q[key] = values
+	}
+	q.Set(ScopeParam, ScopeLocal)
+	q.Del("breakdown")
+
+	responses := make([]PeerResponse, len(peers))
+	var wg sync.WaitGroup
+	for i, peer := range peers {
+		wg.Add(1)
+		go func(i int, peer string) {
+			defer wg.Done()
+			body, err := f.request(ctx, method, peer, path, q, header)
+			responses[i] = PeerResponse{Peer: peer, Body: body, Err: err}
+			if err != nil {
+				f.logger.Warn().Err(err).Str("peer", peer).Str("path", path).Msg("cluster: peer request failed")
+			}
+		}(i, peer)
+	}
+	wg.Wait()
+	return responses, nil
+}
+
+func (f *Fanout) request(ctx context.Context, method, peer, path string, query url.Values, header http.Header) ([]byte, error) {
+	target := url.URL{Scheme: f.config.Scheme, Host: peer, Path: path, RawQuery: query.Encode()}
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	client := f.client
+	if value := header.Get("Authorization"); value != "" {
+		req.Header.Set("Authorization", value)
+	} else if f.certClient != nil {
+		client = f.certClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 32<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("peer returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// isSelf reports whether a peer address is this replica: one of its IPs or its hostname, a DNS name whose first
+// label is its replica ID (e.g. rls-0.rls-headless.ns.svc for pod rls-0), or a name resolving to one of its IPs
+func (f *Fanout) isSelf(ctx context.Context, host, port string, local map[string]bool) bool {
+	if port != f.config.Port {
+		return false
+	}
+	if local[host] {
+		return true
+	}
+	if net.ParseIP(host) != nil {
+		return false
+	}
+	if label, _, _ := strings.Cut(host, "."); f.config.Self != "" && label == f.config.Self {
+		return true
+	}
+	ips, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return false
+	}
+	for _, ip := range ips {
+		if local[ip] {
+			return true
+		}
+	}
+	return false
+}
+
+// localAddresses returns the IPs and hostname of this replica
+func localAddresses() map[string]bool {
+	local := map[string]bool{"localhost": true}
+	if hostname, err := os.Hostname(); err == nil {
+		local[hostname] = true
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return local
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok {
+			local[ipNet.IP.String()] = true
+		}
+	}
+	return local
+}
@@ -0,0 +1,204 @@
+package cluster
+
+import (
+	"sort"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/limits"
+)
+
+// MergeOverview sums the overview counters of every replica.
+// Active tenants are shared through the store, so the largest count is used rather than the sum.
+func MergeOverview(views []limits.OverviewStats) limits.OverviewStats {
+	var merged limits.OverviewStats
+	for _, v := range views {
+		merged.TotalRequests += v.TotalRequests
+		merged.AllowedRequests += v.AllowedRequests
+		merged.DeniedRequests += v.DeniedRequests
+		if v.ActiveTenants > merged.ActiveTenants {
+			merged.ActiveTenants = v.ActiveTenants
+		}
+	}
+	if merged.TotalRequests > 0 {
+		merged.AllowPercentage = float64(merged.AllowedRequests) / float64(merged.TotalRequests) * 100.0
+	}
+	return merged
+}
+
+// MergeTenants merges the tenant lists of every replica by tenant ID.
+// Throughput adds up; allow and deny rates are averaged weighted by each replica's request rate.
+func MergeTenants(views [][]limits.TenantInfo) []limits.TenantInfo {
+	byID := make(map[string]*limits.TenantInfo)
+	weights := make(map[string]float64)
+	order := make([]string, 0)
+
+	for _, tenants := range views {
+		for _, t := range tenants {
+			existing, ok := byID[t.ID]
+			if !ok {
+				copied := t
+				byID[t.ID] = &copied
+				weights[t.ID] = t.Metrics.RPS
+				order = append(order, t.ID)
+				continue
+			}
+
+			m := &existing.Metrics
+			total := weights[t.ID] + t.Metrics.RPS
+			if total > 0 {
+				m.AllowRate = (m.AllowRate*weights[t.ID] + t.Metrics.AllowRate*t.Metrics.RPS) / total
+				m.DenyRate = (m.DenyRate*weights[t.ID] + t.Metrics.DenyRate*t.Metrics.RPS) / total
+				m.AvgResponseTime = (m.AvgResponseTime*weights[t.ID] + t.Metrics.AvgResponseTime*t.Metrics.RPS) / total
+			}
+			weights[t.ID] = total
+			m.RPS += t.Metrics.RPS
+			m.BytesPerSec += t.Metrics.BytesPerSec
+			m.SamplesPerSec += t.Metrics.SamplesPerSec
+			if t.Metrics.UtilizationPct > m.UtilizationPct {
+				m.UtilizationPct = t.Metrics.UtilizationPct
+			}
+		}
+	}
+
+	out := make([]limits.TenantInfo, 0, len(order))
+	for _, id := range order {
+		out = append(out, *byID[id])
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// MergeDenials combines the denial lists of every replica, newest first
+func MergeDenials(views [][]limits.DenialInfo) []limits.DenialInfo {
+	out := make([]limits.DenialInfo, 0)
+	for _, denials := range views {
+		out = append(out, denials...)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Timestamp.After(out[j].Timestamp) })
+	return out
+}
+
+// MergeCardinality combines the cardinality dashboards of every replica. Averages are weighted by the requests behind
+// them, so a replica that saw a handful of requests does not count as much as one that saw millions.
+func MergeCardinality(views []limits.CardinalityData) limits.CardinalityData {
+	var merged limits.CardinalityData
+	if len(views) == 0 {
+		return merged
+	}
+
+	tenants := make(map[string]*limits.TenantCardinality)
+	trends := make(map[time.Time]*limits.CardinalityTrend)
+	alerts := make([][]limits.CardinalityAlert, 0, len(views))
+
+	for _, v := range views {
+		m := &merged.Metrics
+		m.TotalSeries += v.Metrics.TotalSeries
+		m.TotalLabels += v.Metrics.TotalLabels
+		m.CardinalityViolations += v.Metrics.CardinalityViolations
+		m.AvgSeriesPerRequest += v.Metrics.AvgSeriesPerRequest * float64(v.Metrics.SampledRequests)
+		m.AvgLabelsPerSeries += v.Metrics.AvgLabelsPerSeries * float64(v.Metrics.SampledRequests)
+		m.ViolationRate += v.Metrics.ViolationRate * float64(v.Metrics.TotalRequests)
+		m.SampledRequests += v.Metrics.SampledRequests
+		m.TotalRequests += v.Metrics.TotalRequests
+		if v.Metrics.MaxSeriesInRequest > m.MaxSeriesInRequest {
+			m.MaxSeriesInRequest = v.Metrics.MaxSeriesInRequest
+		}
+		if v.Metrics.MaxLabelsInSeries > m.MaxLabelsInSeries {
+			m.MaxLabelsInSeries = v.Metrics.MaxLabelsInSeries
+		}
+
+		merged.Violations = append(merged.Violations, v.Violations...)
+
+		for _, t := range v.Tenants {
+			existing, ok := tenants[t.TenantID]
+			if !ok {
+				copied := t
+				tenants[t.TenantID] = &copied
+				continue
+			}
+			existing.CurrentSeries += t.CurrentSeries
+			existing.CurrentLabels += t.CurrentLabels
+			existing.ViolationCount += t.ViolationCount
+			if t.LastViolation.After(existing.LastViolation) {
+				existing.LastViolation = t.LastViolation
+			}
+		}
+
+		for _, t := range v.Trends {
+			existing, ok := trends[t.Timestamp]
+			if !ok {
+				copied := t
+				trends[t.Timestamp] = &copied
+				continue
+			}
+			total := existing.TotalRequests + t.TotalRequests
+			if total > 0 {
+				existing.AvgSeriesPerRequest = (existing.AvgSeriesPerRequest*float64(existing.TotalRequests) + t.AvgSeriesPerRequest*float64(t.TotalRequests)) / float64(total)
+				existing.AvgLabelsPerSeries = (existing.AvgLabelsPerSeries*float64(existing.TotalRequests) + t.AvgLabelsPerSeries*float64(t.TotalRequests)) / float64(total)
+			}
+			existing.TotalRequests = total
+			existing.ViolationCount += t.ViolationCount
+		}
+
+		alerts = append(alerts, v.Alerts)
+	}
+	if m := &merged.Metrics; m.SampledRequests > 0 {
+		m.AvgSeriesPerRequest /= float64(m.SampledRequests)
+		m.AvgLabelsPerSeries /= float64(m.SampledRequests)
+	}
+	if m := &merged.Metrics; m.TotalRequests > 0 {
+		m.ViolationRate /= float64(m.TotalRequests)
+	}
+	merged.Alerts = MergeAlerts(alerts)
+
+	sort.SliceStable(merged.Violations, func(i, j int) bool { return merged.Violations[i].Timestamp.After(merged.Violations[j].Timestamp) })
+
+	merged.Tenants = make([]limits.TenantCardinality, 0, len(tenants))
+	for _, t := range tenants {
+		merged.Tenants = append(merged.Tenants, *t)
+	}
+	sort.Slice(merged.Tenants, func(i, j int) bool { return merged.Tenants[i].TenantID < merged.Tenants[j].TenantID })
+
+	merged.Trends = make([]limits.CardinalityTrend, 0, len(trends))
+	for _, t := range trends {
+		merged.Trends = append(merged.Trends, *t)
+	}
+	sort.Slice(merged.Trends, func(i, j int) bool { return merged.Trends[i].Timestamp.Before(merged.Trends[j].Timestamp) })
+
+	return merged
+}
+
+// MergeAlerts de-duplicates the alerts of every replica by ID.
+// Replicas evaluate the same rules, so an alert still firing anywhere is reported as firing, with its earliest start.
+func MergeAlerts(views [][]limits.CardinalityAlert) []limits.CardinalityAlert {
+	byID := make(map[string]*limits.CardinalityAlert)
+	order := make([]string, 0)
+	for _, alerts := range views {
+		for _, a := range alerts {
+			existing, ok := byID[a.ID]
+			if !ok {
+				copied := a
+				byID[a.ID] = &copied
+				order = append(order, a.ID)
+				continue
+			}
+			if existing.Resolved && !a.Resolved {
+				startsAt := existing.StartsAt
+				*existing = a
+				if startsAt.Before(a.StartsAt) {
+					existing.StartsAt = startsAt
+				}
+				continue
+			}
+			if a.Resolved == existing.Resolved && a.StartsAt.Before(existing.StartsAt) {
+				existing.StartsAt = a.StartsAt
+			}
+		}
+	}
+
+	out := make([]limits.CardinalityAlert, 0, len(order))
+	for _, id := range order {
+		out = append(out, *byID[id])
+	}
+	return out
+}
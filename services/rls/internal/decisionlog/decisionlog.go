@@ -0,0 +1,259 @@
+package decisionlog
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+)
+
+// Sink kinds
+const (
+	SinkStdout = "stdout" // JSON lines on standard output
+	SinkFile   = "file"   // JSON lines in a size-rotated file
+	SinkOTLP   = "otlp"   // OTLP/HTTP logs exporter (protobuf encoding)
+)
+
+// Decision filters
+const (
+	FilterAll   = "all"
+	FilterAllow = "allow"
+	FilterDeny  = "deny"
+)
+
+// Record is one enforcement decision
+type Record struct {
+	Timestamp time.Time          `json:"timestamp"`
+	Replica   string             `json:"replica,omitempty"`
+	TenantID  string             `json:"tenant_id"`
+	Decision  string             `json:"decision"` // allow or deny
+	Reason    string             `json:"reason"`
+	Limits    map[string]float64 `json:"limits,omitempty"` // limits enforced for the tenant when the decision was made
+	Observed  Observed           `json:"observed"`
+	LatencyMs float64            `json:"latency_ms"`
+}
+
+// Observed holds the values measured on the request
+type Observed struct {
+	Samples   int64 `json:"samples"`
+	Series    int64 `json:"series"`
+	Labels    int64 `json:"labels"`
+	BodyBytes int64 `json:"body_bytes"`
+}
+
+// Config selects the sink and which decisions are logged
+type Config struct {
+	Sink              string             // stdout, file or otlp; empty disables the decision log
+	Decisions         string             // all, allow or deny
+	SampleRate        float64            // fraction of decisions logged for tenants without their own rate
+	TenantSampleRates map[string]float64 // per-tenant fractions overriding SampleRate
+	QueueSize         int                // records buffered before new ones are dropped
+	BatchSize         int                // records written together
+	FlushInterval     time.Duration      // how often a partial batch is written
+
+	FilePath       string
+	FileMaxBytes   int64 // the file is rotated once it would grow beyond this
+	FileMaxBackups int   // rotated files kept as <path>.1 ... <path>.N
+
+	OTLPEndpoint string            // full URL, e.g. http://otel-collector:4318/v1/logs
+	OTLPHeaders  map[string]string // sent with every export, e.g. authentication
+	OTLPTimeout  time.Duration
+	ServiceName  string // OTLP service.name resource attribute
+}
+
+// Metrics counts records written, dropped and lost to sink errors
+type Metrics struct {
+	Records *prometheus.CounterVec // labels: result (written, dropped, failed)
+}
+
+// ParseTenantSampleRates parses a comma-separated list of tenant=rate entries
+func ParseTenantSampleRates(spec string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		tenant, value, ok := strings.Cut(entry, "=")
+		if !ok || tenant == "" {
+			return nil, fmt.Errorf("invalid tenant sample rate %q: expected tenant=rate", entry)
+		}
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("invalid sample rate %q for tenant %s: expected a number between 0 and 1", value, tenant)
+		}
+		rates[tenant] = rate
+	}
+	return rates, nil
+}
+
+// sink writes batches of records
+type sink interface {
+	write(ctx context.Context, records []Record) error
+	close() error
+}
+
+// Logger filters and samples decisions and writes them to a sink in the background
+type Logger struct {
+	config  Config
+	sink    sink
+	queue   chan Record
+	metrics Metrics
+	logger  zerolog.Logger
+}
+
+// NewLogger creates a decision logger. It returns nil when no sink is configured.
+func NewLogger(config Config, metrics Metrics, logger zerolog.Logger) (*Logger, error) {
+	if config.Sink == "" {
+		return nil, nil
+	}
+	switch config.Decisions {
+	case "":
+		config.Decisions = FilterAll
+	case FilterAll, FilterAllow, FilterDeny:
+	default:
+		return nil, fmt.Errorf("invalid decision filter %q: expected all, allow or deny", config.Decisions)
+	}
+	if config.SampleRate < 0 || config.SampleRate > 1 {
+		return nil, fmt.Errorf("invalid sample rate %v: expected a number between 0 and 1", config.SampleRate)
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 10000
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 500
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+
+	var s sink
+	var err error
+	switch config.Sink {
+	case SinkStdout:
+		s = newStdoutSink()
+	case SinkFile:
+		s, err = newFileSink(config.FilePath, config.FileMaxBytes, config.FileMaxBackups)
+	case SinkOTLP:
+		s, err = newOTLPSink(config)
+	default:
+		err = fmt.Errorf("invalid decision log sink %q: expected stdout, file or otlp", config.Sink)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Logger{
+		config:  config,
+		sink:    s,
+		queue:   make(chan Record, config.QueueSize),
+		metrics: metrics,
+		logger:  logger,
+	}, nil
+}
+
+// Sampled reports whether a decision passes the filter and the tenant's sample rate.
+// It is safe to call on a nil logger, which samples nothing.
+func (l *Logger) Sampled(tenantID string, allowed bool) bool {
+	if l == nil {
+		return false
+	}
+	switch l.config.Decisions {
+	case FilterAllow:
+		if !allowed {
+			return false
+		}
+	case FilterDeny:
+		if allowed {
+			return false
+		}
+	}
+	rate, ok := l.config.TenantSampleRates[tenantID]
+	if !ok {
+		rate = l.config.SampleRate
+	}
+	switch {
+	case rate >= 1:
+		return true
+	case rate <= 0:
+		return false
+	default:
+		return rand.Float64() < rate
+	}
+}
+
+// Log queues a record without blocking; records are dropped when the queue is full.
+// Callers check Sampled first. It is safe to call on a nil logger.
+func (l *Logger) Log(record Record) {
+	if l == nil {
+		return
+	}
+	select {
+	case l.queue <- record:
+	default:
+		l.count("dropped", 1)
+	}
+}
+
+// Run writes queued records in batches until ctx is cancelled, then flushes what is left and closes the sink
+func (l *Logger) Run(ctx context.Context) {
+	ticker := time.NewTicker(l.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Record, 0, l.config.BatchSize)
+	flush := func(ctx context.Context) {
+		if len(batch) == 0 {
+			return
+		}
+		if err := l.sink.write(ctx, batch); err != nil {
+			l.count("failed", len(batch))
+			l.logger.Error().Err(err).Str("sink", l.config.Sink).Int("records", len(batch)).Msg("decisionlog: failed to write records")
+		} else {
+			l.count("written", len(batch))
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+		drain:
+			for {
+				select {
+				case record := <-l.queue:
+					batch = append(batch, record)
+					if len(batch) >= l.config.BatchSize {
+						flush(context.Background())
+					}
+				default:
+					break drain
+				}
+			}
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			flush(shutdownCtx)
+			cancel()
+			if err := l.sink.close(); err != nil {
+				l.logger.Error().Err(err).Str("sink", l.config.Sink).Msg("decisionlog: failed to close sink")
+			}
+			return
+		case record := <-l.queue:
+			batch = append(batch, record)
+			if len(batch) >= l.config.BatchSize {
+				flush(ctx)
+			}
+		case <-ticker.C:
+			flush(ctx)
+		}
+	}
+}
+
+func (l *Logger) count(result string, n int) {
+	if l.metrics.Records != nil {
+		l.metrics.Records.WithLabelValues(result).Add(float64(n))
+	}
+}
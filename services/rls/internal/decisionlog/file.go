@@ -0,0 +1,134 @@
+package decisionlog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// encodeLines appends one JSON document per record to buf
+func encodeLines(buf []byte, records []Record) ([]byte, error) {
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			return buf, err
+		}
+		buf = append(buf, line...)
+		buf = append(buf, '\n')
+	}
+	return buf, nil
+}
+
+// stdoutSink writes JSON lines to standard output
+type stdoutSink struct {
+	out *bufio.Writer
+}
+
+func newStdoutSink() *stdoutSink {
+	return &stdoutSink{out: bufio.NewWriter(os.Stdout)}
+}
+
+func (s *stdoutSink) write(_ context.Context, records []Record) error {
+	buf, err := encodeLines(nil, records)
+	if err != nil {
+		return err
+	}
+	if _, err := s.out.Write(buf); err != nil {
+		return err
+	}
+	return s.out.Flush()
+}
+
+func (s *stdoutSink) close() error {
+	return s.out.Flush()
+}
+
+// fileSink writes JSON lines to a file and rotates it by size, keeping maxBackups old files
+type fileSink struct {
+	path       string
+	maxBytes   int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func newFileSink(path string, maxBytes int64, maxBackups int) (*fileSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("decision log file path is required for the file sink")
+	}
+	if maxBytes <= 0 {
+		maxBytes = 100 << 20
+	}
+	if maxBackups < 0 {
+		maxBackups = 0
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create decision log directory: %w", err)
+	}
+	s := &fileSink{path: path, maxBytes: maxBytes, maxBackups: maxBackups}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// open appends to the current file, picking up its size
+func (s *fileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to open decision log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat decision log file: %w", err)
+	}
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+func (s *fileSink) write(_ context.Context, records []Record) error {
+	buf, err := encodeLines(nil, records)
+	if err != nil {
+		return err
+	}
+	if s.size > 0 && s.size+int64(len(buf)) > s.maxBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.file.Write(buf)
+	s.size += int64(n)
+	return err
+}
+
+// rotate shifts <path>.N-1 to <path>.N, ..., <path> to <path>.1 and starts a new file
+func (s *fileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("failed to close decision log file: %w", err)
+	}
+	if s.maxBackups == 0 {
+		if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return s.open()
+	}
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		from := fmt.Sprintf("%s.%d", s.path, i)
+		if err := os.Rename(from, fmt.Sprintf("%s.%d", s.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(s.path, s.path+".1"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return s.open()
+}
+
+func (s *fileSink) close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,138 @@
+package decisionlog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// otlpSink exports records as OTLP log records over HTTP using the protobuf encoding
+type otlpSink struct {
+	endpoint    string
+	headers     map[string]string
+	serviceName string
+	client      *http.Client
+}
+
+func newOTLPSink(config Config) (*otlpSink, error) {
+	if config.OTLPEndpoint == "" {
+		return nil, fmt.Errorf("decision log OTLP endpoint is required for the otlp sink")
+	}
+	timeout := config.OTLPTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	serviceName := config.ServiceName
+	if serviceName == "" {
+		serviceName = "mimir-rls"
+	}
+	return &otlpSink{
+		endpoint:    config.OTLPEndpoint,
+		headers:     config.OTLPHeaders,
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func stringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+func intAttribute(key string, value int64) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: value}}}
+}
+
+func doubleAttribute(key string, value float64) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: value}}}
+}
+
+// toOTLP converts a record; the body is the decision and the fields become attributes
+func toOTLP(record Record, observed time.Time) *logspb.LogRecord {
+	severity, severityText := logspb.SeverityNumber_SEVERITY_NUMBER_INFO, "INFO"
+	if record.Decision == "deny" {
+		severity, severityText = logspb.SeverityNumber_SEVERITY_NUMBER_WARN, "WARN"
+	}
+
+	attributes := []*commonpb.KeyValue{
+		stringAttribute("tenant.id", record.TenantID),
+		stringAttribute("decision", record.Decision),
+		stringAttribute("reason", record.Reason),
+		intAttribute("observed.samples", record.Observed.Samples),
+		intAttribute("observed.series", record.Observed.Series),
+		intAttribute("observed.labels", record.Observed.Labels),
+		intAttribute("observed.body_bytes", record.Observed.BodyBytes),
+		doubleAttribute("latency_ms", record.LatencyMs),
+	}
+	if record.Replica != "" {
+		attributes = append(attributes, stringAttribute("replica", record.Replica))
+	}
+	names := make([]string, 0, len(record.Limits))
+	for name := range record.Limits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		attributes = append(attributes, doubleAttribute("limit."+name, record.Limits[name]))
+	}
+
+	return &logspb.LogRecord{
+		TimeUnixNano:         uint64(record.Timestamp.UnixNano()),
+		ObservedTimeUnixNano: uint64(observed.UnixNano()),
+		SeverityNumber:       severity,
+		SeverityText:         severityText,
+		Body:                 &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: record.Decision + ": " + record.Reason}},
+		Attributes:           attributes,
+	}
+}
+
+func (s *otlpSink) write(ctx context.Context, records []Record) error {
+	now := time.Now()
+	logRecords := make([]*logspb.LogRecord, 0, len(records))
+	for _, record := range records {
+		logRecords = append(logRecords, toOTLP(record, now))
+	}
+	body, err := proto.Marshal(&collogspb.ExportLogsServiceRequest{ResourceLogs: []*logspb.ResourceLogs{{
+		Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{stringAttribute("service.name", s.serviceName)}},
+		ScopeLogs: []*logspb.ScopeLogs{{
+			Scope:      &commonpb.InstrumentationScope{Name: "mimir-edge-enforcement/decisions"},
+			LogRecords: logRecords,
+		}},
+	}}})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	for name, value := range s.headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("OTLP endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (s *otlpSink) close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,497 @@
+package forensics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/rs/zerolog"
+	"google.golang.org/protobuf/proto"
+
+	prompb "github.com/AkshayDubey29/mimir-edge-enforcement/protos/prometheus"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/parser"
+)
+
+// Capture sources
+const (
+	SourceCheck           = "check"            // request denied by ext_authz
+	SourceSelectiveFilter = "selective_filter" // series dropped by selective filtering
+)
+
+// enabledFile persists which tenants have forensic capture switched on. With a ModeStore it caches the last set
+// read from it, so a replica starting while the shared store is unreachable keeps capturing.
+const enabledFile = "enabled.json"
+
+// ErrInvalidTenantID is returned for tenant IDs that cannot name a capture directory
+var ErrInvalidTenantID = errors.New("tenant ID cannot be empty, \".\" or \"..\"")
+
+// Config bounds the on-disk capture queue
+type Config struct {
+	Dir                  string
+	MaxBytes             int64 // total size of all captures; the oldest captures are removed beyond it
+	MaxCapturesPerTenant int
+	MaxSeriesPerCapture  int           // series kept per capture; the rest are counted but not stored
+	QueueSize            int           // captures waiting to be written before new ones are dropped
+	ModeRefreshInterval  time.Duration // how often the enabled tenants are re-read from the ModeStore
+}
+
+// ModeStore shares which tenants have forensic capture switched on between replicas; until is zero while a
+// tenant is enabled indefinitely
+type ModeStore interface {
+	SetForensicsMode(ctx context.Context, tenantID string, until time.Time) error
+	DeleteForensicsMode(ctx context.Context, tenantID string) error
+	ListForensicsModes(ctx context.Context) (map[string]time.Time, error)
+}
+
+// Capture describes one stored set of offending series
+type Capture struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	Source    string    `json:"source"`
+	Reason    string    `json:"reason"`
+	Timestamp time.Time `json:"timestamp"`
+	Series    int       `json:"series"`            // series stored
+	Offending int       `json:"offending"`         // series offending, including those beyond MaxSeriesPerCapture
+	Truncated bool      `json:"truncated"`         // true when Offending > Series
+	SizeBytes int64     `json:"size_bytes"`        // snappy-compressed protobuf size on disk
+	Replica   string    `json:"replica,omitempty"` // replica holding the capture, set in cluster-wide listings
+}
+
+// TenantStatus is the forensic mode of a tenant
+type TenantStatus struct {
+	Enabled bool       `json:"enabled"`
+	Until   *time.Time `json:"until,omitempty"` // nil while enabled indefinitely
+}
+
+type pendingCapture struct {
+	capture Capture
+	series  []parser.SeriesLabels
+}
+
+// Store is a bounded on-disk queue of captured series, one snappy-compressed remote-write protobuf per capture.
+// Captures stay on the replica that took them; the enabled tenants come from the ModeStore when there is one.
+type Store struct {
+	config Config
+	modes  ModeStore
+	logger zerolog.Logger
+
+	mu         sync.RWMutex
+	enabled    map[string]time.Time // tenant -> enabled until; zero means indefinitely
+	captures   map[string][]Capture // tenant -> captures, oldest first
+	totalBytes int64
+
+	queue chan pendingCapture
+}
+
+// Open creates the capture directory if needed and loads the captures already on disk. modes may be nil, in which
+// case the enabled tenants are only kept in the capture directory of this replica.
+func Open(config Config, modes ModeStore, logger zerolog.Logger) (*Store, error) {
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = 256 << 20
+	}
+	if config.MaxCapturesPerTenant <= 0 {
+		config.MaxCapturesPerTenant = 100
+	}
+	if config.MaxSeriesPerCapture <= 0 {
+		config.MaxSeriesPerCapture = 10000
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 64
+	}
+	if config.ModeRefreshInterval <= 0 {
+		config.ModeRefreshInterval = 10 * time.Second
+	}
+	if err := os.MkdirAll(config.Dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create forensics directory: %w", err)
+	}
+
+	s := &Store{
+		config:   config,
+		modes:    modes,
+		logger:   logger,
+		enabled:  make(map[string]time.Time),
+		captures: make(map[string][]Capture),
+		queue:    make(chan pendingCapture, config.QueueSize),
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	if err := s.refreshModes(); err != nil {
+		logger.Warn().Err(err).Msg("forensics: failed to read enabled tenants from the shared store, using the local copy")
+	}
+	return s, nil
+}
+
+// load reads the enabled tenants and the metadata of every stored capture
+func (s *Store) load() error {
+	if data, err := os.ReadFile(filepath.Join(s.config.Dir, enabledFile)); err == nil {
+		if err := json.Unmarshal(data, &s.enabled); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", enabledFile, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	metas, err := filepath.Glob(filepath.Join(s.config.Dir, "*", "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, path := range metas {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var capture Capture
+		if err := json.Unmarshal(data, &capture); err != nil {
+			s.logger.Warn().Err(err).Str("path", path).Msg("forensics: skipping unreadable capture metadata")
+			continue
+		}
+		s.captures[capture.TenantID] = append(s.captures[capture.TenantID], capture)
+		s.totalBytes += capture.SizeBytes
+	}
+	for tenantID := range s.captures {
+		sort.Slice(s.captures[tenantID], func(i, j int) bool {
+			return s.captures[tenantID][i].Timestamp.Before(s.captures[tenantID][j].Timestamp)
+		})
+	}
+	return nil
+}
+
+// Enabled reports whether forensic capture is on for a tenant
+func (s *Store) Enabled(tenantID string) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.RLock()
+	until, ok := s.enabled[tenantID]
+	s.mu.RUnlock()
+	return ok && (until.IsZero() || time.Now().Before(until))
+}
+
+// Status returns the forensic mode of a tenant
+func (s *Store) Status(tenantID string) TenantStatus {
+	s.mu.RLock()
+	until, ok := s.enabled[tenantID]
+	s.mu.RUnlock()
+
+	status := TenantStatus{Enabled: ok && (until.IsZero() || time.Now().Before(until))}
+	if status.Enabled && !until.IsZero() {
+		status.Until = &until
+	}
+	return status
+}
+
+// SetEnabled switches forensic capture on (until a time, or indefinitely when until is zero) or off. Other
+// replicas pick the change up from the ModeStore within ModeRefreshInterval.
+func (s *Store) SetEnabled(tenantID string, enabled bool, until time.Time) error {
+	if enabled {
+		if err := checkTenantID(tenantID); err != nil {
+			return err
+		}
+	}
+	if s.modes != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		var err error
+		if enabled {
+			err = s.modes.SetForensicsMode(ctx, tenantID, until)
+		} else {
+			err = s.modes.DeleteForensicsMode(ctx, tenantID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if enabled {
+		s.enabled[tenantID] = until
+	} else {
+		delete(s.enabled, tenantID)
+	}
+	return s.saveEnabledLocked()
+}
+
+// refreshModes replaces the enabled tenants with those in the ModeStore
+func (s *Store) refreshModes() error {
+	if s.modes == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	modes, err := s.modes.ListForensicsModes(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.enabled = modes
+	return s.saveEnabledLocked()
+}
+
+func (s *Store) saveEnabledLocked() error {
+	data, err := json.Marshal(s.enabled)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(s.config.Dir, enabledFile), data)
+}
+
+// Submit queues the offending series of a request without blocking and reports whether they were accepted.
+// The series must not be modified afterwards.
+func (s *Store) Submit(tenantID, source, reason string, series []parser.SeriesLabels) bool {
+	if s == nil || len(series) == 0 || !s.Enabled(tenantID) {
+		return false
+	}
+	capture := Capture{
+		TenantID:  tenantID,
+		Source:    source,
+		Reason:    reason,
+		Timestamp: time.Now(),
+		Offending: len(series),
+	}
+	if len(series) > s.config.MaxSeriesPerCapture {
+		series = series[:s.config.MaxSeriesPerCapture]
+		capture.Truncated = true
+	}
+	capture.Series = len(series)
+
+	select {
+	case s.queue <- pendingCapture{capture: capture, series: series}:
+		return true
+	default:
+		s.logger.Warn().Str("tenant_id", tenantID).Msg("forensics: capture queue full, dropping capture")
+		return false
+	}
+}
+
+// Run writes queued captures and re-reads the enabled tenants from the ModeStore until the process exits
+func (s *Store) Run() {
+	if s.modes != nil {
+		go func() {
+			ticker := time.NewTicker(s.config.ModeRefreshInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				if err := s.refreshModes(); err != nil {
+					s.logger.Warn().Err(err).Msg("forensics: failed to refresh enabled tenants")
+				}
+			}
+		}()
+	}
+	for pending := range s.queue {
+		if err := s.write(pending); err != nil {
+			s.logger.Error().Err(err).Str("tenant_id", pending.capture.TenantID).Msg("forensics: failed to write capture")
+		}
+	}
+}
+
+func (s *Store) write(pending pendingCapture) error {
+	capture := pending.capture
+	capture.ID = strconv.FormatInt(capture.Timestamp.UnixNano(), 10)
+
+	data, err := proto.Marshal(ToWriteRequest(pending.series))
+	if err != nil {
+		return err
+	}
+	compressed := snappy.Encode(nil, data)
+	capture.SizeBytes = int64(len(compressed))
+
+	dir, err := s.tenantDir(capture.TenantID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(dir, capture.ID+".pb"), compressed); err != nil {
+		return err
+	}
+	meta, err := json.Marshal(capture)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(dir, capture.ID+".json"), meta); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.captures[capture.TenantID] = append(s.captures[capture.TenantID], capture)
+	s.totalBytes += capture.SizeBytes
+	s.enforceBoundsLocked(capture.TenantID)
+	s.mu.Unlock()
+	return nil
+}
+
+// enforceBoundsLocked removes the oldest captures of the tenant beyond its cap, then the oldest captures overall
+func (s *Store) enforceBoundsLocked(tenantID string) {
+	for len(s.captures[tenantID]) > s.config.MaxCapturesPerTenant {
+		s.removeLocked(tenantID, 0)
+	}
+	for s.totalBytes > s.config.MaxBytes {
+		oldestTenant := ""
+		var oldest time.Time
+		for id, captures := range s.captures {
+			if len(captures) > 0 && (oldestTenant == "" || captures[0].Timestamp.Before(oldest)) {
+				oldestTenant, oldest = id, captures[0].Timestamp
+			}
+		}
+		if oldestTenant == "" {
+			return
+		}
+		s.removeLocked(oldestTenant, 0)
+	}
+}
+
+func (s *Store) removeLocked(tenantID string, index int) {
+	captures := s.captures[tenantID]
+	capture := captures[index]
+	if dir, err := s.tenantDir(tenantID); err == nil {
+		os.Remove(filepath.Join(dir, capture.ID+".pb"))
+		os.Remove(filepath.Join(dir, capture.ID+".json"))
+	}
+	s.totalBytes -= capture.SizeBytes
+
+	captures = append(captures[:index], captures[index+1:]...)
+	if len(captures) == 0 {
+		delete(s.captures, tenantID)
+		return
+	}
+	s.captures[tenantID] = captures
+}
+
+// List returns the captures of a tenant, newest first
+func (s *Store) List(tenantID string) []Capture {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	captures := s.captures[tenantID]
+	out := make([]Capture, 0, len(captures))
+	for i := len(captures) - 1; i >= 0; i-- {
+		out = append(out, captures[i])
+	}
+	return out
+}
+
+// ReadRaw returns a capture as the snappy-compressed remote-write protobuf stored on disk
+func (s *Store) ReadRaw(tenantID, captureID string) ([]byte, Capture, error) {
+	s.mu.RLock()
+	var capture Capture
+	found := false
+	for _, c := range s.captures[tenantID] {
+		if c.ID == captureID {
+			capture, found = c, true
+			break
+		}
+	}
+	s.mu.RUnlock()
+	if !found {
+		return nil, Capture{}, os.ErrNotExist
+	}
+
+	dir, err := s.tenantDir(tenantID)
+	if err != nil {
+		return nil, Capture{}, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, capture.ID+".pb"))
+	return data, capture, err
+}
+
+// Read returns a capture decoded as a remote-write request
+func (s *Store) Read(tenantID, captureID string) (*prompb.WriteRequest, Capture, error) {
+	compressed, capture, err := s.ReadRaw(tenantID, captureID)
+	if err != nil {
+		return nil, capture, err
+	}
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return nil, capture, fmt.Errorf("failed to decompress capture: %w", err)
+	}
+	var request prompb.WriteRequest
+	if err := proto.Unmarshal(data, &request); err != nil {
+		return nil, capture, fmt.Errorf("failed to decode capture: %w", err)
+	}
+	return &request, capture, nil
+}
+
+// Purge removes every capture of a tenant and returns how many were removed
+func (s *Store) Purge(tenantID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := len(s.captures[tenantID])
+	for len(s.captures[tenantID]) > 0 {
+		s.removeLocked(tenantID, 0)
+	}
+	if dir, err := s.tenantDir(tenantID); err == nil {
+		os.Remove(dir)
+	}
+	return removed
+}
+
+// tenantDir returns the capture directory of a tenant. PathEscape escapes separators but keeps "." and "..", which
+// would name the capture directory itself or its parent.
+func (s *Store) tenantDir(tenantID string) (string, error) {
+	if err := checkTenantID(tenantID); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.config.Dir, url.PathEscape(tenantID)), nil
+}
+
+func checkTenantID(tenantID string) error {
+	switch tenantID {
+	case "", ".", "..":
+		return ErrInvalidTenantID
+	}
+	return nil
+}
+
+// ToWriteRequest builds a remote-write request holding the labels of the given series
+func ToWriteRequest(series []parser.SeriesLabels) *prompb.WriteRequest {
+	request := &prompb.WriteRequest{Timeseries: make([]*prompb.TimeSeries, 0, len(series))}
+	for _, s := range series {
+		request.Timeseries = append(request.Timeseries, &prompb.TimeSeries{Labels: s.Labels})
+	}
+	return request
+}
+
+// SeriesJSON is the JSON rendering of a captured series
+type SeriesJSON struct {
+	Labels map[string]string `json:"labels"`
+}
+
+// ToJSON renders a captured remote-write request as label sets
+func ToJSON(request *prompb.WriteRequest) []SeriesJSON {
+	out := make([]SeriesJSON, 0, len(request.Timeseries))
+	for _, ts := range request.Timeseries {
+		labels := make(map[string]string, len(ts.Labels))
+		for _, label := range ts.Labels {
+			labels[label.Name] = label.Value
+		}
+		out = append(out, SeriesJSON{Labels: labels})
+	}
+	return out
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o640); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
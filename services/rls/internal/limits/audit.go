@@ -0,0 +1,91 @@
+package limits
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// TenantConfigSnapshot captures the mutable configuration of a tenant at a point in time
+type TenantConfigSnapshot struct {
+	Limits            TenantLimits       `json:"limits"` // defaults, profile and tenant overrides merged
+	Enforcement       EnforcementConfig  `json:"enforcement"`
+	TemporaryOverride *TemporaryOverride `json:"temporary_override,omitempty"`
+	Profile           string             `json:"profile,omitempty"`
+	Overrides         *LimitOverrides    `json:"overrides,omitempty"` // fields explicitly set for the tenant
+	Policy            *EdgePolicy        `json:"policy,omitempty"`
+}
+
+// FieldChange describes a single field that changed between two tenant configurations
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// AuditEvent records a single mutation of a tenant's limits or enforcement configuration
+type AuditEvent struct {
+	TenantID  string                `json:"tenant_id"`
+	Revision  int64                 `json:"revision"`
+	Action    string                `json:"action"` // set_limits, set_enforcement, set_profile, set_policy, set_override, clear_override, override_expired, rollback
+	Actor     string                `json:"actor"`
+	Source    string                `json:"source"` // admin_api, grpc_admin, overrides_sync
+	Timestamp time.Time             `json:"timestamp"`
+	Before    *TenantConfigSnapshot `json:"before,omitempty"` // nil when the mutation created the tenant
+	After     TenantConfigSnapshot  `json:"after"`
+	Changes   []FieldChange         `json:"changes"`
+}
+
+// DiffTenantConfig returns the per-field differences between two tenant configurations.
+// A nil before snapshot is treated as an empty configuration.
+func DiffTenantConfig(before *TenantConfigSnapshot, after TenantConfigSnapshot) []FieldChange {
+	var prev TenantConfigSnapshot
+	if before != nil {
+		prev = *before
+	}
+
+	changes := diffFields("limits", reflect.ValueOf(prev.Limits), reflect.ValueOf(after.Limits))
+	changes = append(changes, diffFields("enforcement", reflect.ValueOf(prev.Enforcement), reflect.ValueOf(after.Enforcement))...)
+	if prev.Profile != after.Profile {
+		changes = append(changes, FieldChange{Field: "profile", Old: prev.Profile, New: after.Profile})
+	}
+	if !reflect.DeepEqual(prev.Policy, after.Policy) {
+		changes = append(changes, FieldChange{Field: "policy", Old: prev.Policy, New: after.Policy})
+	}
+	if !reflect.DeepEqual(prev.TemporaryOverride, after.TemporaryOverride) {
+		changes = append(changes, FieldChange{
+			Field: "temporary_override",
+			Old:   prev.TemporaryOverride,
+			New:   after.TemporaryOverride,
+		})
+	}
+	return changes
+}
+
+// diffFields compares two structs of the same type field by field, naming fields by their JSON tag
+func diffFields(prefix string, oldValue, newValue reflect.Value) []FieldChange {
+	changes := make([]FieldChange, 0)
+	t := oldValue.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := jsonFieldName(t.Field(i))
+		oldField := oldValue.Field(i).Interface()
+		newField := newValue.Field(i).Interface()
+		if !reflect.DeepEqual(oldField, newField) {
+			changes = append(changes, FieldChange{
+				Field: prefix + "." + name,
+				Old:   oldField,
+				New:   newField,
+			})
+		}
+	}
+	return changes
+}
+
+// jsonFieldName returns the JSON name of a struct field, falling back to its Go name
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,97 @@
+package limits
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiffTenantConfig(t *testing.T) {
+	base := TenantConfigSnapshot{
+		Limits:      TenantLimits{SamplesPerSecond: 1000, MaxBodyBytes: 1 << 20},
+		Enforcement: EnforcementConfig{Enabled: true},
+		Profile:     "silver",
+	}
+	override := &TemporaryOverride{SamplesPerSecond: float64Ptr(5000), ExpiresAt: time.Unix(1700000000, 0)}
+
+	tests := []struct {
+		name   string
+		before *TenantConfigSnapshot
+		after  func() TenantConfigSnapshot
+		want   map[string][2]any // field -> old, new
+	}{
+		{
+			name:   "no change",
+			before: &base,
+			after:  func() TenantConfigSnapshot { return base },
+			want:   map[string][2]any{},
+		},
+		{
+			name:   "limit and enforcement fields",
+			before: &base,
+			after: func() TenantConfigSnapshot {
+				after := base
+				after.Limits.SamplesPerSecond = 2000
+				after.Enforcement.EnforceMaxBodyBytes = true
+				return after
+			},
+			want: map[string][2]any{
+				"limits.samples_per_second":          {1000.0, 2000.0},
+				"enforcement.enforce_max_body_bytes": {false, true},
+			},
+		},
+		{
+			name:   "profile",
+			before: &base,
+			after: func() TenantConfigSnapshot {
+				after := base
+				after.Profile = "gold"
+				return after
+			},
+			want: map[string][2]any{
+				"profile": {"silver", "gold"},
+			},
+		},
+		{
+			name:   "temporary override set",
+			before: &base,
+			after: func() TenantConfigSnapshot {
+				after := base
+				after.TemporaryOverride = override
+				return after
+			},
+			want: map[string][2]any{
+				"temporary_override": {(*TemporaryOverride)(nil), override},
+			},
+		},
+		{
+			name:   "nil before is an empty configuration",
+			before: nil,
+			after: func() TenantConfigSnapshot {
+				return TenantConfigSnapshot{Limits: TenantLimits{SamplesPerSecond: 10}, Profile: "gold"}
+			},
+			want: map[string][2]any{
+				"limits.samples_per_second": {0.0, 10.0},
+				"profile":                   {"", "gold"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := DiffTenantConfig(tt.before, tt.after())
+			if len(changes) != len(tt.want) {
+				t.Fatalf("got %d changes %+v, want %d", len(changes), changes, len(tt.want))
+			}
+			for _, change := range changes {
+				want, ok := tt.want[change.Field]
+				if !ok {
+					t.Errorf("unexpected change of %s: %v -> %v", change.Field, change.Old, change.New)
+					continue
+				}
+				if change.Old != want[0] || change.New != want[1] {
+					t.Errorf("%s: got %v -> %v, want %v -> %v", change.Field, change.Old, change.New, want[0], want[1])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package limits
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Limit layers reported as the source of an effective limit value, lowest precedence first
+const (
+	LayerDefault   = "default"
+	LayerSynced    = "synced_default" // defaults of every tenant synced in bulk, e.g. Mimir's global limits
+	LayerProfile   = "profile"
+	LayerTenant    = "tenant"
+	LayerTemporary = "temporary"
+)
+
+// LimitOverrides holds an optional value for each tenant limit.
+// Nil fields inherit the value from the layer below.
+type LimitOverrides struct {
+	SamplesPerSecond    *float64 `json:"samples_per_second,omitempty"`
+	BurstPercent        *float64 `json:"burst_pct,omitempty"`
+	MaxBodyBytes        *int64   `json:"max_body_bytes,omitempty"`
+	MaxLabelsPerSeries  *int32   `json:"max_labels_per_series,omitempty"`
+	MaxLabelValueLength *int32   `json:"max_label_value_length,omitempty"`
+	MaxSeriesPerRequest *int32   `json:"max_series_per_request,omitempty"`
+	MaxSeriesPerMetric  *int32   `json:"max_series_per_metric,omitempty"`
+
+	IngestionBurstSize          *int64   `json:"ingestion_burst_size,omitempty"`
+	IngestionRateStrategy       *string  `json:"ingestion_rate_strategy,omitempty"`
+	RequestRate                 *float64 `json:"request_rate,omitempty"`
+	RequestBurstSize            *int64   `json:"request_burst_size,omitempty"`
+	MaxGlobalExemplarsPerUser   *int32   `json:"max_global_exemplars_per_user,omitempty"`
+	MaxGlobalMetadataPerUser    *int32   `json:"max_global_metadata_per_user,omitempty"`
+	MaxGlobalMetadataPerMetric  *int32   `json:"max_global_metadata_per_metric,omitempty"`
+	MaxLabelNameLength          *int32   `json:"max_label_name_length,omitempty"`
+	MaxMetadataLength           *int32   `json:"max_metadata_length,omitempty"`
+	MaxNativeHistogramBuckets   *int32   `json:"max_native_histogram_buckets,omitempty"`
+	OutOfOrderTimeWindowSeconds *int64   `json:"out_of_order_time_window_seconds,omitempty"`
+	CreationGracePeriodSeconds  *int64   `json:"creation_grace_period_seconds,omitempty"`
+	PastGracePeriodSeconds      *int64   `json:"past_grace_period_seconds,omitempty"`
+	AcceptHASamples             *bool    `json:"accept_ha_samples,omitempty"`
+	HAClusterLabel              *string  `json:"ha_cluster_label,omitempty"`
+	HAReplicaLabel              *string  `json:"ha_replica_label,omitempty"`
+	HAMaxClusters               *int32   `json:"ha_max_clusters,omitempty"`
+}
+
+// ingestionRateStrategies are the values Mimir accepts for ingestion_rate_strategy,
+// or "" for Mimir's default
+var ingestionRateStrategies = map[string]bool{"": true, "local": true, "global": true}
+
+// Validate reports the first invalid field: a negative or non-finite number, an unknown ingestion_rate_strategy
+// or HA labels that are the same
+func (o LimitOverrides) Validate() error {
+	ov := reflect.ValueOf(o)
+	for i := 0; i < ov.NumField(); i++ {
+		field := ov.Field(i)
+		if field.IsNil() {
+			continue
+		}
+		name := jsonFieldName(ov.Type().Field(i))
+		switch value := field.Elem(); value.Kind() {
+		case reflect.Float64:
+			if f := value.Float(); f < 0 || math.IsNaN(f) || math.IsInf(f, 0) {
+				return fmt.Errorf("%s must be a non-negative number, got %v", name, f)
+			}
+		case reflect.Int32, reflect.Int64:
+			if value.Int() < 0 {
+				return fmt.Errorf("%s must not be negative, got %d", name, value.Int())
+			}
+		}
+	}
+	if o.IngestionRateStrategy != nil && !ingestionRateStrategies[*o.IngestionRateStrategy] {
+		return fmt.Errorf("ingestion_rate_strategy must be local or global, got %q", *o.IngestionRateStrategy)
+	}
+	if o.HAClusterLabel != nil && o.HAReplicaLabel != nil && *o.HAClusterLabel != "" && *o.HAClusterLabel == *o.HAReplicaLabel {
+		return fmt.Errorf("ha_replica_label must differ from ha_cluster_label %q", *o.HAClusterLabel)
+	}
+	return nil
+}
+
+// LimitLayer is a named set of overrides taking part in limit resolution
+type LimitLayer struct {
+	Source    string // e.g. "profile:gold", "tenant", "temporary"
+	Overrides LimitOverrides
+}
+
+// EffectiveValue is a resolved limit value together with the layer that supplied it
+type EffectiveValue struct {
+	Value  any    `json:"value"`
+	Source string `json:"source"`
+}
+
+// FullOverrides returns overrides that explicitly set every field of the given limits
+func FullOverrides(l TenantLimits) LimitOverrides {
+	var o LimitOverrides
+	ov := reflect.ValueOf(&o).Elem()
+	lv := reflect.ValueOf(l)
+	for i := 0; i < ov.NumField(); i++ {
+		field := lv.FieldByName(ov.Type().Field(i).Name)
+		if !field.IsValid() {
+			continue
+		}
+		ptr := reflect.New(field.Type())
+		ptr.Elem().Set(field)
+		ov.Field(i).Set(ptr)
+	}
+	return o
+}
+
+// ResolveLimits merges the layers on top of the defaults field by field; later layers win.
+// It returns the effective limits and, keyed by JSON field name, the layer each value came from.
+func ResolveLimits(defaults TenantLimits, layers ...LimitLayer) (TenantLimits, map[string]EffectiveValue) {
+	resolved := defaults
+	rv := reflect.ValueOf(&resolved).Elem()
+	rt := rv.Type()
+
+	sources := make(map[string]string, rt.NumField())
+	for i := 0; i < rt.NumField(); i++ {
+		sources[rt.Field(i).Name] = LayerDefault
+	}
+
+	for _, layer := range layers {
+		ov := reflect.ValueOf(layer.Overrides)
+		for i := 0; i < ov.NumField(); i++ {
+			value := ov.Field(i)
+			if value.IsNil() {
+				continue
+			}
+			name := ov.Type().Field(i).Name
+			if target := rv.FieldByName(name); target.IsValid() {
+				target.Set(value.Elem())
+				sources[name] = layer.Source
+			}
+		}
+	}
+
+	effective := make(map[string]EffectiveValue, rt.NumField())
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		effective[jsonFieldName(field)] = EffectiveValue{
+			Value:  rv.Field(i).Interface(),
+			Source: sources[field.Name],
+		}
+	}
+	return resolved, effective
+}
@@ -0,0 +1,158 @@
+package limits
+
+import (
+	"testing"
+)
+
+func float64Ptr(v float64) *float64 { return &v }
+func int32Ptr(v int32) *int32       { return &v }
+func int64Ptr(v int64) *int64       { return &v }
+func stringPtr(v string) *string    { return &v }
+
+func TestResolveLimits(t *testing.T) {
+	defaults := TenantLimits{
+		SamplesPerSecond:    1000,
+		BurstPercent:        0.2,
+		MaxBodyBytes:        4 << 20,
+		MaxSeriesPerRequest: 100,
+	}
+
+	tests := []struct {
+		name        string
+		layers      []LimitLayer
+		wantSPS     float64
+		wantSeries  int32
+		wantBody    int64
+		wantSources map[string]string
+	}{
+		{
+			name:       "defaults only",
+			wantSPS:    1000,
+			wantSeries: 100,
+			wantBody:   4 << 20,
+			wantSources: map[string]string{
+				"samples_per_second":     LayerDefault,
+				"max_series_per_request": LayerDefault,
+				"max_body_bytes":         LayerDefault,
+			},
+		},
+		{
+			name: "profile overrides a single field",
+			layers: []LimitLayer{
+				{Source: LayerProfile + ":gold", Overrides: LimitOverrides{SamplesPerSecond: float64Ptr(5000)}},
+			},
+			wantSPS:    5000,
+			wantSeries: 100,
+			wantBody:   4 << 20,
+			wantSources: map[string]string{
+				"samples_per_second":     "profile:gold",
+				"max_series_per_request": LayerDefault,
+			},
+		},
+		{
+			name: "later layers win field by field",
+			layers: []LimitLayer{
+				{Source: LayerSynced, Overrides: LimitOverrides{SamplesPerSecond: float64Ptr(2000), MaxBodyBytes: int64Ptr(1 << 20)}},
+				{Source: LayerProfile + ":gold", Overrides: LimitOverrides{SamplesPerSecond: float64Ptr(5000)}},
+				{Source: LayerTenant, Overrides: LimitOverrides{MaxSeriesPerRequest: int32Ptr(300)}},
+				{Source: LayerTemporary, Overrides: LimitOverrides{SamplesPerSecond: float64Ptr(9000)}},
+			},
+			wantSPS:    9000,
+			wantSeries: 300,
+			wantBody:   1 << 20,
+			wantSources: map[string]string{
+				"samples_per_second":     LayerTemporary,
+				"max_series_per_request": LayerTenant,
+				"max_body_bytes":         LayerSynced,
+				"burst_pct":              LayerDefault,
+			},
+		},
+		{
+			name: "an explicit zero is a value, not inheritance",
+			layers: []LimitLayer{
+				{Source: LayerTenant, Overrides: LimitOverrides{SamplesPerSecond: float64Ptr(0)}},
+			},
+			wantSPS:    0,
+			wantSeries: 100,
+			wantBody:   4 << 20,
+			wantSources: map[string]string{
+				"samples_per_second": LayerTenant,
+			},
+		},
+		{
+			name: "an empty layer changes nothing",
+			layers: []LimitLayer{
+				{Source: LayerTemporary},
+			},
+			wantSPS:    1000,
+			wantSeries: 100,
+			wantBody:   4 << 20,
+			wantSources: map[string]string{
+				"samples_per_second": LayerDefault,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved, effective := ResolveLimits(defaults, tt.layers...)
+			if resolved.SamplesPerSecond != tt.wantSPS {
+				t.Errorf("samples_per_second = %v, want %v", resolved.SamplesPerSecond, tt.wantSPS)
+			}
+			if resolved.MaxSeriesPerRequest != tt.wantSeries {
+				t.Errorf("max_series_per_request = %v, want %v", resolved.MaxSeriesPerRequest, tt.wantSeries)
+			}
+			if resolved.MaxBodyBytes != tt.wantBody {
+				t.Errorf("max_body_bytes = %v, want %v", resolved.MaxBodyBytes, tt.wantBody)
+			}
+			for field, source := range tt.wantSources {
+				if got := effective[field].Source; got != source {
+					t.Errorf("source of %s = %q, want %q", field, got, source)
+				}
+			}
+			if got := effective["samples_per_second"].Value; got != tt.wantSPS {
+				t.Errorf("effective samples_per_second = %v, want %v", got, tt.wantSPS)
+			}
+		})
+	}
+}
+
+func TestResolveLimitsDoesNotModifyDefaults(t *testing.T) {
+	defaults := TenantLimits{SamplesPerSecond: 1000}
+	ResolveLimits(defaults, LimitLayer{Source: LayerTenant, Overrides: LimitOverrides{SamplesPerSecond: float64Ptr(1)}})
+	if defaults.SamplesPerSecond != 1000 {
+		t.Fatalf("defaults were modified: samples_per_second = %v", defaults.SamplesPerSecond)
+	}
+}
+
+func TestFullOverridesRoundTrip(t *testing.T) {
+	limits := TenantLimits{SamplesPerSecond: 42, MaxBodyBytes: 7, HAClusterLabel: "cluster"}
+	resolved, _ := ResolveLimits(TenantLimits{SamplesPerSecond: 1, MaxSeriesPerRequest: 9}, LimitLayer{Source: LayerTenant, Overrides: FullOverrides(limits)})
+	if resolved != limits {
+		t.Fatalf("resolved = %+v, want %+v", resolved, limits)
+	}
+}
+
+func TestLimitOverridesValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides LimitOverrides
+		wantErr   bool
+	}{
+		{name: "empty", overrides: LimitOverrides{}},
+		{name: "valid values", overrides: LimitOverrides{SamplesPerSecond: float64Ptr(10), MaxBodyBytes: int64Ptr(0), IngestionRateStrategy: stringPtr("global")}},
+		{name: "negative float", overrides: LimitOverrides{SamplesPerSecond: float64Ptr(-1)}, wantErr: true},
+		{name: "negative int", overrides: LimitOverrides{MaxSeriesPerRequest: int32Ptr(-1)}, wantErr: true},
+		{name: "unknown strategy", overrides: LimitOverrides{IngestionRateStrategy: stringPtr("fast")}, wantErr: true},
+		{name: "same HA labels", overrides: LimitOverrides{HAClusterLabel: stringPtr("a"), HAReplicaLabel: stringPtr("a")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.overrides.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
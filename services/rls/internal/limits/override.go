@@ -0,0 +1,30 @@
+package limits
+
+import "time"
+
+// TemporaryOverride replaces selected limits of a tenant until it expires.
+// Unset fields leave the underlying limit untouched.
+type TemporaryOverride struct {
+	SamplesPerSecond    *float64  `json:"samples_per_second,omitempty"`
+	MaxSeriesPerRequest *int32    `json:"max_series_per_request,omitempty"`
+	Reason              string    `json:"reason,omitempty"`
+	CreatedBy           string    `json:"created_by,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+	ExpiresAt           time.Time `json:"expires_at"`
+}
+
+// Active reports whether the override is set and has not yet expired
+func (o *TemporaryOverride) Active(now time.Time) bool {
+	return o != nil && now.Before(o.ExpiresAt)
+}
+
+// Overrides returns the limits replaced by the override as a resolution layer
+func (o *TemporaryOverride) Overrides() LimitOverrides {
+	if o == nil {
+		return LimitOverrides{}
+	}
+	return LimitOverrides{
+		SamplesPerSecond:    o.SamplesPerSecond,
+		MaxSeriesPerRequest: o.MaxSeriesPerRequest,
+	}
+}
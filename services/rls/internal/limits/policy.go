@@ -0,0 +1,55 @@
+package limits
+
+import "fmt"
+
+// EdgePolicy holds edge-only settings of a tenant that Mimir's overrides have no place for,
+// e.g. from an EdgeEnforcementPolicy resource synced by overrides-sync
+type EdgePolicy struct {
+	Source string `json:"source,omitempty"` // where the policy came from, e.g. namespace/name of the resource
+	// Enforcement replaces the default enforcement configuration while the policy is set
+	Enforcement *EnforcementConfig `json:"enforcement,omitempty"`
+	// ShadowMode evaluates the limits but allows every request, counting what would have been denied
+	ShadowMode bool `json:"shadow_mode,omitempty"`
+	// SelectiveFiltering replaces the global selective filtering settings for the tenant
+	SelectiveFiltering *SelectiveFilteringPolicy `json:"selective_filtering,omitempty"`
+}
+
+// SelectiveFilteringPolicy is a tenant's selective filtering configuration; unset fields use the global flags
+type SelectiveFilteringPolicy struct {
+	Enabled                *bool    `json:"enabled,omitempty"`
+	Strategy               string   `json:"strategy,omitempty"`        // random, oldest, newest or priority
+	MetricPriority         []string `json:"metric_priority,omitempty"` // metrics dropped last, highest priority first
+	MaxFilteringPercentage *int64   `json:"max_filtering_percentage,omitempty"`
+	MinSeriesToKeep        *int64   `json:"min_series_to_keep,omitempty"`
+}
+
+// selectionStrategies are the series selection strategies of selective filtering
+var selectionStrategies = map[string]bool{
+	"random":   true,
+	"oldest":   true,
+	"newest":   true,
+	"priority": true,
+}
+
+// Validate reports the first invalid setting of the policy
+func (p *EdgePolicy) Validate() error {
+	if p == nil || p.SelectiveFiltering == nil {
+		return nil
+	}
+	sf := p.SelectiveFiltering
+	if sf.Strategy != "" && !selectionStrategies[sf.Strategy] {
+		return fmt.Errorf("selective_filtering.strategy must be random, oldest, newest or priority, got %q", sf.Strategy)
+	}
+	if sf.MaxFilteringPercentage != nil && (*sf.MaxFilteringPercentage < 0 || *sf.MaxFilteringPercentage > 100) {
+		return fmt.Errorf("selective_filtering.max_filtering_percentage must be between 0 and 100, got %d", *sf.MaxFilteringPercentage)
+	}
+	if sf.MinSeriesToKeep != nil && *sf.MinSeriesToKeep < 0 {
+		return fmt.Errorf("selective_filtering.min_series_to_keep must not be negative, got %d", *sf.MinSeriesToKeep)
+	}
+	return nil
+}
+
+// InShadowMode reports whether the policy only observes; a nil policy enforces
+func (p *EdgePolicy) InShadowMode() bool {
+	return p != nil && p.ShadowMode
+}
@@ -13,6 +13,25 @@ type TenantLimits struct {
 	MaxLabelValueLength int32   `json:"max_label_value_length"`
 	MaxSeriesPerRequest int32   `json:"max_series_per_request"`
 	MaxSeriesPerMetric  int32   `json:"max_series_per_metric"` // 🔧 NEW: Per-metric series limit
+
+	// 🔧 NEW: Remaining ingestion limits of Mimir's per-tenant overrides, as synced by overrides-sync
+	IngestionBurstSize          int64   `json:"ingestion_burst_size,omitempty"`
+	IngestionRateStrategy       string  `json:"ingestion_rate_strategy,omitempty"` // local or global
+	RequestRate                 float64 `json:"request_rate,omitempty"`
+	RequestBurstSize            int64   `json:"request_burst_size,omitempty"`
+	MaxGlobalExemplarsPerUser   int32   `json:"max_global_exemplars_per_user,omitempty"`
+	MaxGlobalMetadataPerUser    int32   `json:"max_global_metadata_per_user,omitempty"`
+	MaxGlobalMetadataPerMetric  int32   `json:"max_global_metadata_per_metric,omitempty"`
+	MaxLabelNameLength          int32   `json:"max_label_name_length,omitempty"`
+	MaxMetadataLength           int32   `json:"max_metadata_length,omitempty"`
+	MaxNativeHistogramBuckets   int32   `json:"max_native_histogram_buckets,omitempty"`
+	OutOfOrderTimeWindowSeconds int64   `json:"out_of_order_time_window_seconds,omitempty"`
+	CreationGracePeriodSeconds  int64   `json:"creation_grace_period_seconds,omitempty"`
+	PastGracePeriodSeconds      int64   `json:"past_grace_period_seconds,omitempty"`
+	AcceptHASamples             bool    `json:"accept_ha_samples,omitempty"`
+	HAClusterLabel              string  `json:"ha_cluster_label,omitempty"`
+	HAReplicaLabel              string  `json:"ha_replica_label,omitempty"`
+	HAMaxClusters               int32   `json:"ha_max_clusters,omitempty"`
 }
 
 // EnforcementConfig represents enforcement settings for a tenant
@@ -36,6 +55,16 @@ type TenantInfo struct {
 	Limits      TenantLimits      `json:"limits"`
 	Metrics     TenantMetrics     `json:"metrics"`
 	Enforcement EnforcementConfig `json:"enforcement"`
+	// 🔧 NEW: Temporary override currently layered on top of the synced limits
+	TemporaryOverride *TemporaryOverride `json:"temporary_override,omitempty"`
+	// 🔧 NEW: Named limit profile (tier) the tenant inherits from
+	Profile string `json:"profile,omitempty"`
+	// 🔧 NEW: The tenant has limits of its own, e.g. synced from Mimir's overrides, rather than only profile and defaults
+	HasTenantLimits bool `json:"has_tenant_limits,omitempty"`
+	// 🔧 NEW: The limits set for the tenant itself, without its profile, the defaults or a temporary override
+	Overrides *LimitOverrides `json:"overrides,omitempty"`
+	// 🔧 NEW: Edge-only settings from an EdgeEnforcementPolicy
+	Policy *EdgePolicy `json:"policy,omitempty"`
 }
 
 // TenantMetrics represents metrics for a tenant
@@ -138,6 +167,9 @@ type RequestInfo struct {
 	ObservedSeries     int64            `json:"observed_series"`
 	ObservedLabels     int64            `json:"observed_labels"`
 	MetricSeriesCounts map[string]int64 `json:"metric_series_counts"` // 🔧 NEW: Per-metric series counts for Mimir-style limits
+	// 🔧 NEW: HA cluster and replica labels of the request's first series, when the tenant accepts HA samples
+	HACluster string `json:"ha_cluster,omitempty"`
+	HAReplica string `json:"ha_replica,omitempty"`
 }
 
 // Decision represents the result of an authorization check
@@ -157,6 +189,9 @@ type CardinalityMetrics struct {
 	MaxLabelsInSeries     int64   `json:"max_labels_in_series"`
 	CardinalityViolations int64   `json:"cardinality_violations"`
 	ViolationRate         float64 `json:"violation_rate"`
+	// 🔧 NEW: Sample sizes of the averages, so replicas' views can be merged
+	SampledRequests int64 `json:"sampled_requests"` // requests behind AvgSeriesPerRequest and AvgLabelsPerSeries
+	TotalRequests   int64 `json:"total_requests"`   // requests behind ViolationRate
 }
 
 // CardinalityViolation represents a cardinality violation
@@ -189,6 +224,10 @@ type CardinalityAlert struct {
 	Value     int64     `json:"value"`
 	Threshold int64     `json:"threshold"`
 	Resolved  bool      `json:"resolved"`
+	// 🔧 NEW: Rule engine state
+	Rule     string     `json:"rule,omitempty"`
+	StartsAt time.Time  `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
 }
 
 // CardinalityData represents comprehensive cardinality data for the dashboard
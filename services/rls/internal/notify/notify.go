@@ -0,0 +1,379 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+)
+
+// Event types emitted by RLS
+const (
+	EventFirstDenial        = "first_denial"        // a tenant is denied after a quiet period
+	EventSeriesThreshold    = "series_threshold"    // a tenant crossed 80/90/100% of its series limit
+	EventSafetyValve        = "safety_valve"        // the safety valve let denied traffic through
+	EventLimitsChanged      = "limits_changed"      // a tenant's limits, profile, overrides or enforcement changed
+	EventCardinalityAlert   = "cardinality_alert"   // a cardinality alert started firing
+	EventCardinalityResolve = "cardinality_resolve" // a cardinality alert resolved
+)
+
+// Sink kinds
+const (
+	SinkAlertmanager = "alertmanager" // Alertmanager v2 API (POST /api/v2/alerts)
+	SinkSlack        = "slack"        // Slack-compatible incoming webhook
+	SinkGeneric      = "generic"      // JSON batch of events
+)
+
+// Event is a single enforcement event
+type Event struct {
+	Type      string            `json:"type"`
+	TenantID  string            `json:"tenant_id,omitempty"`
+	Severity  string            `json:"severity"`
+	Summary   string            `json:"summary"`
+	Labels    map[string]string `json:"labels,omitempty"`  // extra labels, e.g. rule or metric
+	Details   map[string]string `json:"details,omitempty"` // free-form context
+	Timestamp time.Time         `json:"timestamp"`
+	Resolved  bool              `json:"resolved,omitempty"`
+}
+
+// Webhook is a configured notification target
+type Webhook struct {
+	Kind string
+	URL  string
+}
+
+// ParseWebhooks parses a comma-separated list of kind=url entries
+func ParseWebhooks(spec string) ([]Webhook, error) {
+	var webhooks []Webhook
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kind, url, ok := strings.Cut(entry, "=")
+		if !ok || url == "" {
+			return nil, fmt.Errorf("invalid webhook %q: expected kind=url", entry)
+		}
+		switch kind {
+		case SinkAlertmanager, SinkSlack, SinkGeneric:
+		default:
+			return nil, fmt.Errorf("invalid webhook kind %q: expected alertmanager, slack or generic", kind)
+		}
+		webhooks = append(webhooks, Webhook{Kind: kind, URL: url})
+	}
+	return webhooks, nil
+}
+
+// Config controls batching and delivery
+type Config struct {
+	Webhooks       []Webhook
+	Events         map[string]bool // event types to send; empty sends all
+	BatchSize      int             // flush once this many events are queued
+	BatchInterval  time.Duration   // flush at least this often
+	QueueSize      int             // events buffered before new ones are dropped
+	MaxRetries     int             // delivery attempts after the first
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Timeout        time.Duration // per delivery attempt
+	EventTTL       time.Duration // how long one-shot events stay active in Alertmanager
+	ExternalURL    string        // reported as generatorURL
+}
+
+// Metrics counts deliveries and drops
+type Metrics struct {
+	Deliveries *prometheus.CounterVec // labels: sink, result
+	Dropped    prometheus.Counter
+}
+
+// Notifier batches events and posts them to every webhook
+type Notifier struct {
+	config  Config
+	client  *http.Client
+	queue   chan Event
+	metrics Metrics
+	logger  zerolog.Logger
+}
+
+// NewNotifier creates a notifier. It returns nil if no webhooks are configured.
+func NewNotifier(config Config, metrics Metrics, logger zerolog.Logger) *Notifier {
+	if len(config.Webhooks) == 0 {
+		return nil
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.BatchInterval <= 0 {
+		config.BatchInterval = 10 * time.Second
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1024
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = time.Second
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 30 * time.Second
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	if config.EventTTL <= 0 {
+		config.EventTTL = 5 * time.Minute
+	}
+	return &Notifier{
+		config:  config,
+		client:  &http.Client{Timeout: config.Timeout},
+		queue:   make(chan Event, config.QueueSize),
+		metrics: metrics,
+		logger:  logger,
+	}
+}
+
+// Notify queues an event without blocking. Events are dropped when the queue is full.
+// It is safe to call on a nil notifier.
+func (n *Notifier) Notify(event Event) {
+	if n == nil {
+		return
+	}
+	if len(n.config.Events) > 0 && !n.config.Events[event.Type] {
+		return
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	select {
+	case n.queue <- event:
+	default:
+		if n.metrics.Dropped != nil {
+			n.metrics.Dropped.Inc()
+		}
+	}
+}
+
+// Run batches queued events and delivers them until ctx is cancelled, then flushes what is left
+func (n *Notifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(n.config.BatchInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, n.config.BatchSize)
+	flush := func(ctx context.Context) {
+		if len(batch) == 0 {
+			return
+		}
+		n.deliver(ctx, batch)
+		batch = make([]Event, 0, n.config.BatchSize)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+		drain:
+			for {
+				select {
+				case event := <-n.queue:
+					batch = append(batch, event)
+				default:
+					break drain
+				}
+			}
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), n.config.Timeout)
+			flush(shutdownCtx)
+			cancel()
+			return
+		case event := <-n.queue:
+			batch = append(batch, event)
+			if len(batch) >= n.config.BatchSize {
+				flush(ctx)
+			}
+		case <-ticker.C:
+			flush(ctx)
+		}
+	}
+}
+
+// deliver posts one batch to every webhook
+func (n *Notifier) deliver(ctx context.Context, batch []Event) {
+	for _, webhook := range n.config.Webhooks {
+		body, err := n.encode(webhook.Kind, batch)
+		if err != nil {
+			n.logger.Error().Err(err).Str("sink", webhook.Kind).Msg("notify: failed to encode batch")
+			continue
+		}
+
+		url := webhook.URL
+		if webhook.Kind == SinkAlertmanager {
+			url = strings.TrimSuffix(url, "/") + "/api/v2/alerts"
+		}
+
+		result := "success"
+		if err := n.post(ctx, url, body); err != nil {
+			result = "failure"
+			n.logger.Error().Err(err).Str("sink", webhook.Kind).Int("events", len(batch)).Msg("notify: delivery failed")
+		}
+		if n.metrics.Deliveries != nil {
+			n.metrics.Deliveries.WithLabelValues(webhook.Kind, result).Inc()
+		}
+	}
+}
+
+// post sends body with exponential backoff, retrying connection errors, 429 and 5xx
+func (n *Notifier) post(ctx context.Context, url string, body []byte) error {
+	backoff := n.config.InitialBackoff
+	var lastErr error
+	for attempt := 0; attempt <= n.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%w (last error: %v)", ctx.Err(), lastErr)
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > n.config.MaxBackoff {
+				backoff = n.config.MaxBackoff
+			}
+		}
+
+		retry, err := n.postOnce(ctx, url, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			return err
+		}
+		n.logger.Debug().Err(err).Int("attempt", attempt+1).Str("url", url).Msg("notify: delivery attempt failed")
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", n.config.MaxRetries+1, lastErr)
+}
+
+func (n *Notifier) postOnce(ctx context.Context, url string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("webhook returned %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
+
+// encode renders a batch in the format expected by the sink
+func (n *Notifier) encode(kind string, batch []Event) ([]byte, error) {
+	switch kind {
+	case SinkAlertmanager:
+		alerts := make([]PostableAlert, 0, len(batch))
+		for _, event := range batch {
+			alerts = append(alerts, n.toPostableAlert(event))
+		}
+		return json.Marshal(alerts)
+	case SinkSlack:
+		return json.Marshal(toSlackMessage(batch))
+	default:
+		return json.Marshal(GenericPayload{Source: "mimir-edge-enforcement", Events: batch})
+	}
+}
+
+// PostableAlert is an alert in Alertmanager's v2 API
+type PostableAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	StartsAt     time.Time         `json:"startsAt,omitempty"`
+	EndsAt       time.Time         `json:"endsAt,omitempty"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// alertNames maps event types to Alertmanager alert names
+var alertNames = map[string]string{
+	EventFirstDenial:        "EdgeTenantThrottled",
+	EventSeriesThreshold:    "EdgeTenantSeriesThreshold",
+	EventSafetyValve:        "EdgeSafetyValveActivated",
+	EventLimitsChanged:      "EdgeTenantLimitsChanged",
+	EventCardinalityAlert:   "EdgeCardinalityAlert",
+	EventCardinalityResolve: "EdgeCardinalityAlert",
+}
+
+// toPostableAlert converts an event to an Alertmanager alert.
+// Events are sent once, so they end after EventTTL unless they report a resolution.
+func (n *Notifier) toPostableAlert(event Event) PostableAlert {
+	labels := map[string]string{
+		"alertname": alertNames[event.Type],
+		"event":     event.Type,
+		"severity":  event.Severity,
+		"source":    "mimir-edge-enforcement",
+	}
+	if event.TenantID != "" {
+		labels["tenant"] = event.TenantID
+	}
+	for k, v := range event.Labels {
+		labels[k] = v
+	}
+
+	annotations := map[string]string{"summary": event.Summary}
+	for k, v := range event.Details {
+		annotations[k] = v
+	}
+
+	alert := PostableAlert{
+		Labels:       labels,
+		Annotations:  annotations,
+		StartsAt:     event.Timestamp,
+		EndsAt:       event.Timestamp.Add(n.config.EventTTL),
+		GeneratorURL: n.config.ExternalURL,
+	}
+	if event.Resolved {
+		alert.EndsAt = event.Timestamp
+	}
+	return alert
+}
+
+// SlackMessage is a Slack-compatible incoming webhook payload
+type SlackMessage struct {
+	Text string `json:"text"`
+}
+
+func toSlackMessage(batch []Event) SlackMessage {
+	var b strings.Builder
+	for i, event := range batch {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		icon := ":warning:"
+		switch {
+		case event.Resolved:
+			icon = ":white_check_mark:"
+		case event.Severity == "critical":
+			icon = ":rotating_light:"
+		case event.Severity == "info":
+			icon = ":information_source:"
+		}
+		fmt.Fprintf(&b, "%s *%s*", icon, event.Type)
+		if event.TenantID != "" {
+			fmt.Fprintf(&b, " `%s`", event.TenantID)
+		}
+		fmt.Fprintf(&b, ": %s", event.Summary)
+	}
+	return SlackMessage{Text: b.String()}
+}
+
+// GenericPayload is the JSON body posted to generic webhooks
+type GenericPayload struct {
+	Source string  `json:"source"`
+	Events []Event `json:"events"`
+}
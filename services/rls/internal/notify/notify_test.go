@@ -0,0 +1,137 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestParseWebhooks(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []Webhook
+		wantErr bool
+	}{
+		{spec: ""},
+		{spec: "slack=http://slack", want: []Webhook{{Kind: SinkSlack, URL: "http://slack"}}},
+		{
+			spec: "alertmanager=http://am:9093, generic=http://hook",
+			want: []Webhook{{Kind: SinkAlertmanager, URL: "http://am:9093"}, {Kind: SinkGeneric, URL: "http://hook"}},
+		},
+		{spec: "http://missing-kind", wantErr: true},
+		{spec: "pagerduty=http://pd", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseWebhooks(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseWebhooks(%q) error = %v, want error %v", tt.spec, err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseWebhooks(%q) = %v, want %v", tt.spec, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("webhook %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNotifierDeliversToReceiver(t *testing.T) {
+	tests := []struct {
+		name      string
+		kind      string
+		wantPath  string
+		failFirst int
+	}{
+		{name: "generic", kind: SinkGeneric, wantPath: "/hook"},
+		{name: "alertmanager", kind: SinkAlertmanager, wantPath: "/hook/api/v2/alerts"},
+		{name: "retried after failures", kind: SinkSlack, wantPath: "/hook", failFirst: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := NewReceiver(0)
+			receiver.FailNext(tt.failFirst)
+			server := httptest.NewServer(receiver)
+			defer server.Close()
+
+			notifier := NewNotifier(Config{
+				Webhooks:       []Webhook{{Kind: tt.kind, URL: server.URL + "/hook"}},
+				BatchSize:      2,
+				BatchInterval:  time.Hour,
+				MaxRetries:     tt.failFirst,
+				InitialBackoff: time.Millisecond,
+				MaxBackoff:     time.Millisecond,
+			}, Metrics{}, zerolog.Nop())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				notifier.Run(ctx)
+				close(done)
+			}()
+			notifier.Notify(Event{Type: "denial", TenantID: "tenant-a", Severity: "warning", Summary: "denied"})
+			notifier.Notify(Event{Type: "denial", TenantID: "tenant-b", Severity: "warning", Summary: "denied"})
+
+			deadline := time.Now().Add(5 * time.Second)
+			for len(receiver.Requests()) == 0 && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			cancel()
+			<-done
+
+			requests := receiver.Requests()
+			if len(requests) != 1 {
+				t.Fatalf("receiver got %d requests, want one batch", len(requests))
+			}
+			if requests[0].Path != tt.wantPath {
+				t.Errorf("path = %s, want %s", requests[0].Path, tt.wantPath)
+			}
+			if !json.Valid(requests[0].Body) {
+				t.Errorf("body is not JSON: %s", requests[0].Body)
+			}
+		})
+	}
+}
+
+func TestNotifierFiltersEventTypes(t *testing.T) {
+	receiver := NewReceiver(0)
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	notifier := NewNotifier(Config{
+		Webhooks:      []Webhook{{Kind: SinkGeneric, URL: server.URL}},
+		Events:        map[string]bool{"override_expired": true},
+		BatchInterval: time.Hour,
+	}, Metrics{}, zerolog.Nop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		notifier.Run(ctx)
+		close(done)
+	}()
+	notifier.Notify(Event{Type: "denial", TenantID: "tenant-a"})
+	cancel()
+	<-done
+
+	if got := len(receiver.Requests()); got != 0 {
+		t.Fatalf("receiver got %d requests for a filtered event type, want 0", got)
+	}
+}
+
+func TestNilNotifier(t *testing.T) {
+	var notifier *Notifier
+	notifier.Notify(Event{Type: "denial"})
+	if NewNotifier(Config{}, Metrics{}, zerolog.Nop()) != nil {
+		t.Fatal("NewNotifier without webhooks should return nil")
+	}
+}
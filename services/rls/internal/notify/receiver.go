@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ReceivedRequest is a webhook request captured by a Receiver
+type ReceivedRequest struct {
+	Path       string          `json:"path"`
+	ReceivedAt time.Time       `json:"received_at"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// Receiver is a local stand-in for Alertmanager, Slack or any webhook endpoint.
+// It records every POST and can be told to fail the next requests to exercise retries.
+// GET returns the recorded requests and DELETE clears them.
+type Receiver struct {
+	mu       sync.Mutex
+	requests []ReceivedRequest
+	failNext int
+	limit    int
+}
+
+// NewReceiver creates a receiver keeping at most limit requests (0 keeps 1000)
+func NewReceiver(limit int) *Receiver {
+	if limit <= 0 {
+		limit = 1000
+	}
+	return &Receiver{limit: limit}
+}
+
+// FailNext makes the next n POSTs answer 503
+func (r *Receiver) FailNext(n int) {
+	r.mu.Lock()
+	r.failNext = n
+	r.mu.Unlock()
+}
+
+// Requests returns the recorded requests, oldest first
+func (r *Receiver) Requests() []ReceivedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]ReceivedRequest, len(r.requests))
+	copy(out, r.requests)
+	return out
+}
+
+// Reset discards the recorded requests
+func (r *Receiver) Reset() {
+	r.mu.Lock()
+	r.requests = nil
+	r.failNext = 0
+	r.mu.Unlock()
+}
+
+// ServeHTTP implements http.Handler
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"requests": r.Requests()})
+	case http.MethodDelete:
+		r.Reset()
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodPost:
+		body, err := io.ReadAll(io.LimitReader(req.Body, 4<<20))
+		if err != nil || !json.Valid(body) {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+
+		r.mu.Lock()
+		if r.failNext > 0 {
+			r.failNext--
+			r.mu.Unlock()
+			http.Error(w, "receiver told to fail", http.StatusServiceUnavailable)
+			return
+		}
+		r.requests = append(r.requests, ReceivedRequest{Path: req.URL.Path, ReceivedAt: time.Now(), Body: body})
+		if len(r.requests) > r.limit {
+			r.requests = r.requests[len(r.requests)-r.limit:]
+		}
+		r.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
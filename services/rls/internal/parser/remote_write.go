@@ -3,14 +3,18 @@ package parser
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"fmt"
 	"io"
 	"strings"
 
 	"github.com/golang/snappy"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/proto"
 
 	prompb "github.com/AkshayDubey29/mimir-edge-enforcement/protos/prometheus"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/tracing"
 )
 
 // ParseResult represents the result of parsing a remote write request
@@ -22,6 +26,14 @@ type ParseResult struct {
 	// 🔧 NEW: Per-metric series counts for Mimir-style limits
 	MetricSeriesCounts map[string]int64    `json:"metric_series_counts"`
 	MetricSeriesHashes map[string][]string `json:"metric_series_hashes"` // For deduplication
+	// 🔧 NEW: Labels of every series, shared with the decoded request, for cardinality tracking
+	Series []SeriesLabels `json:"-"`
+}
+
+// SeriesLabels is the metric name and label set of one series
+type SeriesLabels struct {
+	MetricName string
+	Labels     []*prompb.Label
 }
 
 // SampleMetricDetail represents a parsed metric sample
@@ -34,6 +46,12 @@ type SampleMetricDetail struct {
 
 // ParseRemoteWriteRequest parses a Prometheus remote write request and counts samples
 func ParseRemoteWriteRequest(body []byte, contentEncoding string) (*ParseResult, error) {
+	return ParseRemoteWriteRequestContext(context.Background(), body, contentEncoding)
+}
+
+// 🔧 NEW: ParseRemoteWriteRequestContext parses a remote write request, tracing decompression and decoding
+// as children of the span in ctx
+func ParseRemoteWriteRequestContext(ctx context.Context, body []byte, contentEncoding string) (*ParseResult, error) {
 	// 🔧 PERFORMANCE FIX: Early return for empty body
 	if len(body) == 0 {
 		return &ParseResult{SamplesCount: 0, SeriesCount: 0, LabelsCount: 0}, nil
@@ -72,10 +90,17 @@ func ParseRemoteWriteRequest(body []byte, contentEncoding string) (*ParseResult,
 	}
 
 	// Decompress based on content encoding
+	_, decompressSpan := tracing.Start(ctx, "parser.decompress", trace.SpanKindInternal,
+		attribute.String("content_encoding", contentEncoding),
+		attribute.Int64("compressed_bytes", int64(len(body))))
 	decompressed, err := decompress(body, contentEncoding)
 	if err != nil {
+		tracing.RecordError(decompressSpan, err)
+		decompressSpan.End()
 		return nil, fmt.Errorf("failed to decompress body: %w", err)
 	}
+	decompressSpan.SetAttributes(attribute.Int64("decompressed_bytes", int64(len(decompressed))))
+	decompressSpan.End()
 
 	fmt.Printf("DEBUG: Decompressed body size: %d (original: %d)\n", len(decompressed), len(body))
 	fmt.Printf("DEBUG: Decompressed body preview: %q\n", string(decompressed[:minInt(100, len(decompressed))]))
@@ -87,6 +112,8 @@ func ParseRemoteWriteRequest(body []byte, contentEncoding string) (*ParseResult,
 	}
 
 	// Parse protobuf
+	_, unmarshalSpan := tracing.Start(ctx, "parser.unmarshal", trace.SpanKindInternal)
+	defer unmarshalSpan.End()
 	var writeRequest prompb.WriteRequest
 	parseSuccess := false
 
@@ -123,6 +150,7 @@ func ParseRemoteWriteRequest(body []byte, contentEncoding string) (*ParseResult,
 		fmt.Printf("DEBUG: Trying %s parsing strategy\n", strategy.name)
 		if err := strategy.fn(decompressed); err == nil {
 			fmt.Printf("DEBUG: %s parsing succeeded\n", strategy.name)
+			unmarshalSpan.SetAttributes(attribute.String("strategy", strategy.name))
 			parseSuccess = true
 			break
 		} else {
@@ -154,6 +182,7 @@ func ParseRemoteWriteRequest(body []byte, contentEncoding string) (*ParseResult,
 	result := &ParseResult{
 		MetricSeriesCounts: make(map[string]int64),
 		MetricSeriesHashes: make(map[string][]string),
+		Series:             make([]SeriesLabels, 0, len(writeRequest.Timeseries)),
 	}
 
 	// 🔧 ENHANCEMENT: Capture sample metric details for denial analysis
@@ -180,6 +209,7 @@ func ParseRemoteWriteRequest(body []byte, contentEncoding string) (*ParseResult,
 		// Add series hash to metric (handles deduplication automatically)
 		metricSeriesMap[metricName][seriesHash] = true
 		result.SamplesCount += int64(len(ts.Samples))
+		result.Series = append(result.Series, SeriesLabels{MetricName: metricName, Labels: ts.Labels})
 
 		// 🔧 ENHANCEMENT: Capture sample metric details for denial analysis
 		// Limit to first 10 metrics to avoid memory issues
@@ -224,6 +254,7 @@ func ParseRemoteWriteRequest(body []byte, contentEncoding string) (*ParseResult,
 		}
 	}
 
+	unmarshalSpan.SetAttributes(attribute.Int64("series", result.SeriesCount), attribute.Int64("samples", result.SamplesCount))
 	fmt.Printf("DEBUG: Successfully parsed - samples: %d, series: %d, labels: %d, metrics: %d\n",
 		result.SamplesCount, result.SeriesCount, result.LabelsCount, len(result.MetricSeriesCounts))
 
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	admin "github.com/AkshayDubey29/mimir-edge-enforcement/protos/admin"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/auth"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/limits"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ActorMetadataKey is the gRPC metadata key callers use to identify themselves for the audit log
+const ActorMetadataKey = "x-edge-actor"
+
+// AdminServer implements the gRPC AdminService on top of the RLS
+type AdminServer struct {
+	admin.UnimplementedAdminServiceServer
+	rls *RLS
+}
+
+// NewAdminServer creates a gRPC admin server backed by the given RLS
+func NewAdminServer(rls *RLS) *AdminServer {
+	return &AdminServer{rls: rls}
+}
+
+// SetLimits sets the limits for a tenant
+func (s *AdminServer) SetLimits(ctx context.Context, req *admin.SetLimitsRequest) (*admin.SetLimitsResponse, error) {
+	if req.GetTenantId() == "" || req.GetLimits() == nil {
+		return nil, status.Error(codes.InvalidArgument, "tenant_id and limits are required")
+	}
+
+	newLimits := limits.FullOverrides(fromProtoLimits(req.GetLimits()))
+
+	// The proto has no per-metric limit; keep whatever the tenant already has explicitly set
+	newLimits.MaxSeriesPerMetric = nil
+	if current, ok := s.rls.GetTenantOverrides(req.GetTenantId()); ok {
+		newLimits.MaxSeriesPerMetric = current.MaxSeriesPerMetric
+	}
+
+	if err := s.rls.SetTenantLimits(req.GetTenantId(), newLimits, grpcChangeContext(ctx)); err != nil {
+		return &admin.SetLimitsResponse{Success: false, Message: err.Error()}, nil
+	}
+	return &admin.SetLimitsResponse{Success: true, Message: "limits updated"}, nil
+}
+
+// GetLimits returns the limits for a tenant
+func (s *AdminServer) GetLimits(ctx context.Context, req *admin.GetLimitsRequest) (*admin.GetLimitsResponse, error) {
+	tenantLimits, ok := s.rls.GetTenantLimits(req.GetTenantId())
+	if !ok {
+		return &admin.GetLimitsResponse{Found: false}, nil
+	}
+	return &admin.GetLimitsResponse{Limits: toProtoLimits(*tenantLimits), Found: true}, nil
+}
+
+// ListTenants returns a page of tenants, optionally filtered by a search string
+func (s *AdminServer) ListTenants(ctx context.Context, req *admin.ListTenantsRequest) (*admin.ListTenantsResponse, error) {
+	tenants := s.rls.ListTenantsWithMetrics()
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].ID < tenants[j].ID })
+
+	if search := strings.ToLower(req.GetSearch()); search != "" {
+		filtered := tenants[:0]
+		for _, t := range tenants {
+			if strings.Contains(strings.ToLower(t.ID), search) || strings.Contains(strings.ToLower(t.Name), search) {
+				filtered = append(filtered, t)
+			}
+		}
+		tenants = filtered
+	}
+
+	page := req.GetPage()
+	if page < 1 {
+		page = 1
+	}
+	pageSize := req.GetPageSize()
+	if pageSize <= 0 {
+		pageSize = 50
+	}
+
+	start := int((page - 1) * pageSize)
+	end := minInt(start+int(pageSize), len(tenants))
+	out := make([]*admin.TenantInfo, 0, pageSize)
+	for i := start; i < end; i++ {
+		out = append(out, toProtoTenantInfo(tenants[i]))
+	}
+
+	return &admin.ListTenantsResponse{
+		Tenants:  out,
+		Total:    int32(len(tenants)),
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+// GetHealth returns the health of the RLS
+func (s *AdminServer) GetHealth(ctx context.Context, req *admin.GetHealthRequest) (*admin.GetHealthResponse, error) {
+	health := s.rls.GetHealth()
+	var lastSyncAgo int64
+	if !health.LastSyncTime.IsZero() {
+		lastSyncAgo = int64(time.Since(health.LastSyncTime).Seconds())
+	}
+
+	return &admin.GetHealthResponse{
+		Rls: &admin.RLSHealth{
+			Status:                   "healthy",
+			Version:                  health.Version,
+			OverridesResourceVersion: health.OverridesResourceVersion,
+			LastSyncAgoSec:           lastSyncAgo,
+		},
+		Envoy: &admin.EnvoyHealth{Status: "unknown"},
+	}, nil
+}
+
+// GetOverview returns overview stats and the busiest tenants
+func (s *AdminServer) GetOverview(ctx context.Context, req *admin.GetOverviewRequest) (*admin.GetOverviewResponse, error) {
+	timeRange := req.GetRange()
+	if timeRange == "" {
+		timeRange = "1h"
+	}
+	stats := s.rls.GetOverviewSnapshotWithTimeRange(timeRange)
+
+	tenants := s.rls.ListTenantsWithMetrics()
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].Metrics.RPS > tenants[j].Metrics.RPS })
+	topTenants := make([]*admin.TenantMetrics, 0, minInt(len(tenants), 10))
+	for i := 0; i < minInt(len(tenants), 10); i++ {
+		topTenants = append(topTenants, toProtoMetrics(tenants[i].Metrics))
+	}
+
+	return &admin.GetOverviewResponse{
+		Stats: &admin.OverviewStats{
+			TotalRequests:   stats.TotalRequests,
+			AllowedRequests: stats.AllowedRequests,
+			DeniedRequests:  stats.DeniedRequests,
+			AllowPercentage: stats.AllowPercentage,
+			ActiveTenants:   stats.ActiveTenants,
+		},
+		TopTenants: topTenants,
+	}, nil
+}
+
+// GetTenantDetails returns a tenant and its recent denials
+func (s *AdminServer) GetTenantDetails(ctx context.Context, req *admin.GetTenantDetailsRequest) (*admin.GetTenantDetailsResponse, error) {
+	info, ok := s.rls.GetTenantSnapshot(req.GetTenantId())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "tenant %s not found", req.GetTenantId())
+	}
+
+	denials := s.rls.RecentDenials(req.GetTenantId(), 24*time.Hour)
+	out := make([]*admin.DenialInfo, 0, len(denials))
+	for _, d := range denials {
+		out = append(out, &admin.DenialInfo{
+			TenantId:          d.TenantID,
+			Reason:            d.Reason,
+			Timestamp:         timestamppb.New(d.Timestamp),
+			ObservedSamples:   d.ObservedSamples,
+			ObservedBodyBytes: d.ObservedBodyBytes,
+		})
+	}
+
+	return &admin.GetTenantDetailsResponse{Tenant: toProtoTenantInfo(info), RecentDenials: out}, nil
+}
+
+// SetEnforcement updates the enforcement configuration for a tenant.
+// Only the fields present in the proto are changed; granular enforcement flags are preserved.
+func (s *AdminServer) SetEnforcement(ctx context.Context, req *admin.SetEnforcementRequest) (*admin.SetEnforcementResponse, error) {
+	if req.GetTenantId() == "" || req.GetEnforcement() == nil {
+		return nil, status.Error(codes.InvalidArgument, "tenant_id and enforcement are required")
+	}
+
+	info, ok := s.rls.GetTenantSnapshot(req.GetTenantId())
+	if !ok {
+		return &admin.SetEnforcementResponse{Success: false, Message: "tenant not found"}, nil
+	}
+
+	enforcement := info.Enforcement
+	enforcement.Enabled = req.GetEnforcement().GetEnabled()
+	enforcement.BurstPctOverride = req.GetEnforcement().GetBurstPctOverride()
+
+	if err := s.rls.SetTenantEnforcement(req.GetTenantId(), enforcement, grpcChangeContext(ctx)); err != nil {
+		return &admin.SetEnforcementResponse{Success: false, Message: err.Error()}, nil
+	}
+	return &admin.SetEnforcementResponse{Success: true, Message: "enforcement updated"}, nil
+}
+
+// grpcChangeContext identifies the caller of a gRPC admin mutation
+func grpcChangeContext(ctx context.Context) ChangeContext {
+	change := ChangeContext{Source: AuditSourceGRPCAdmin}
+	if identity, ok := auth.IdentityFromContext(ctx); ok {
+		change.Actor = identity.Subject
+		return change
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(ActorMetadataKey); len(values) > 0 {
+			change.Actor = values[0]
+		}
+	}
+	if change.Actor == "" {
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			change.Actor = p.Addr.String()
+		}
+	}
+	return change
+}
+
+func fromProtoLimits(l *admin.TenantLimits) limits.TenantLimits {
+	return limits.TenantLimits{
+		SamplesPerSecond:    l.GetSamplesPerSecond(),
+		BurstPercent:        l.GetBurstPct(),
+		MaxBodyBytes:        l.GetMaxBodyBytes(),
+		MaxLabelsPerSeries:  l.GetMaxLabelsPerSeries(),
+		MaxLabelValueLength: l.GetMaxLabelValueLength(),
+		MaxSeriesPerRequest: l.GetMaxSeriesPerRequest(),
+	}
+}
+
+func toProtoLimits(l limits.TenantLimits) *admin.TenantLimits {
+	return &admin.TenantLimits{
+		SamplesPerSecond:    l.SamplesPerSecond,
+		BurstPct:            l.BurstPercent,
+		MaxBodyBytes:        l.MaxBodyBytes,
+		MaxLabelsPerSeries:  l.MaxLabelsPerSeries,
+		MaxLabelValueLength: l.MaxLabelValueLength,
+		MaxSeriesPerRequest: l.MaxSeriesPerRequest,
+	}
+}
+
+func toProtoMetrics(m limits.TenantMetrics) *admin.TenantMetrics {
+	return &admin.TenantMetrics{
+		Rps:            m.RPS,
+		BytesPerSec:    m.BytesPerSec,
+		SamplesPerSec:  m.SamplesPerSec,
+		DenyRate:       m.DenyRate,
+		AllowRate:      m.AllowRate,
+		UtilizationPct: m.UtilizationPct,
+	}
+}
+
+func toProtoTenantInfo(info limits.TenantInfo) *admin.TenantInfo {
+	return &admin.TenantInfo{
+		Id:      info.ID,
+		Name:    info.Name,
+		Limits:  toProtoLimits(info.Limits),
+		Metrics: toProtoMetrics(info.Metrics),
+		Enforcement: &admin.EnforcementConfig{
+			Enabled:          info.Enforcement.Enabled,
+			BurstPctOverride: info.Enforcement.BurstPctOverride,
+		},
+	}
+}
@@ -0,0 +1,522 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Aggregator granularities, matching the time ranges served by /api/aggregated/{timeRange}
+const (
+	granularity15m = "15m"
+	granularity1h  = "1h"
+	granularity24h = "24h"
+	granularity1w  = "1w"
+)
+
+var aggregatorGranularities = []string{granularity15m, granularity1h, granularity24h, granularity1w}
+
+const (
+	// defaultAggregatorSnapshotInterval is how often buckets are persisted when no interval is configured
+	defaultAggregatorSnapshotInterval = time.Minute
+	// aggregatorSnapshotCompactAfter is how long a replica may stop writing before its snapshot is folded into the
+	// compacted one. Replicas without a stable identity write under a new name after every restart.
+	aggregatorSnapshotCompactAfter = time.Hour
+	// aggregatorCompactedReplica names the snapshot holding the buckets of every replica that stopped writing
+	aggregatorCompactedReplica = "_compacted"
+	// aggregatorCompactAttempts bounds the retries when another replica writes during a compaction
+	aggregatorCompactAttempts = 3
+	// aggregatorRedisKey is the Redis hash holding one snapshot per replica
+	aggregatorRedisKey = "rls:aggregator:snapshots"
+)
+
+// aggregatorRetention is how long buckets of each granularity are kept, as in TimeAggregator.cleanupOldBuckets
+var aggregatorRetention = map[string]time.Duration{
+	granularity15m: 24 * time.Hour,
+	granularity1h:  7 * 24 * time.Hour,
+	granularity24h: 30 * 24 * time.Hour,
+	granularity1w:  52 * 7 * 24 * time.Hour,
+}
+
+// AggregatorSnapshot is a point-in-time copy of one replica's TimeAggregator buckets
+type AggregatorSnapshot struct {
+	Replica string
+	TakenAt time.Time
+	Global  map[string]map[string]*TimeBucket            // granularity -> bucket key -> bucket
+	Tenants map[string]map[string]map[string]*TimeBucket // granularity -> tenant -> bucket key -> bucket
+}
+
+// AggregatorPersistence stores aggregator snapshots so history survives restarts
+type AggregatorPersistence interface {
+	// Save stores the snapshot of a replica, replacing its previous one
+	Save(ctx context.Context, snapshot *AggregatorSnapshot) error
+	// LoadAll returns the latest snapshot of every replica
+	LoadAll(ctx context.Context) ([]*AggregatorSnapshot, error)
+}
+
+// NewAggregatorPersistence creates the persistence backend named by kind: "file", "redis" or "" for none
+func NewAggregatorPersistence(kind, path, redisAddress string) (AggregatorPersistence, error) {
+	switch kind {
+	case "":
+		return nil, nil
+	case "file":
+		if path == "" {
+			return nil, errors.New("file aggregator persistence requires a snapshot path")
+		}
+		return &FileAggregatorPersistence{path: path}, nil
+	case "redis":
+		return &RedisAggregatorPersistence{client: redis.NewClient(&redis.Options{Addr: redisAddress}), key: aggregatorRedisKey}, nil
+	default:
+		return nil, fmt.Errorf("unknown aggregator persistence backend: %s", kind)
+	}
+}
+
+// FileAggregatorPersistence keeps a single gzip-compressed gob snapshot on local disk
+type FileAggregatorPersistence struct {
+	path string
+}
+
+// Save implements AggregatorPersistence. The file is replaced atomically.
+func (f *FileAggregatorPersistence) Save(ctx context.Context, snapshot *AggregatorSnapshot) error {
+	data, err := encodeAggregatorSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot file: %w", err)
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// LoadAll implements AggregatorPersistence
+func (f *FileAggregatorPersistence) LoadAll(ctx context.Context) ([]*AggregatorSnapshot, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot file: %w", err)
+	}
+	snapshot, err := decodeAggregatorSnapshot(data)
+	if err != nil {
+		return nil, err
+	}
+	return []*AggregatorSnapshot{snapshot}, nil
+}
+
+// RedisAggregatorPersistence keeps one snapshot per replica in a Redis hash, so every replica can merge the others
+type RedisAggregatorPersistence struct {
+	client *redis.Client
+	key    string
+}
+
+// Save implements AggregatorPersistence
+func (r *RedisAggregatorPersistence) Save(ctx context.Context, snapshot *AggregatorSnapshot) error {
+	data, err := encodeAggregatorSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+	return r.client.HSet(ctx, r.key, snapshot.Replica, data).Err()
+}
+
+// LoadAll implements AggregatorPersistence. Snapshots of replicas that have not written for
+// aggregatorSnapshotCompactAfter are merged into the compacted snapshot and deleted, so the hash holds one entry per
+// live replica plus one for all history of the others, whatever the replicas were named.
+func (r *RedisAggregatorPersistence) LoadAll(ctx context.Context) ([]*AggregatorSnapshot, error) {
+	var snapshots []*AggregatorSnapshot
+	compact := func(tx *redis.Tx) error {
+		fields, err := tx.HGetAll(ctx, r.key).Result()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		snapshots = make([]*AggregatorSnapshot, 0, len(fields))
+		var compacted *AggregatorSnapshot
+		var idle []*AggregatorSnapshot
+		var stale []string
+		for replica, data := range fields {
+			snapshot, err := decodeAggregatorSnapshot([]byte(data))
+			switch {
+			case err != nil:
+				stale = append(stale, replica)
+			case replica == aggregatorCompactedReplica:
+				compacted = snapshot
+			case now.Sub(snapshot.TakenAt) > aggregatorSnapshotCompactAfter:
+				stale = append(stale, replica)
+				idle = append(idle, snapshot)
+			default:
+				snapshots = append(snapshots, snapshot)
+			}
+		}
+		if len(stale) == 0 {
+			if compacted != nil {
+				snapshots = append(snapshots, compacted)
+			}
+			return nil
+		}
+
+		if compacted == nil {
+			compacted = newAggregatorSnapshot(aggregatorCompactedReplica, now)
+		}
+		for _, snapshot := range idle {
+			mergeAggregatorSnapshot(compacted, snapshot)
+		}
+		compacted.TakenAt = now
+		pruneAggregatorSnapshot(compacted, now)
+		data, err := encodeAggregatorSnapshot(compacted)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, r.key, aggregatorCompactedReplica, data)
+			pipe.HDel(ctx, r.key, stale...)
+			return nil
+		}); err != nil {
+			return err
+		}
+		snapshots = append(snapshots, compacted)
+		return nil
+	}
+
+	// The hash is watched so a snapshot saved during the compaction is never deleted unmerged
+	var err error
+	for attempt := 0; attempt < aggregatorCompactAttempts; attempt++ {
+		if err = r.client.Watch(ctx, compact, r.key); !errors.Is(err, redis.TxFailedErr) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// newAggregatorSnapshot returns an empty snapshot
+func newAggregatorSnapshot(replica string, takenAt time.Time) *AggregatorSnapshot {
+	return &AggregatorSnapshot{
+		Replica: replica,
+		TakenAt: takenAt,
+		Global:  make(map[string]map[string]*TimeBucket, len(aggregatorGranularities)),
+		Tenants: make(map[string]map[string]map[string]*TimeBucket, len(aggregatorGranularities)),
+	}
+}
+
+// mergeAggregatorSnapshot adds the buckets of src into dst
+func mergeAggregatorSnapshot(dst, src *AggregatorSnapshot) {
+	for _, granularity := range aggregatorGranularities {
+		if dst.Global[granularity] == nil {
+			dst.Global[granularity] = make(map[string]*TimeBucket)
+		}
+		mergeBucketMap(dst.Global[granularity], src.Global[granularity])
+
+		if dst.Tenants[granularity] == nil {
+			dst.Tenants[granularity] = make(map[string]map[string]*TimeBucket)
+		}
+		tenants := dst.Tenants[granularity]
+		for tenantID, buckets := range src.Tenants[granularity] {
+			if tenants[tenantID] == nil {
+				tenants[tenantID] = make(map[string]*TimeBucket, len(buckets))
+			}
+			mergeBucketMap(tenants[tenantID], buckets)
+		}
+	}
+}
+
+// pruneAggregatorSnapshot drops the buckets that ended before their granularity's retention
+func pruneAggregatorSnapshot(snapshot *AggregatorSnapshot, now time.Time) {
+	for _, granularity := range aggregatorGranularities {
+		cutoff := now.Add(-aggregatorRetention[granularity])
+		pruneBucketMap(snapshot.Global[granularity], cutoff)
+		for tenantID, buckets := range snapshot.Tenants[granularity] {
+			pruneBucketMap(buckets, cutoff)
+			if len(buckets) == 0 {
+				delete(snapshot.Tenants[granularity], tenantID)
+			}
+		}
+	}
+}
+
+func pruneBucketMap(buckets map[string]*TimeBucket, cutoff time.Time) {
+	for key, bucket := range buckets {
+		if bucket.EndTime.Before(cutoff) {
+			delete(buckets, key)
+		}
+	}
+}
+
+func encodeAggregatorSnapshot(snapshot *AggregatorSnapshot) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := gob.NewEncoder(zw).Encode(snapshot); err != nil {
+		return nil, fmt.Errorf("failed to encode aggregator snapshot: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress aggregator snapshot: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeAggregatorSnapshot(data []byte) (*AggregatorSnapshot, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress aggregator snapshot: %w", err)
+	}
+	defer zr.Close()
+
+	var snapshot AggregatorSnapshot
+	if err := gob.NewDecoder(zr).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode aggregator snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// globalMap returns the aggregator's own global buckets for a granularity. Callers must hold ta.mu.
+func (ta *TimeAggregator) globalMap(granularity string) map[string]*TimeBucket {
+	switch granularity {
+	case granularity15m:
+		return ta.buckets15min
+	case granularity24h:
+		return ta.buckets24h
+	case granularity1w:
+		return ta.buckets1w
+	default:
+		return ta.buckets1h
+	}
+}
+
+// tenantMap returns the aggregator's own per-tenant buckets for a granularity. Callers must hold ta.mu.
+func (ta *TimeAggregator) tenantMap(granularity string) map[string]map[string]*TimeBucket {
+	switch granularity {
+	case granularity15m:
+		return ta.tenantBuckets15min
+	case granularity24h:
+		return ta.tenantBuckets24h
+	case granularity1w:
+		return ta.tenantBuckets1w
+	default:
+		return ta.tenantBuckets1h
+	}
+}
+
+// globalView returns the global buckets merged with those of peer replicas. Callers must hold ta.mu.
+func (ta *TimeAggregator) globalView(granularity string) map[string]*TimeBucket {
+	own := ta.globalMap(granularity)
+	if len(ta.peers) == 0 {
+		return own
+	}
+	merged := make(map[string]*TimeBucket, len(own))
+	mergeBucketMap(merged, own)
+	for _, peer := range ta.peers {
+		mergeBucketMap(merged, peer.Global[granularity])
+	}
+	return merged
+}
+
+// tenantView returns a tenant's buckets merged with those of peer replicas. Callers must hold ta.mu.
+func (ta *TimeAggregator) tenantView(granularity, tenantID string) map[string]*TimeBucket {
+	own := ta.tenantMap(granularity)[tenantID]
+	if len(ta.peers) == 0 {
+		return own
+	}
+	merged := make(map[string]*TimeBucket, len(own))
+	mergeBucketMap(merged, own)
+	for _, peer := range ta.peers {
+		mergeBucketMap(merged, peer.Tenants[granularity][tenantID])
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// Snapshot returns a deep copy of the aggregator's own buckets
+func (ta *TimeAggregator) Snapshot(replica string) *AggregatorSnapshot {
+	ta.mu.RLock()
+	defer ta.mu.RUnlock()
+
+	snapshot := newAggregatorSnapshot(replica, time.Now())
+	for _, granularity := range aggregatorGranularities {
+		global := make(map[string]*TimeBucket)
+		mergeBucketMap(global, ta.globalMap(granularity))
+		snapshot.Global[granularity] = global
+
+		tenants := make(map[string]map[string]*TimeBucket)
+		for tenantID, buckets := range ta.tenantMap(granularity) {
+			copied := make(map[string]*TimeBucket, len(buckets))
+			mergeBucketMap(copied, buckets)
+			tenants[tenantID] = copied
+		}
+		snapshot.Tenants[granularity] = tenants
+	}
+	return snapshot
+}
+
+// Restore adds a snapshot of this replica's earlier buckets to the aggregator's own buckets
+func (ta *TimeAggregator) Restore(snapshot *AggregatorSnapshot) {
+	ta.mu.Lock()
+	defer ta.mu.Unlock()
+
+	for _, granularity := range aggregatorGranularities {
+		mergeBucketMap(ta.globalMap(granularity), snapshot.Global[granularity])
+
+		tenants := ta.tenantMap(granularity)
+		for tenantID, buckets := range snapshot.Tenants[granularity] {
+			if tenants[tenantID] == nil {
+				tenants[tenantID] = make(map[string]*TimeBucket, len(buckets))
+			}
+			mergeBucketMap(tenants[tenantID], buckets)
+		}
+	}
+	ta.cleanupOldBuckets()
+}
+
+// SetPeerSnapshots replaces the buckets of other replicas merged into every read
+func (ta *TimeAggregator) SetPeerSnapshots(peers []*AggregatorSnapshot) {
+	byReplica := make(map[string]*AggregatorSnapshot, len(peers))
+	for _, peer := range peers {
+		byReplica[peer.Replica] = peer
+	}
+
+	ta.mu.Lock()
+	ta.peers = byReplica
+	ta.mu.Unlock()
+}
+
+// mergeBucketMap adds every bucket of src into dst, copying so dst never aliases src
+func mergeBucketMap(dst, src map[string]*TimeBucket) {
+	for key, bucket := range src {
+		if existing, ok := dst[key]; ok {
+			merged := mergeBuckets(*existing, *bucket)
+			dst[key] = &merged
+			continue
+		}
+		copied := *bucket
+		dst[key] = &copied
+	}
+}
+
+// mergeBuckets combines two buckets covering the same period
+func mergeBuckets(a, b TimeBucket) TimeBucket {
+	merged := a
+	merged.TotalRequests += b.TotalRequests
+	merged.AllowedRequests += b.AllowedRequests
+	merged.DeniedRequests += b.DeniedRequests
+	merged.TotalSeries += b.TotalSeries
+	merged.TotalLabels += b.TotalLabels
+	merged.Violations += b.Violations
+	merged.ResponseTimeCount += b.ResponseTimeCount
+
+	switch {
+	case a.ResponseTimeCount == 0:
+		merged.MinResponseTime, merged.MaxResponseTime, merged.AvgResponseTime = b.MinResponseTime, b.MaxResponseTime, b.AvgResponseTime
+	case b.ResponseTimeCount > 0:
+		if b.MinResponseTime < merged.MinResponseTime {
+			merged.MinResponseTime = b.MinResponseTime
+		}
+		if b.MaxResponseTime > merged.MaxResponseTime {
+			merged.MaxResponseTime = b.MaxResponseTime
+		}
+		merged.AvgResponseTime = (a.AvgResponseTime*float64(a.ResponseTimeCount) + b.AvgResponseTime*float64(b.ResponseTimeCount)) / float64(merged.ResponseTimeCount)
+	}
+	return merged
+}
+
+// restoreAggregator loads persisted snapshots: this replica's own history is restored into the
+// aggregator, every other replica's is merged into reads. Only a replica with a stable AggregatorReplicaID finds its
+// own snapshot again after a restart; the history of the others is merged into reads until it is compacted.
+func (rls *RLS) restoreAggregator() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	snapshots, err := rls.aggregatorPersistence.LoadAll(ctx)
+	if err != nil {
+		rls.logger.Error().Err(err).Msg("RLS: failed to restore aggregated analytics - starting empty")
+		return
+	}
+
+	peers := make([]*AggregatorSnapshot, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		if snapshot.Replica == rls.config.AggregatorReplicaID || rls.config.AggregatorPersistence == "file" {
+			rls.timeAggregator.Restore(snapshot)
+			continue
+		}
+		peers = append(peers, snapshot)
+	}
+	rls.timeAggregator.SetPeerSnapshots(peers)
+
+	rls.logger.Info().
+		Str("replica", rls.config.AggregatorReplicaID).
+		Int("snapshots", len(snapshots)).
+		Int("peers", len(peers)).
+		Msg("RLS: restored aggregated analytics")
+}
+
+// PersistAggregator saves this replica's buckets and refreshes the merged buckets of its peers
+func (rls *RLS) PersistAggregator(ctx context.Context) error {
+	if rls.aggregatorPersistence == nil {
+		return nil
+	}
+
+	if err := rls.aggregatorPersistence.Save(ctx, rls.timeAggregator.Snapshot(rls.config.AggregatorReplicaID)); err != nil {
+		return fmt.Errorf("failed to save aggregator snapshot: %w", err)
+	}
+	if rls.config.AggregatorPersistence == "file" {
+		return nil
+	}
+
+	snapshots, err := rls.aggregatorPersistence.LoadAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load peer aggregator snapshots: %w", err)
+	}
+	peers := snapshots[:0]
+	for _, snapshot := range snapshots {
+		if snapshot.Replica != rls.config.AggregatorReplicaID {
+			peers = append(peers, snapshot)
+		}
+	}
+	rls.timeAggregator.SetPeerSnapshots(peers)
+	return nil
+}
+
+// startAggregatorPersistenceLoop periodically persists the aggregator
+func (rls *RLS) startAggregatorPersistenceLoop() {
+	interval := rls.config.AggregatorSnapshotInterval
+	if interval <= 0 {
+		interval = defaultAggregatorSnapshotInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		if err := rls.PersistAggregator(ctx); err != nil {
+			rls.logger.Warn().Err(err).Msg("RLS: failed to persist aggregated analytics")
+		}
+		cancel()
+	}
+}
+
+// AggregatesIncludePeers reports whether time-aggregated reads already include other replicas' buckets
+func (rls *RLS) AggregatesIncludePeers() bool {
+	return rls.aggregatorPersistence != nil && rls.config.AggregatorPersistence == "redis"
+}
@@ -0,0 +1,437 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/limits"
+)
+
+// Alert rules evaluated per tenant
+const (
+	AlertRuleSeriesUtilization = "series_utilization"  // active series against MaxSeriesPerRequest
+	AlertRuleSeriesGrowth      = "series_growth"       // active series growth over a window
+	AlertRuleMetricHotSpot     = "metric_hot_spot"     // a single metric against MaxSeriesPerMetric
+	AlertRuleSustainedDenyRate = "sustained_deny_rate" // deny rate held above a threshold
+)
+
+// Alert severities
+const (
+	AlertSeverityWarning  = "warning"
+	AlertSeverityCritical = "critical"
+)
+
+// AlertRulesConfig holds the thresholds of the cardinality alert rules
+type AlertRulesConfig struct {
+	EvaluationInterval           time.Duration // 0 disables evaluation
+	SeriesUtilizationWarningPct  float64       // warn when active series reach this share of MaxSeriesPerRequest
+	SeriesUtilizationCriticalPct float64       // critical when active series reach this share of MaxSeriesPerRequest
+	SeriesGrowthPct              float64       // alert when active series grow by this percentage within SeriesGrowthWindow
+	SeriesGrowthWindow           time.Duration
+	SeriesGrowthMinSeries        int64         // ignore growth of tenants below this many series
+	MetricHotSpotPct             float64       // alert when one metric reaches this share of MaxSeriesPerMetric
+	DenyRatePct                  float64       // alert when the 15m deny rate stays above this percentage...
+	DenyRateFor                  time.Duration // ...for at least this long
+	DenyRateMinRequests          int64         // ignore tenants with fewer requests in the window
+	ResolvedRetention            time.Duration // how long resolved alerts stay visible
+}
+
+// DefaultAlertRulesConfig returns the default alert thresholds
+func DefaultAlertRulesConfig() AlertRulesConfig {
+	return AlertRulesConfig{
+		EvaluationInterval:           30 * time.Second,
+		SeriesUtilizationWarningPct:  80,
+		SeriesUtilizationCriticalPct: 95,
+		SeriesGrowthPct:              50,
+		SeriesGrowthWindow:           time.Hour,
+		SeriesGrowthMinSeries:        1000,
+		MetricHotSpotPct:             90,
+		DenyRatePct:                  10,
+		DenyRateFor:                  10 * time.Minute,
+		DenyRateMinRequests:          100,
+		ResolvedRetention:            time.Hour,
+	}
+}
+
+// alertCandidate is a rule condition that currently holds
+type alertCandidate struct {
+	rule      string
+	tenantID  string
+	metric    string
+	severity  string
+	message   string
+	value     int64
+	threshold int64
+	holdFor   time.Duration // the condition must hold this long before the alert fires
+}
+
+type alertState struct {
+	alert        limits.CardinalityAlert
+	pendingSince time.Time
+	firing       bool
+}
+
+type seriesSample struct {
+	at     time.Time
+	series int64
+}
+
+// AlertEngine tracks the firing and resolved state of cardinality alerts.
+// Alerts are identified by rule, tenant and metric, so repeated evaluations update a single alert.
+type AlertEngine struct {
+	mu            sync.RWMutex
+	config        AlertRulesConfig
+	states        map[string]*alertState
+	seriesHistory map[string][]seriesSample
+}
+
+// NewAlertEngine creates an alert engine with the given thresholds
+func NewAlertEngine(config AlertRulesConfig) *AlertEngine {
+	return &AlertEngine{
+		config:        config,
+		states:        make(map[string]*alertState),
+		seriesHistory: make(map[string][]seriesSample),
+	}
+}
+
+// alertID derives a stable ID so replicas and evaluations agree on the same alert
+func alertID(rule, tenantID, metric string) string {
+	h := fnv.New64a()
+	h.Write([]byte(rule + "\x00" + tenantID + "\x00" + metric))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// apply records one evaluation round and returns the alerts that started firing and those that resolved
+func (e *AlertEngine) apply(now time.Time, candidates []alertCandidate) (fired, resolved []limits.CardinalityAlert) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	seen := make(map[string]bool, len(candidates))
+	for _, c := range candidates {
+		id := alertID(c.rule, c.tenantID, c.metric)
+		seen[id] = true
+
+		state, ok := e.states[id]
+		if !ok || state.alert.Resolved {
+			state = &alertState{pendingSince: now}
+			e.states[id] = state
+		}
+		state.alert.ID = id
+		state.alert.Rule = c.rule
+		state.alert.TenantID = c.tenantID
+		state.alert.Metric = c.metric
+		state.alert.Severity = c.severity
+		state.alert.Message = c.message
+		state.alert.Value = c.value
+		state.alert.Threshold = c.threshold
+		state.alert.Timestamp = now
+
+		if !state.firing && now.Sub(state.pendingSince) >= c.holdFor {
+			state.firing = true
+			state.alert.StartsAt = now
+			fired = append(fired, state.alert)
+		}
+	}
+
+	for id, state := range e.states {
+		if seen[id] {
+			continue
+		}
+		switch {
+		case !state.firing:
+			// The condition cleared before the alert fired
+			delete(e.states, id)
+		case !state.alert.Resolved:
+			endsAt := now
+			state.alert.Resolved = true
+			state.alert.EndsAt = &endsAt
+			state.alert.Timestamp = now
+			resolved = append(resolved, state.alert)
+		case now.Sub(*state.alert.EndsAt) > e.config.ResolvedRetention:
+			delete(e.states, id)
+		}
+	}
+	return fired, resolved
+}
+
+// observeSeries records a tenant's active series and returns the oldest sample within the growth window
+func (e *AlertEngine) observeSeries(tenantID string, now time.Time, series int64) (seriesSample, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	cutoff := now.Add(-e.config.SeriesGrowthWindow)
+	history := e.seriesHistory[tenantID]
+	first := 0
+	for first < len(history) && history[first].at.Before(cutoff) {
+		first++
+	}
+	history = append(history[first:], seriesSample{at: now, series: series})
+	e.seriesHistory[tenantID] = history
+
+	baseline := history[0]
+	// Wait for at least half a window of history before judging growth
+	if now.Sub(baseline.at) < e.config.SeriesGrowthWindow/2 {
+		return seriesSample{}, false
+	}
+	return baseline, true
+}
+
+// forgetTenants drops the series history of tenants that no longer exist
+func (e *AlertEngine) forgetTenants(active map[string]bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for tenantID := range e.seriesHistory {
+		if !active[tenantID] {
+			delete(e.seriesHistory, tenantID)
+		}
+	}
+}
+
+// Alerts returns firing and recently resolved alerts: firing first, then by severity and start time
+func (e *AlertEngine) Alerts() []limits.CardinalityAlert {
+	e.mu.RLock()
+	alerts := make([]limits.CardinalityAlert, 0, len(e.states))
+	for _, state := range e.states {
+		if state.firing {
+			alerts = append(alerts, state.alert)
+		}
+	}
+	e.mu.RUnlock()
+
+	sort.Slice(alerts, func(i, j int) bool {
+		a, b := alerts[i], alerts[j]
+		if a.Resolved != b.Resolved {
+			return !a.Resolved
+		}
+		if a.Severity != b.Severity {
+			return a.Severity == AlertSeverityCritical
+		}
+		if !a.StartsAt.Equal(b.StartsAt) {
+			return a.StartsAt.After(b.StartsAt)
+		}
+		return a.ID < b.ID
+	})
+	return alerts
+}
+
+// startAlertEvaluationLoop evaluates the alert rules until the process exits
+func (rls *RLS) startAlertEvaluationLoop() {
+	ticker := time.NewTicker(rls.alerts.config.EvaluationInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		rls.EvaluateAlerts(time.Now())
+	}
+}
+
+// EvaluateAlerts runs every alert rule against every tenant and updates the alert state
+func (rls *RLS) EvaluateAlerts(now time.Time) (fired, resolved []limits.CardinalityAlert) {
+	config := rls.alerts.config
+
+	rls.tenantsMu.RLock()
+	tenantLimits := make(map[string]limits.TenantLimits, len(rls.tenants))
+	for id, tenant := range rls.tenants {
+		tenantLimits[id] = tenant.Info.Limits
+	}
+	rls.tenantsMu.RUnlock()
+
+	var candidates []alertCandidate
+	active := make(map[string]bool, len(tenantLimits))
+	for tenantID, tl := range tenantLimits {
+		active[tenantID] = true
+		series := rls.getTenantGlobalSeriesCount(context.Background(), tenantID)
+		rls.observeSeriesLevel(tenantID, series, int64(tl.MaxSeriesPerRequest))
+
+		// Series utilization against the per-tenant series limit
+		if tl.MaxSeriesPerRequest > 0 {
+			limit := int64(tl.MaxSeriesPerRequest)
+			pct := float64(series) / float64(limit) * 100.0
+			severity, thresholdPct := "", 0.0
+			switch {
+			case config.SeriesUtilizationCriticalPct > 0 && pct >= config.SeriesUtilizationCriticalPct:
+				severity, thresholdPct = AlertSeverityCritical, config.SeriesUtilizationCriticalPct
+			case config.SeriesUtilizationWarningPct > 0 && pct >= config.SeriesUtilizationWarningPct:
+				severity, thresholdPct = AlertSeverityWarning, config.SeriesUtilizationWarningPct
+			}
+			if severity != "" {
+				candidates = append(candidates, alertCandidate{
+					rule:      AlertRuleSeriesUtilization,
+					tenantID:  tenantID,
+					metric:    "active_series",
+					severity:  severity,
+					message:   fmt.Sprintf("Tenant %s is at %.1f%% of its series limit (%d of %d)", tenantID, pct, series, limit),
+					value:     series,
+					threshold: int64(float64(limit) * thresholdPct / 100.0),
+				})
+			}
+		}
+
+		// Series growth within the growth window
+		if config.SeriesGrowthPct > 0 && config.SeriesGrowthWindow > 0 {
+			if baseline, ok := rls.alerts.observeSeries(tenantID, now, series); ok &&
+				baseline.series >= config.SeriesGrowthMinSeries && baseline.series > 0 {
+				growth := float64(series-baseline.series) / float64(baseline.series) * 100.0
+				if growth >= config.SeriesGrowthPct {
+					candidates = append(candidates, alertCandidate{
+						rule:     AlertRuleSeriesGrowth,
+						tenantID: tenantID,
+						metric:   "active_series",
+						severity: AlertSeverityWarning,
+						message: fmt.Sprintf("Tenant %s active series grew %.1f%% (%d to %d) in %s",
+							tenantID, growth, baseline.series, series, now.Sub(baseline.at).Round(time.Minute)),
+						value:     series - baseline.series,
+						threshold: int64(float64(baseline.series) * config.SeriesGrowthPct / 100.0),
+					})
+				}
+			}
+		}
+
+		// Per-metric hot spots against the per-metric series limit
+		if config.MetricHotSpotPct > 0 && tl.MaxSeriesPerMetric > 0 {
+			limit := int64(tl.MaxSeriesPerMetric)
+			for metric, metricSeries := range rls.getTenantMetricSeriesCount(context.Background(), tenantID, nil) {
+				pct := float64(metricSeries) / float64(limit) * 100.0
+				if pct < config.MetricHotSpotPct {
+					continue
+				}
+				severity := AlertSeverityWarning
+				if metricSeries >= limit {
+					severity = AlertSeverityCritical
+				}
+				candidates = append(candidates, alertCandidate{
+					rule:      AlertRuleMetricHotSpot,
+					tenantID:  tenantID,
+					metric:    metric,
+					severity:  severity,
+					message:   fmt.Sprintf("Metric %s of tenant %s is at %.1f%% of the per-metric series limit (%d of %d)", metric, tenantID, pct, metricSeries, limit),
+					value:     metricSeries,
+					threshold: int64(float64(limit) * config.MetricHotSpotPct / 100.0),
+				})
+			}
+		}
+
+		// Deny rate that holds for DenyRateFor
+		if config.DenyRatePct > 0 {
+			data := rls.timeAggregator.GetTenantAggregatedData(tenantID, "15m")
+			total, _ := data["total_requests"].(int64)
+			denyRate, _ := data["deny_rate"].(float64)
+			if total >= config.DenyRateMinRequests && total > 0 && denyRate >= config.DenyRatePct {
+				candidates = append(candidates, alertCandidate{
+					rule:      AlertRuleSustainedDenyRate,
+					tenantID:  tenantID,
+					metric:    "deny_rate",
+					severity:  AlertSeverityWarning,
+					message:   fmt.Sprintf("Tenant %s has denied %.1f%% of %d requests over the last 15m", tenantID, denyRate, total),
+					value:     int64(denyRate + 0.5),
+					threshold: int64(config.DenyRatePct),
+					holdFor:   config.DenyRateFor,
+				})
+			}
+		}
+	}
+	rls.alerts.forgetTenants(active)
+
+	fired, resolved = rls.alerts.apply(now, candidates)
+	for _, alert := range fired {
+		rls.logger.Warn().Str("alert_id", alert.ID).Str("rule", alert.Rule).Str("tenant_id", alert.TenantID).
+			Str("severity", alert.Severity).Msg(alert.Message)
+	}
+	for _, alert := range resolved {
+		rls.logger.Info().Str("alert_id", alert.ID).Str("rule", alert.Rule).Str("tenant_id", alert.TenantID).
+			Msg("cardinality alert resolved")
+	}
+	rls.notifyAlerts(fired, resolved)
+	return fired, resolved
+}
+
+// AlertmanagerAlert is a single alert in Alertmanager's webhook payload
+type AlertmanagerAlert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// AlertmanagerWebhook is the payload Alertmanager sends to webhook receivers (version 4)
+type AlertmanagerWebhook struct {
+	Version           string              `json:"version"`
+	GroupKey          string              `json:"groupKey"`
+	TruncatedAlerts   int                 `json:"truncatedAlerts"`
+	Status            string              `json:"status"`
+	Receiver          string              `json:"receiver"`
+	GroupLabels       map[string]string   `json:"groupLabels"`
+	CommonLabels      map[string]string   `json:"commonLabels"`
+	CommonAnnotations map[string]string   `json:"commonAnnotations"`
+	ExternalURL       string              `json:"externalURL"`
+	Alerts            []AlertmanagerAlert `json:"alerts"`
+}
+
+// alertNames maps rules to Alertmanager alert names
+var alertNames = map[string]string{
+	AlertRuleSeriesUtilization: "EdgeTenantSeriesUtilizationHigh",
+	AlertRuleSeriesGrowth:      "EdgeTenantSeriesGrowthHigh",
+	AlertRuleMetricHotSpot:     "EdgeTenantMetricHotSpot",
+	AlertRuleSustainedDenyRate: "EdgeTenantDenyRateHigh",
+}
+
+// ToAlertmanagerAlert converts a cardinality alert to Alertmanager's alert format
+func ToAlertmanagerAlert(alert limits.CardinalityAlert, generatorURL string) AlertmanagerAlert {
+	name := alertNames[alert.Rule]
+	if name == "" {
+		name = "EdgeCardinalityAlert"
+	}
+	out := AlertmanagerAlert{
+		Status: "firing",
+		Labels: map[string]string{
+			"alertname": name,
+			"severity":  alert.Severity,
+			"tenant":    alert.TenantID,
+			"metric":    alert.Metric,
+			"rule":      alert.Rule,
+			"source":    "mimir-edge-enforcement",
+		},
+		Annotations: map[string]string{
+			"summary":   alert.Message,
+			"value":     fmt.Sprintf("%d", alert.Value),
+			"threshold": fmt.Sprintf("%d", alert.Threshold),
+		},
+		StartsAt:     alert.StartsAt,
+		GeneratorURL: generatorURL,
+		Fingerprint:  alert.ID,
+	}
+	if alert.Resolved && alert.EndsAt != nil {
+		out.Status = "resolved"
+		out.EndsAt = *alert.EndsAt
+	}
+	return out
+}
+
+// NewAlertmanagerWebhook builds an Alertmanager webhook payload for the given alerts
+func NewAlertmanagerWebhook(alerts []limits.CardinalityAlert, receiver, externalURL string) AlertmanagerWebhook {
+	payload := AlertmanagerWebhook{
+		Version:           "4",
+		GroupKey:          `{}:{source="mimir-edge-enforcement"}`,
+		Status:            "resolved",
+		Receiver:          receiver,
+		GroupLabels:       map[string]string{"source": "mimir-edge-enforcement"},
+		CommonLabels:      map[string]string{"source": "mimir-edge-enforcement"},
+		CommonAnnotations: map[string]string{},
+		ExternalURL:       externalURL,
+		Alerts:            make([]AlertmanagerAlert, 0, len(alerts)),
+	}
+	for _, alert := range alerts {
+		converted := ToAlertmanagerAlert(alert, externalURL)
+		if converted.Status == "firing" {
+			payload.Status = "firing"
+		}
+		payload.Alerts = append(payload.Alerts, converted)
+	}
+	return payload
+}
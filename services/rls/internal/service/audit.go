@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/limits"
+)
+
+// Audit actions recorded for tenant mutations
+const (
+	AuditActionSetLimits       = "set_limits"
+	AuditActionSetEnforcement  = "set_enforcement"
+	AuditActionSetProfile      = "set_profile"
+	AuditActionSetOverride     = "set_override"
+	AuditActionClearOverride   = "clear_override"
+	AuditActionOverrideExpired = "override_expired"
+	AuditActionRollback        = "rollback"
+	AuditActionDeleteTenant    = "delete_tenant"
+	AuditActionSetPolicy       = "set_policy"
+)
+
+// Audit sources identifying which interface performed a mutation
+const (
+	AuditSourceAdminAPI      = "admin_api"
+	AuditSourceGRPCAdmin     = "grpc_admin"
+	AuditSourceOverridesSync = "overrides_sync"
+	AuditSourceSystem        = "system"
+)
+
+// ErrRevisionNotFound is returned when rolling back to a revision that is not in the tenant's history
+var ErrRevisionNotFound = fmt.Errorf("revision not found")
+
+// ChangeContext identifies who performed a mutation and through which interface
+type ChangeContext struct {
+	Actor  string
+	Source string
+}
+
+// snapshotTenantConfig copies the mutable configuration of a tenant
+func snapshotTenantConfig(tenant *TenantState) *limits.TenantConfigSnapshot {
+	overrides := tenant.Overrides
+	return &limits.TenantConfigSnapshot{
+		Limits:            tenant.BaseLimits,
+		Enforcement:       tenant.Info.Enforcement,
+		TemporaryOverride: tenant.Info.TemporaryOverride,
+		Profile:           tenant.Info.Profile,
+		Overrides:         &overrides,
+		Policy:            tenant.Info.Policy,
+	}
+}
+
+// newAuditEvent describes a tenant mutation; the store assigns its revision
+func newAuditEvent(tenantID, action string, change ChangeContext, before *limits.TenantConfigSnapshot, tenant *TenantState) *limits.AuditEvent {
+	after := *snapshotTenantConfig(tenant)
+	return &limits.AuditEvent{
+		TenantID:  tenantID,
+		Action:    action,
+		Actor:     change.Actor,
+		Source:    change.Source,
+		Timestamp: time.Now(),
+		Before:    before,
+		After:     after,
+		Changes:   limits.DiffTenantConfig(before, after),
+	}
+}
+
+// recordAuditEvent appends an audit event describing a tenant mutation to the store.
+// Failures are logged but never fail the mutation itself.
+func (rls *RLS) recordAuditEvent(tenantID, action string, change ChangeContext, before *limits.TenantConfigSnapshot, tenant *TenantState) {
+	event := newAuditEvent(tenantID, action, change, before, tenant)
+	rls.notifyLimitsChanged(event)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := rls.store.AppendAuditEvent(ctx, event); err != nil {
+		rls.logger.Error().Err(err).Str("tenant_id", tenantID).Str("action", action).Msg("RLS: failed to record audit event")
+		return
+	}
+
+	rls.logger.Info().
+		Str("tenant_id", tenantID).
+		Int64("revision", event.Revision).
+		Str("action", action).
+		Str("actor", change.Actor).
+		Str("source", change.Source).
+		Int("changed_fields", len(event.Changes)).
+		Msg("RLS: recorded audit event")
+}
+
+// GetTenantHistory returns the audit history of a tenant, newest first
+func (rls *RLS) GetTenantHistory(tenantID string, limit int) ([]limits.AuditEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return rls.store.ListAuditEvents(ctx, tenantID, limit)
+}
+
+// RollbackTenant restores the limits, profile, enforcement and temporary override a tenant had after the given revision
+func (rls *RLS) RollbackTenant(tenantID string, revision int64, change ChangeContext) (*limits.TenantConfigSnapshot, error) {
+	history, err := rls.GetTenantHistory(tenantID, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load history for tenant %s: %w", tenantID, err)
+	}
+
+	var target *limits.AuditEvent
+	for i := range history {
+		if history[i].Revision == revision {
+			target = &history[i]
+			break
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("%w: revision %d of tenant %s", ErrRevisionNotFound, revision, tenantID)
+	}
+
+	rls.tenantsMu.Lock()
+	tenant, exists := rls.tenants[tenantID]
+	var before *limits.TenantConfigSnapshot
+	if exists {
+		before = snapshotTenantConfig(tenant)
+	} else {
+		tenant = &TenantState{
+			Info: limits.TenantInfo{
+				ID:   tenantID,
+				Name: tenantID,
+			},
+			Enforcement: rls.config.DefaultEnforcement,
+		}
+		rls.tenants[tenantID] = tenant
+	}
+
+	if target.After.Overrides != nil {
+		tenant.Overrides = *target.After.Overrides
+	} else {
+		tenant.Overrides = limits.FullOverrides(target.After.Limits)
+	}
+	tenant.Info.Profile = target.After.Profile
+	// The snapshot holds the effective enforcement, which is the tenant's own unless its policy set one
+	tenant.Info.Policy = target.After.Policy
+	if policy := target.After.Policy; policy == nil || policy.Enforcement == nil {
+		tenant.Enforcement = target.After.Enforcement
+	}
+	rls.applyEnforcement(tenant)
+	// Expired overrides are not resurrected by a rollback
+	tenant.Info.TemporaryOverride = nil
+	if target.After.TemporaryOverride.Active(time.Now()) {
+		tenant.Info.TemporaryOverride = target.After.TemporaryOverride
+	}
+	rls.resolveTenantLimits(tenant)
+	rls.updateTenantBuckets(tenantID, tenant, tenant.Info.Limits)
+	write := newTenantWrite(tenantID, AuditActionRollback, change, before, tenant)
+	rls.tenantsMu.Unlock()
+
+	rls.flushTenantWrites("", []tenantWrite{write})
+
+	rls.logger.Info().
+		Str("tenant_id", tenantID).
+		Int64("revision", revision).
+		Str("actor", change.Actor).
+		Msg("RLS: rolled back tenant configuration")
+
+	restored := target.After
+	return &restored, nil
+}
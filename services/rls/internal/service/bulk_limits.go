@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/limits"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/store"
+)
+
+// LimitsSetVersion identifies the last set of tenant limits applied in bulk
+type LimitsSetVersion struct {
+	Version   string                 `json:"version"`
+	AppliedAt time.Time              `json:"applied_at,omitempty"`
+	Tenants   int                    `json:"tenants"`
+	Defaults  *limits.LimitOverrides `json:"defaults,omitempty"` // synced defaults of the set, if it had any
+}
+
+// BulkLimitsResult reports what applying a set of tenant limits changed
+type BulkLimitsResult struct {
+	Version   string `json:"version"`
+	Unchanged bool   `json:"unchanged"` // the set was already applied; nothing was touched
+	Updated   int    `json:"updated"`   // tenants whose limits changed
+	Created   int    `json:"created"`   // tenants that did not exist before
+	Skipped   int    `json:"skipped"`   // tenants whose limits were already the same
+	// PoliciesChanged counts tenants whose edge policy was set or cleared
+	PoliciesChanged int `json:"policies_changed"`
+	// DefaultsChanged is set when the set's defaults differ from the previous ones; every tenant was re-resolved
+	DefaultsChanged bool `json:"defaults_changed,omitempty"`
+}
+
+// SetAllTenantLimits applies the tenant limits layer of many tenants as one versioned set. Every tenant's limits and
+// policy are validated before anything changes. The set is applied in memory under a single lock, so requests are
+// checked either against the old limits or against the new ones, never a mix; the store writes and audit events
+// follow once the lock is released, in one round trip, by the first replica to claim the version.
+// Tenants not in the set keep their limits. A set carrying the version already applied is a no-op, which lets every
+// replica be sent the same set repeatedly.
+// A non-nil policies map is the complete set of edge policies: tenants with a policy not in it lose theirs.
+// Non-nil defaults replace the synced defaults every tenant inherits below its profile, e.g. Mimir's global limits.
+func (rls *RLS) SetAllTenantLimits(version string, tenants map[string]limits.LimitOverrides, defaults *limits.LimitOverrides, policies map[string]*limits.EdgePolicy, change ChangeContext) (BulkLimitsResult, error) {
+	if version == "" {
+		return BulkLimitsResult{}, fmt.Errorf("version is required")
+	}
+	for tenantID, overrides := range tenants {
+		if tenantID == "" {
+			return BulkLimitsResult{}, fmt.Errorf("tenant ID must not be empty")
+		}
+		if err := overrides.Validate(); err != nil {
+			return BulkLimitsResult{}, fmt.Errorf("invalid limits for tenant %s: %w", tenantID, err)
+		}
+	}
+	if defaults != nil {
+		if err := defaults.Validate(); err != nil {
+			return BulkLimitsResult{}, fmt.Errorf("invalid defaults: %w", err)
+		}
+	}
+	for tenantID, policy := range policies {
+		if tenantID == "" {
+			return BulkLimitsResult{}, fmt.Errorf("tenant ID must not be empty")
+		}
+		if err := policy.Validate(); err != nil {
+			return BulkLimitsResult{}, fmt.Errorf("invalid policy for tenant %s: %w", tenantID, err)
+		}
+	}
+
+	rls.tenantsMu.Lock()
+	result := BulkLimitsResult{Version: version}
+	if rls.limitsVersion.Version == version {
+		rls.tenantsMu.Unlock()
+		result.Unchanged = true
+		return result, nil
+	}
+	var writes []tenantWrite
+
+	// 🔧 NEW: New defaults change the limits of every tenant, including those not in the set
+	if defaults != nil && *defaults != rls.syncedDefaults {
+		rls.syncedDefaults = *defaults
+		result.DefaultsChanged = true
+		for tenantID, tenant := range rls.tenants {
+			if _, inSet := tenants[tenantID]; inSet {
+				continue
+			}
+			rls.resolveTenantLimits(tenant)
+			rls.updateTenantBuckets(tenantID, tenant, tenant.Info.Limits)
+		}
+	}
+
+	for tenantID, overrides := range tenants {
+		tenant, exists := rls.tenants[tenantID]
+		switch {
+		case !exists:
+			result.Created++
+		case tenant.Overrides == overrides:
+			result.Skipped++
+			if result.DefaultsChanged {
+				rls.resolveTenantLimits(tenant)
+				rls.updateTenantBuckets(tenantID, tenant, tenant.Info.Limits)
+			}
+			continue
+		default:
+			result.Updated++
+		}
+		writes = append(writes, rls.setTenantLimitsLocked(tenantID, overrides, change))
+	}
+
+	if policies != nil {
+		for tenantID, tenant := range rls.tenants {
+			if _, ok := policies[tenantID]; !ok && tenant.Info.Policy != nil {
+				write, _ := rls.setTenantPolicyLocked(tenantID, nil, change)
+				writes = append(writes, write)
+				result.PoliciesChanged++
+			}
+		}
+		for tenantID, policy := range policies {
+			if write, changed := rls.setTenantPolicyLocked(tenantID, policy, change); changed {
+				writes = append(writes, write)
+				result.PoliciesChanged++
+			}
+		}
+	}
+
+	rls.limitsVersion = LimitsSetVersion{Version: version, AppliedAt: time.Now(), Tenants: len(tenants)}
+	if rls.syncedDefaults != (limits.LimitOverrides{}) {
+		synced := rls.syncedDefaults
+		rls.limitsVersion.Defaults = &synced
+	}
+	rls.tenantsMu.Unlock()
+
+	rls.flushTenantWrites(version, writes)
+
+	rls.logger.Info().
+		Str("version", version).
+		Str("actor", change.Actor).
+		Str("source", change.Source).
+		Int("tenants", len(tenants)).
+		Int("created", result.Created).
+		Int("updated", result.Updated).
+		Int("skipped", result.Skipped).
+		Int("policies_changed", result.PoliciesChanged).
+		Bool("defaults_changed", result.DefaultsChanged).
+		Msg("RLS: applied tenant limits set")
+	return result, nil
+}
+
+// LimitsVersion returns the version of the last set of tenant limits applied in bulk, empty before the first one
+func (rls *RLS) LimitsVersion() LimitsSetVersion {
+	rls.tenantsMu.RLock()
+	defer rls.tenantsMu.RUnlock()
+	return rls.limitsVersion
+}
+
+// tenantWrite is the store write and audit event of a tenant mutation, made once tenantsMu is released
+type tenantWrite struct {
+	tenantID string
+	data     *store.TenantData
+	event    *limits.AuditEvent
+}
+
+// newTenantWrite captures a mutated tenant; the caller holds tenantsMu
+func newTenantWrite(tenantID, action string, change ChangeContext, before *limits.TenantConfigSnapshot, tenant *TenantState) tenantWrite {
+	return tenantWrite{
+		tenantID: tenantID,
+		data:     tenantData(tenant),
+		event:    newAuditEvent(tenantID, action, change, before, tenant),
+	}
+}
+
+// flushTenantWrites persists the tenants and records their audit events in one store round trip each; the caller
+// must not hold tenantsMu. Writes of a limits set version are skipped when another replica already claimed it, since
+// the store is shared. Failures are logged but never fail the mutation itself.
+func (rls *RLS) flushTenantWrites(version string, writes []tenantWrite) {
+	if len(writes) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if version != "" {
+		claimed, err := rls.store.ClaimLimitsVersion(ctx, version)
+		if err != nil {
+			rls.logger.Warn().Err(err).Str("version", version).Msg("RLS: failed to claim limits set version - writing it anyway")
+		} else if !claimed {
+			rls.logger.Debug().Str("version", version).Msg("RLS: limits set version already written by another replica")
+			return
+		}
+	}
+
+	tenants := make(map[string]*store.TenantData, len(writes))
+	events := make([]*limits.AuditEvent, 0, len(writes))
+	for _, write := range writes {
+		// A later write of the same tenant holds its newer state
+		tenants[write.tenantID] = write.data
+		events = append(events, write.event)
+		rls.notifyLimitsChanged(write.event)
+	}
+
+	tenantsErr := rls.store.SetTenants(ctx, tenants)
+	if tenantsErr != nil {
+		rls.logger.Error().Err(tenantsErr).Int("tenants", len(tenants)).Msg("RLS: failed to persist tenants to store")
+	}
+	eventsErr := rls.store.AppendAuditEvents(ctx, events)
+	if eventsErr != nil {
+		rls.logger.Error().Err(eventsErr).Int("events", len(events)).Msg("RLS: failed to record audit events")
+	}
+	if (tenantsErr != nil || eventsErr != nil) && version != "" {
+		if err := rls.store.ReleaseLimitsVersion(ctx, version); err != nil {
+			rls.logger.Error().Err(err).Str("version", version).Msg("RLS: failed to release limits set version")
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/decisionlog"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/limits"
+)
+
+// decisionLogState controls the background decision log writer
+type decisionLogState struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// startDecisionLog starts writing decision records in the background
+func (rls *RLS) startDecisionLog() {
+	if rls.decisionLog == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	rls.decisionLogState.cancel = cancel
+	rls.decisionLogState.done = make(chan struct{})
+	go func() {
+		defer close(rls.decisionLogState.done)
+		rls.decisionLog.Run(ctx)
+	}()
+}
+
+// StopDecisionLog flushes queued decision records and closes the sink
+func (rls *RLS) StopDecisionLog() {
+	if rls.decisionLog == nil || rls.decisionLogState.cancel == nil {
+		return
+	}
+	rls.decisionLogState.cancel()
+	<-rls.decisionLogState.done
+}
+
+// logDecision hands a sampled decision to the decision log without blocking
+func (rls *RLS) logDecision(tenantID string, allowed bool, reason string, samples, bodyBytes int64, requestInfo *limits.RequestInfo, start time.Time, latency time.Duration) {
+	if !rls.decisionLog.Sampled(tenantID, allowed) {
+		return
+	}
+
+	record := decisionlog.Record{
+		Timestamp: start,
+		Replica:   rls.config.ReplicaID,
+		TenantID:  tenantID,
+		Decision:  "allow",
+		Reason:    reason,
+		Limits:    rls.enforcedLimits(tenantID),
+		Observed: decisionlog.Observed{
+			Samples:   samples,
+			BodyBytes: bodyBytes,
+		},
+		LatencyMs: float64(latency.Microseconds()) / 1000,
+	}
+	if !allowed {
+		record.Decision = "deny"
+	}
+	if requestInfo != nil {
+		record.Observed.Series = requestInfo.ObservedSeries
+		record.Observed.Labels = requestInfo.ObservedLabels
+	}
+	rls.decisionLog.Log(record)
+}
+
+// enforcedLimits returns the limits evaluated for a tenant, or nil when enforcement is off
+func (rls *RLS) enforcedLimits(tenantID string) map[string]float64 {
+	rls.tenantsMu.RLock()
+	defer rls.tenantsMu.RUnlock()
+
+	tenant, ok := rls.tenants[tenantID]
+	if !ok || !tenant.Info.Enforcement.Enabled {
+		return nil
+	}
+	enforcement := tenant.Info.Enforcement
+	tenantLimits := tenant.Info.Limits
+
+	evaluated := make(map[string]float64)
+	if enforcement.EnforceSamplesPerSecond && tenantLimits.SamplesPerSecond > 0 {
+		evaluated["samples_per_second"] = tenantLimits.SamplesPerSecond
+	}
+	if enforcement.EnforceMaxBodyBytes && tenantLimits.MaxBodyBytes > 0 {
+		evaluated["max_body_bytes"] = float64(tenantLimits.MaxBodyBytes)
+	}
+	if enforcement.EnforceMaxLabelsPerSeries && tenantLimits.MaxLabelsPerSeries > 0 {
+		evaluated["max_labels_per_series"] = float64(tenantLimits.MaxLabelsPerSeries)
+	}
+	if enforcement.EnforceMaxSeriesPerRequest && tenantLimits.MaxSeriesPerRequest > 0 {
+		evaluated["max_series_per_request"] = float64(tenantLimits.MaxSeriesPerRequest)
+	}
+	if enforcement.EnforceMaxSeriesPerMetric && tenantLimits.MaxSeriesPerMetric > 0 {
+		evaluated["max_series_per_metric"] = float64(tenantLimits.MaxSeriesPerMetric)
+	}
+	return evaluated
+}
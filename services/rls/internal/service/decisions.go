@@ -0,0 +1,222 @@
+package service
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/limits"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/parser"
+)
+
+const (
+	// defaultDecisionBufferSize is the capacity of each decision ring when none is configured
+	defaultDecisionBufferSize = 1 << 16
+	// decisionPollInterval is how often the consumer drains the decision rings
+	decisionPollInterval = 50 * time.Millisecond
+	// maxDenialSampleMetrics caps the example series kept per recorded denial
+	maxDenialSampleMetrics = 10
+)
+
+// DecisionEvent is a single ext_authz decision handed from Check to the background consumer
+type DecisionEvent struct {
+	TenantID      string
+	Allowed       bool
+	Reason        string
+	Samples       int64
+	BodyBytes     int64
+	Series        int64
+	Labels        int64
+	ResponseTime  float64 // seconds
+	Weight        int64   // number of decisions this event stands for after sampling
+	Timestamp     time.Time
+	SampleMetrics []parser.SampleMetricDetail // denials only; converted off the hot path
+}
+
+// decisionRing is a bounded multi-producer, single-consumer queue without locks.
+// Each slot carries a sequence number telling producers and the consumer whose turn it is.
+type decisionRing struct {
+	mask  uint64
+	slots []decisionSlot
+	head  atomic.Uint64 // next position to write
+	tail  atomic.Uint64 // next position to read; only the consumer advances it
+}
+
+type decisionSlot struct {
+	seq   atomic.Uint64
+	event DecisionEvent
+}
+
+// newDecisionRing creates a ring whose capacity is size rounded up to a power of two, and at least 2:
+// with a single slot a filled and a freed slot carry the same sequence number
+func newDecisionRing(size int) *decisionRing {
+	capacity := 2
+	for capacity < size {
+		capacity <<= 1
+	}
+	r := &decisionRing{mask: uint64(capacity - 1), slots: make([]decisionSlot, capacity)}
+	for i := range r.slots {
+		r.slots[i].seq.Store(uint64(i))
+	}
+	return r
+}
+
+// push enqueues an event and reports false when the ring is full
+func (r *decisionRing) push(event DecisionEvent) bool {
+	pos := r.head.Load()
+	for {
+		slot := &r.slots[pos&r.mask]
+		seq := slot.seq.Load()
+		switch diff := int64(seq - pos); {
+		case diff == 0:
+			if r.head.CompareAndSwap(pos, pos+1) {
+				slot.event = event
+				slot.seq.Store(pos + 1)
+				return true
+			}
+			pos = r.head.Load()
+		case diff < 0:
+			return false
+		default:
+			// Another producer claimed this position first
+			pos = r.head.Load()
+		}
+	}
+}
+
+// pop dequeues the oldest event. It must only be called from the consumer goroutine.
+func (r *decisionRing) pop() (DecisionEvent, bool) {
+	pos := r.tail.Load()
+	slot := &r.slots[pos&r.mask]
+	if slot.seq.Load() != pos+1 {
+		return DecisionEvent{}, false
+	}
+	event := slot.event
+	slot.event = DecisionEvent{}
+	r.tail.Store(pos + 1)
+	slot.seq.Store(pos + r.mask + 1)
+	return event, true
+}
+
+// len returns the approximate number of queued events
+func (r *decisionRing) len() int {
+	return int(r.head.Load() - r.tail.Load())
+}
+
+// decisionPipeline moves decisions out of Check into the admin counters and the TimeAggregator.
+// Denials and allows use separate rings so a burst of allows can never crowd out denials.
+type decisionPipeline struct {
+	denials *decisionRing
+	allows  *decisionRing
+
+	// Allows are sampled one in allowEvery; 0 disables allow recording
+	allowEvery uint64
+	allowSeq   atomic.Uint64
+}
+
+func newDecisionPipeline(bufferSize int, allowSampleRate float64) *decisionPipeline {
+	if bufferSize <= 0 {
+		bufferSize = defaultDecisionBufferSize
+	}
+	p := &decisionPipeline{
+		denials: newDecisionRing(bufferSize),
+		allows:  newDecisionRing(bufferSize),
+	}
+	if allowSampleRate > 0 {
+		if allowSampleRate > 1 {
+			allowSampleRate = 1
+		}
+		p.allowEvery = uint64(1/allowSampleRate + 0.5)
+	}
+	return p
+}
+
+// enqueueDecision hands a decision to the background consumer. It never blocks or logs;
+// events that do not fit are counted as dropped.
+func (rls *RLS) enqueueDecision(tenantID string, allowed bool, reason string, samples, bodyBytes int64, requestInfo *limits.RequestInfo, result *parser.ParseResult, start time.Time) {
+	p := rls.decisions
+	latency := time.Since(start)
+
+	// 🔧 NEW: Every decision is offered to the decision log, before allows are sampled for recording
+	rls.logDecision(tenantID, allowed, reason, samples, bodyBytes, requestInfo, start, latency)
+
+	event := DecisionEvent{
+		TenantID:     tenantID,
+		Allowed:      allowed,
+		Reason:       reason,
+		Samples:      samples,
+		BodyBytes:    bodyBytes,
+		ResponseTime: latency.Seconds(),
+		Weight:       1,
+		Timestamp:    start,
+	}
+	if requestInfo != nil {
+		event.Series = requestInfo.ObservedSeries
+		event.Labels = requestInfo.ObservedLabels
+	}
+
+	if allowed {
+		if p.allowEvery == 0 || p.allowSeq.Add(1)%p.allowEvery != 0 {
+			return
+		}
+		event.Weight = int64(p.allowEvery)
+		if !p.allows.push(event) {
+			rls.metrics.DecisionEventsDropped.WithLabelValues("allow").Inc()
+		}
+		return
+	}
+
+	if result != nil {
+		event.SampleMetrics = result.SampleMetrics
+	}
+	if !p.denials.push(event) {
+		rls.metrics.DecisionEventsDropped.WithLabelValues("deny").Inc()
+	}
+}
+
+// startDecisionConsumer drains the decision rings until the process exits
+func (rls *RLS) startDecisionConsumer() {
+	ticker := time.NewTicker(decisionPollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		rls.drainDecisions()
+		rls.metrics.DecisionQueueDepth.WithLabelValues("deny").Set(float64(rls.decisions.denials.len()))
+		rls.metrics.DecisionQueueDepth.WithLabelValues("allow").Set(float64(rls.decisions.allows.len()))
+	}
+}
+
+// drainDecisions records every queued event, denials first, and returns how many it handled
+func (rls *RLS) drainDecisions() int {
+	handled := 0
+	for _, ring := range []*decisionRing{rls.decisions.denials, rls.decisions.allows} {
+		for {
+			event, ok := ring.pop()
+			if !ok {
+				break
+			}
+			rls.processDecision(event)
+			handled++
+		}
+	}
+	return handled
+}
+
+func (rls *RLS) processDecision(event DecisionEvent) {
+	var sampleMetrics []limits.SampleMetric
+	if !event.Allowed && len(event.SampleMetrics) > 0 {
+		sampleMetrics = convertParserMetrics(event.SampleMetrics[:minInt(len(event.SampleMetrics), maxDenialSampleMetrics)])
+	}
+
+	requestInfo := &limits.RequestInfo{
+		ObservedSamples: event.Samples,
+		ObservedSeries:  event.Series,
+		ObservedLabels:  event.Labels,
+	}
+	rls.recordDecision(event.TenantID, event.Allowed, event.Reason, event.Samples, event.BodyBytes, requestInfo, sampleMetrics, nil, event.Timestamp, event.ResponseTime, event.Weight)
+	rls.updateTrafficFlowState(event.Timestamp, event.ResponseTime, event.Allowed, event.Weight)
+
+	// Rejected duplicates of a non-elected HA replica are not an enforcement event
+	if !event.Allowed && event.Reason != haNotElectedReason {
+		rls.observeDenial(event.TenantID, event.Reason, event.Timestamp)
+	}
+}
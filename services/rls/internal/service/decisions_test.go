@@ -0,0 +1,109 @@
+package service
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestDecisionRingPushPop(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     int
+		pushes   int
+		wantCap  int
+		wantPush int // pushes that fit
+	}{
+		{name: "rounds capacity up to a power of two", size: 5, pushes: 8, wantCap: 8, wantPush: 8},
+		{name: "rejects pushes when full", size: 4, pushes: 6, wantCap: 4, wantPush: 4},
+		{name: "at least two slots", size: 1, pushes: 3, wantCap: 2, wantPush: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newDecisionRing(tt.size)
+			if got := len(r.slots); got != tt.wantCap {
+				t.Fatalf("capacity = %d, want %d", got, tt.wantCap)
+			}
+
+			pushed := 0
+			for i := 0; i < tt.pushes; i++ {
+				if r.push(DecisionEvent{Samples: int64(i)}) {
+					pushed++
+				}
+			}
+			if pushed != tt.wantPush {
+				t.Fatalf("pushed %d events, want %d", pushed, tt.wantPush)
+			}
+			if got := r.len(); got != tt.wantPush {
+				t.Fatalf("len = %d, want %d", got, tt.wantPush)
+			}
+
+			for i := 0; i < tt.wantPush; i++ {
+				event, ok := r.pop()
+				if !ok {
+					t.Fatalf("pop %d: ring empty", i)
+				}
+				if event.Samples != int64(i) {
+					t.Fatalf("pop %d: got event %d, events must come out in order", i, event.Samples)
+				}
+			}
+			if _, ok := r.pop(); ok {
+				t.Fatal("pop on an empty ring returned an event")
+			}
+		})
+	}
+}
+
+func TestDecisionRingWrapsAround(t *testing.T) {
+	r := newDecisionRing(4)
+	next := int64(0)
+	for round := 0; round < 10; round++ {
+		for i := 0; i < 3; i++ {
+			if !r.push(DecisionEvent{Samples: next + int64(i)}) {
+				t.Fatalf("round %d: push %d rejected", round, i)
+			}
+		}
+		for i := 0; i < 3; i++ {
+			event, ok := r.pop()
+			if !ok || event.Samples != next {
+				t.Fatalf("round %d: got (%d, %v), want event %d", round, event.Samples, ok, next)
+			}
+			next++
+		}
+	}
+}
+
+func TestDecisionRingConcurrentProducers(t *testing.T) {
+	const producers, perProducer = 8, 1000
+	r := newDecisionRing(producers * perProducer)
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				if !r.push(DecisionEvent{Samples: int64(p*perProducer + i)}) {
+					t.Errorf("producer %d: push %d rejected", p, i)
+					return
+				}
+			}
+		}(p)
+	}
+	wg.Wait()
+
+	seen := make(map[int64]bool, producers*perProducer)
+	for {
+		event, ok := r.pop()
+		if !ok {
+			break
+		}
+		if seen[event.Samples] {
+			t.Fatalf("event %d popped twice", event.Samples)
+		}
+		seen[event.Samples] = true
+	}
+	if len(seen) != producers*perProducer {
+		t.Fatalf("popped %d events, want %d", len(seen), producers*perProducer)
+	}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	prompb "github.com/AkshayDubey29/mimir-edge-enforcement/protos/prometheus"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/forensics"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/parser"
+)
+
+// ErrForensicsDisabled is returned when no forensics directory is configured
+var ErrForensicsDisabled = fmt.Errorf("forensic capture is disabled (no forensics directory configured)")
+
+// ForensicsEnabled reports whether forensic capture is available on this replica
+func (rls *RLS) ForensicsEnabled() bool {
+	return rls.forensics != nil
+}
+
+// GetTenantForensics returns the forensic mode of a tenant and its captures, newest first
+func (rls *RLS) GetTenantForensics(tenantID string) (forensics.TenantStatus, []forensics.Capture, error) {
+	if rls.forensics == nil {
+		return forensics.TenantStatus{}, nil, ErrForensicsDisabled
+	}
+	return rls.forensics.Status(tenantID), rls.forensics.List(tenantID), nil
+}
+
+// SetTenantForensics switches forensic capture on or off for a tenant; a zero duration keeps it on until switched off
+func (rls *RLS) SetTenantForensics(tenantID string, enabled bool, duration time.Duration, change ChangeContext) (forensics.TenantStatus, error) {
+	if rls.forensics == nil {
+		return forensics.TenantStatus{}, ErrForensicsDisabled
+	}
+	var until time.Time
+	if enabled && duration > 0 {
+		until = time.Now().Add(duration)
+	}
+	if err := rls.forensics.SetEnabled(tenantID, enabled, until); err != nil {
+		return forensics.TenantStatus{}, fmt.Errorf("failed to persist forensic mode: %w", err)
+	}
+
+	rls.logger.Info().
+		Str("tenant_id", tenantID).
+		Bool("enabled", enabled).
+		Dur("duration", duration).
+		Str("actor", change.Actor).
+		Str("source", change.Source).
+		Msg("RLS: forensic capture mode changed")
+	return rls.forensics.Status(tenantID), nil
+}
+
+// PurgeTenantForensics deletes every capture of a tenant
+func (rls *RLS) PurgeTenantForensics(tenantID string) (int, error) {
+	if rls.forensics == nil {
+		return 0, ErrForensicsDisabled
+	}
+	return rls.forensics.Purge(tenantID), nil
+}
+
+// ReadTenantCapture returns a capture as snappy-compressed remote-write protobuf
+func (rls *RLS) ReadTenantCapture(tenantID, captureID string) ([]byte, forensics.Capture, error) {
+	if rls.forensics == nil {
+		return nil, forensics.Capture{}, ErrForensicsDisabled
+	}
+	return rls.forensics.ReadRaw(tenantID, captureID)
+}
+
+// ReadTenantCaptureJSON returns the label sets of a capture
+func (rls *RLS) ReadTenantCaptureJSON(tenantID, captureID string) ([]forensics.SeriesJSON, forensics.Capture, error) {
+	if rls.forensics == nil {
+		return nil, forensics.Capture{}, ErrForensicsDisabled
+	}
+	request, capture, err := rls.forensics.Read(tenantID, captureID)
+	if err != nil {
+		return nil, capture, err
+	}
+	return forensics.ToJSON(request), capture, nil
+}
+
+// captureDroppedSeries stores the series selective filtering removed from a request
+func (rls *RLS) captureDroppedSeries(tenantID, contentEncoding string, original *parser.ParseResult, filteredBody []byte) {
+	if !rls.forensics.Enabled(tenantID) {
+		return
+	}
+	filtered, err := parser.ParseRemoteWriteRequest(filteredBody, contentEncoding)
+	if err != nil {
+		rls.logger.Debug().Err(err).Str("tenant_id", tenantID).Msg("RLS: cannot parse filtered body for forensic capture")
+		return
+	}
+
+	kept := make(map[string]bool, len(filtered.Series))
+	for _, s := range filtered.Series {
+		kept[seriesKey(s.Labels)] = true
+	}
+	var dropped []parser.SeriesLabels
+	for _, s := range original.Series {
+		if !kept[seriesKey(s.Labels)] {
+			dropped = append(dropped, s)
+		}
+	}
+	rls.forensics.Submit(tenantID, forensics.SourceSelectiveFilter, "selective_filter_applied", dropped)
+}
+
+// seriesKey identifies a series by its full label set
+func seriesKey(labels []*prompb.Label) string {
+	var b strings.Builder
+	for _, label := range labels {
+		b.WriteString(label.Name)
+		b.WriteByte(0xff)
+		b.WriteString(label.Value)
+		b.WriteByte(0xff)
+	}
+	return b.String()
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/limits"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/parser"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/store"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Mimir's default HA labels, used when the tenant's overrides do not name them
+const (
+	defaultHAClusterLabel = "cluster"
+	defaultHAReplicaLabel = "__replica__"
+)
+
+// haNotElectedReason is the decision reason for traffic of a replica that is not its cluster's elected one
+const haNotElectedReason = "ha_replica_not_elected"
+
+// HATrackerConfig configures the election of one replica per tenant and HA cluster, like Mimir's HA tracker.
+// Tenants opt in with accept_ha_samples.
+type HATrackerConfig struct {
+	FailoverTimeout time.Duration // how long the elected replica may be silent before another replica takes over
+	UpdateTimeout   time.Duration // how long an election read from the store is trusted before it is read again
+	// RejectNonElected answers traffic of non-elected replicas with 202 at the edge, as Mimir would, instead of
+	// forwarding it to Mimir uncounted
+	RejectNonElected bool
+}
+
+type haClusterKey struct {
+	tenantID string
+	cluster  string
+}
+
+// haElection is an election as last read from the store
+type haElection struct {
+	replica     string
+	checkedAt   time.Time // last read from the store, by any replica's request
+	refreshedAt time.Time // last read by a request of the elected replica, which refreshed its LastSeen
+}
+
+// haLabels returns the cluster and replica labels of the first series, which Mimir also uses for the whole
+// request. Both are empty unless the tenant accepts HA samples.
+func haLabels(tenant *TenantState, series []parser.SeriesLabels) (cluster, replica string) {
+	tenantLimits := tenant.Info.Limits
+	if !tenantLimits.AcceptHASamples || len(series) == 0 {
+		return "", ""
+	}
+	clusterLabel, replicaLabel := tenantLimits.HAClusterLabel, tenantLimits.HAReplicaLabel
+	if clusterLabel == "" {
+		clusterLabel = defaultHAClusterLabel
+	}
+	if replicaLabel == "" {
+		replicaLabel = defaultHAReplicaLabel
+	}
+	for _, label := range series[0].Labels {
+		switch label.Name {
+		case clusterLabel:
+			cluster = label.Value
+		case replicaLabel:
+			replica = label.Value
+		}
+	}
+	return cluster, replica
+}
+
+// checkHAReplica decides on traffic of an HA replica. Traffic of the elected replica, and of requests without both HA
+// labels, is left to the limits (false). Traffic of another replica is deduplicated: allowed without being counted, or
+// rejected with 202 when RejectNonElected is set. A new cluster beyond ha_max_clusters is rejected with 400.
+func (rls *RLS) checkHAReplica(ctx context.Context, tenant *TenantState, requestInfo *limits.RequestInfo) (limits.Decision, bool) {
+	if requestInfo == nil || requestInfo.HACluster == "" || requestInfo.HAReplica == "" {
+		return limits.Decision{}, false
+	}
+
+	elected, err := rls.electedHAReplica(ctx, tenant, requestInfo.HACluster, requestInfo.HAReplica)
+	if errors.Is(err, store.ErrTooManyHAClusters) {
+		return limits.Decision{Allowed: false, Reason: "too_many_ha_clusters", Code: 400}, true
+	}
+	if err != nil {
+		// Counting a replica twice is safer than dropping the elected one's traffic
+		rls.logger.Warn().Err(err).Str("tenant", tenant.Info.ID).Str("cluster", requestInfo.HACluster).Msg("RLS: HA replica election failed - counting the request")
+		return limits.Decision{}, false
+	}
+	if elected == requestInfo.HAReplica {
+		return limits.Decision{}, false
+	}
+
+	rls.metrics.HADeduplicatedSamples.WithLabelValues(rls.labels.tenant(tenant.Info.ID)).Add(float64(requestInfo.ObservedSamples))
+	if rls.config.HATracker.RejectNonElected {
+		return limits.Decision{Allowed: false, Reason: haNotElectedReason, Code: 202}, true
+	}
+	return limits.Decision{Allowed: true, Reason: haNotElectedReason, Code: 200}, true
+}
+
+// electedHAReplica returns the elected replica of the tenant's cluster. An election is read from the store at most
+// once per UpdateTimeout. Only a read by the elected replica refreshes its LastSeen there, so a request of the
+// elected replica also reads it once its last refresh is older than UpdateTimeout, whoever read it last; otherwise
+// reads made for the other replica would keep the cache fresh and the elected one would fail over while sending.
+// Failover takes at most FailoverTimeout plus UpdateTimeout.
+func (rls *RLS) electedHAReplica(ctx context.Context, tenant *TenantState, cluster, replica string) (string, error) {
+	key := haClusterKey{tenantID: tenant.Info.ID, cluster: cluster}
+	now := time.Now()
+
+	rls.haMu.Lock()
+	election, ok := rls.haElections[key]
+	rls.haMu.Unlock()
+	updateTimeout := rls.config.HATracker.UpdateTimeout
+	if ok && now.Sub(election.checkedAt) < updateTimeout &&
+		(replica != election.replica || now.Sub(election.refreshedAt) < updateTimeout) {
+		return election.replica, nil
+	}
+
+	// The election keeps the caller's trace but not its cancellation
+	ctx, span := tracing.Start(ctx, "store.ElectHAReplica", trace.SpanKindClient, attribute.String("tenant.id", tenant.Info.ID))
+	defer span.End()
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 500*time.Millisecond)
+	defer cancel()
+
+	elected, err := rls.store.ElectHAReplica(ctx, tenant.Info.ID, cluster, replica, now, rls.config.HATracker.FailoverTimeout, int(tenant.Info.Limits.HAMaxClusters))
+	if err != nil {
+		tracing.RecordError(span, err)
+		return "", err
+	}
+
+	rls.haMu.Lock()
+	if previous, ok := rls.haElections[key]; ok && previous.replica != elected.Replica {
+		rls.logger.Info().
+			Str("tenant", tenant.Info.ID).
+			Str("cluster", cluster).
+			Str("previous_replica", previous.replica).
+			Str("elected_replica", elected.Replica).
+			Msg("RLS: HA replica failover")
+	}
+	refreshedAt := now
+	if elected.Replica != replica {
+		refreshedAt = time.Time{}
+		if previous, ok := rls.haElections[key]; ok && previous.replica == elected.Replica {
+			refreshedAt = previous.refreshedAt
+		}
+	}
+	rls.haElections[key] = haElection{replica: elected.Replica, checkedAt: now, refreshedAt: refreshedAt}
+	rls.haMu.Unlock()
+	return elected.Replica, nil
+}
+
+// GetHAReplicas returns the elected replica of each HA cluster of a tenant
+func (rls *RLS) GetHAReplicas(tenantID string) ([]store.HAReplica, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return rls.store.ListHAReplicas(ctx, tenantID)
+}
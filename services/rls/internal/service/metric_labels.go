@@ -0,0 +1,216 @@
+package service
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Tenant label modes for RLS's own metrics
+const (
+	TenantLabelRaw    = "raw"    // the tenant ID, up to MaxTenants distinct tenants
+	TenantLabelHash   = "hash"   // an opaque FNV-1a hash of the tenant ID
+	TenantLabelBucket = "bucket" // one of TenantBuckets buckets chosen by hash
+)
+
+// Label values that stand in for values RLS does not export
+const (
+	otherTenantLabel = "__other__"
+	otherReasonLabel = "other"
+)
+
+// Causes reported by rls_self_metric_label_sets_dropped_total
+const (
+	dropCauseTenantOverflow = "tenant_overflow"
+	dropCauseUnknownReason  = "unknown_reason"
+	dropCauseMetricTopK     = "metric_top_k"
+)
+
+// maxDroppedValuesPerCause bounds the dropped values remembered per cause. When a cause reaches it the
+// remembered values are forgotten, so a value can then be counted once more.
+const maxDroppedValuesPerCause = 10000
+
+// knownDecisionReasons are the reasons exported on rls_decisions_total; anything else becomes "other"
+var knownDecisionReasons = map[string]bool{
+	"allowed":                              true,
+	"enforcement_disabled":                 true,
+	"small_request":                        true,
+	"request_too_large":                    true,
+	"request body too large":               true,
+	"missing_tenant_header":                true,
+	"body_extract_failed":                  true,
+	"body_extract_failed_allow":            true,
+	"body_extract_failed_limit_exceeded":   true,
+	"parse_failed_allow":                   true,
+	"parse_failed_limit_exceeded":          true,
+	"body_parse_failed_allow":              true,
+	"body_parse_failed_limit_exceeded":     true,
+	"selective_filter_applied":             true,
+	"selective_filter_allowed":             true,
+	"series_filtered":                      true,
+	"body_size_exceeded_after_filtering":   true,
+	"per_user_series_limit_exceeded":       true,
+	"per_metric_series_limit_exceeded":     true,
+	"body_size_exceeded":                   true,
+	"labels_per_series_exceeded":           true,
+	"samples_per_second_exceeded":          true,
+	"samples_per_second_exceeded_recovery": true,
+	"bytes_per_second_exceeded":            true,
+	"safety_valve_activated":               true,
+	"ha_replica_not_elected":               true,
+	"too_many_ha_clusters":                 true,
+	"shadow_mode":                          true,
+}
+
+// SelfMetricsConfig bounds the label cardinality of RLS's own metrics
+type SelfMetricsConfig struct {
+	TenantLabelMode  string // raw, hash or bucket
+	MaxTenants       int    // distinct tenant labels in raw and hash mode; later tenants are reported as __other__ (0 = unlimited)
+	TenantBuckets    int    // buckets in bucket mode
+	MetricSeriesTopK int    // metric names per tenant on rls_metric_series_count_gauge, largest first (0 disables the gauge)
+}
+
+// metricLabeler maps tenants, reasons and client metric names to bounded label values
+type metricLabeler struct {
+	config  SelfMetricsConfig
+	dropped *prometheus.CounterVec
+
+	tenants     sync.Map // tenant label -> struct{}, admitted in raw and hash mode
+	tenantCount atomic.Int64
+
+	mu         sync.Mutex
+	topMetrics map[string]map[string]float64 // tenant label -> metric name -> exported gauge value
+
+	droppedMu     sync.RWMutex
+	droppedValues map[string]map[string]struct{} // cause -> values already counted as dropped
+}
+
+func newMetricLabeler(config SelfMetricsConfig, dropped *prometheus.CounterVec) *metricLabeler {
+	switch config.TenantLabelMode {
+	case TenantLabelRaw, TenantLabelHash, TenantLabelBucket:
+	default:
+		config.TenantLabelMode = TenantLabelRaw
+	}
+	if config.TenantLabelMode == TenantLabelBucket && config.TenantBuckets <= 0 {
+		config.TenantBuckets = 64
+	}
+	return &metricLabeler{
+		config:        config,
+		dropped:       dropped,
+		topMetrics:    make(map[string]map[string]float64),
+		droppedValues: make(map[string]map[string]struct{}),
+	}
+}
+
+// tenant returns the label value for a tenant
+func (l *metricLabeler) tenant(tenantID string) string {
+	if tenantID == "" || tenantID == "unknown" {
+		return "unknown"
+	}
+
+	label := tenantID
+	switch l.config.TenantLabelMode {
+	case TenantLabelBucket:
+		return fmt.Sprintf("bucket-%03d", hashTenant(tenantID)%uint32(l.config.TenantBuckets))
+	case TenantLabelHash:
+		label = fmt.Sprintf("%08x", hashTenant(tenantID))
+	}
+
+	if l.config.MaxTenants <= 0 {
+		return label
+	}
+	if _, ok := l.tenants.Load(label); ok {
+		return label
+	}
+	if l.tenantCount.Add(1) > int64(l.config.MaxTenants) {
+		l.tenantCount.Add(-1)
+		l.drop(dropCauseTenantOverflow, label)
+		return otherTenantLabel
+	}
+	if _, loaded := l.tenants.LoadOrStore(label, struct{}{}); loaded {
+		l.tenantCount.Add(-1)
+	}
+	return label
+}
+
+// reason returns the label value for a decision reason
+func (l *metricLabeler) reason(reason string) string {
+	if knownDecisionReasons[reason] {
+		return reason
+	}
+	l.drop(dropCauseUnknownReason, reason)
+	return otherReasonLabel
+}
+
+// setMetricSeries sets the per-metric series gauge if the metric is among the tenant's top K.
+// A metric that outgrows the smallest exported one replaces it.
+func (l *metricLabeler) setMetricSeries(gauge *prometheus.GaugeVec, tenantID, metricName string, value float64) {
+	if l.config.MetricSeriesTopK <= 0 {
+		return
+	}
+	tenant := l.tenant(tenantID)
+
+	l.mu.Lock()
+	metrics, ok := l.topMetrics[tenant]
+	if !ok {
+		metrics = make(map[string]float64, l.config.MetricSeriesTopK)
+		l.topMetrics[tenant] = metrics
+	}
+	if _, exported := metrics[metricName]; !exported && len(metrics) >= l.config.MetricSeriesTopK {
+		smallest, smallestValue := "", 0.0
+		for name, v := range metrics {
+			if smallest == "" || v < smallestValue {
+				smallest, smallestValue = name, v
+			}
+		}
+		if value <= smallestValue {
+			l.mu.Unlock()
+			l.drop(dropCauseMetricTopK, tenant+"/"+metricName)
+			return
+		}
+		delete(metrics, smallest)
+		gauge.DeleteLabelValues(tenant, smallest)
+		l.drop(dropCauseMetricTopK, tenant+"/"+smallest)
+	}
+	metrics[metricName] = value
+	gauge.WithLabelValues(tenant, metricName).Set(value)
+	l.mu.Unlock()
+}
+
+// drop counts a label set the first time its value is dropped for a cause, rather than on every request carrying it
+func (l *metricLabeler) drop(cause, value string) {
+	if l.dropped == nil {
+		return
+	}
+
+	// Values already counted are the common case on the request path, so only a read lock is taken for them
+	l.droppedMu.RLock()
+	_, seen := l.droppedValues[cause][value]
+	l.droppedMu.RUnlock()
+	if seen {
+		return
+	}
+
+	l.droppedMu.Lock()
+	values, ok := l.droppedValues[cause]
+	if !ok || len(values) >= maxDroppedValuesPerCause {
+		values = make(map[string]struct{})
+		l.droppedValues[cause] = values
+	}
+	_, seen = values[value]
+	values[value] = struct{}{}
+	l.droppedMu.Unlock()
+
+	if !seen {
+		l.dropped.WithLabelValues(cause).Inc()
+	}
+}
+
+func hashTenant(tenantID string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(tenantID))
+	return h.Sum32()
+}
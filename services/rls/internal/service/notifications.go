@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/limits"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/notify"
+)
+
+const (
+	// defaultNotifyQuietPeriod is how long a tenant must go without denials before its next denial is announced
+	defaultNotifyQuietPeriod = 10 * time.Minute
+)
+
+// seriesNotifyLevels are the shares of MaxSeriesPerRequest announced when a tenant crosses them
+var seriesNotifyLevels = []float64{80, 90, 100}
+
+// notificationState remembers what has already been announced per tenant
+type notificationState struct {
+	mu sync.Mutex
+	// last denial and safety valve activation seen per tenant
+	lastDenial      map[string]time.Time
+	lastSafetyValve map[string]time.Time
+	// index+1 of the highest series level a tenant is at; 0 when below every level
+	seriesLevel map[string]int
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newNotificationState() *notificationState {
+	return &notificationState{
+		lastDenial:      make(map[string]time.Time),
+		lastSafetyValve: make(map[string]time.Time),
+		seriesLevel:     make(map[string]int),
+	}
+}
+
+// startNotifier starts delivering notifications in the background
+func (rls *RLS) startNotifier() {
+	if rls.notifier == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	rls.notifications.cancel = cancel
+	rls.notifications.done = make(chan struct{})
+	go func() {
+		defer close(rls.notifications.done)
+		rls.notifier.Run(ctx)
+	}()
+}
+
+// StopNotifier flushes queued notifications and stops delivery
+func (rls *RLS) StopNotifier() {
+	if rls.notifier == nil || rls.notifications.cancel == nil {
+		return
+	}
+	rls.notifications.cancel()
+	<-rls.notifications.done
+}
+
+// notifyQuietPeriod returns the configured quiet period
+func (rls *RLS) notifyQuietPeriod() time.Duration {
+	if rls.config.NotifyQuietPeriod > 0 {
+		return rls.config.NotifyQuietPeriod
+	}
+	return defaultNotifyQuietPeriod
+}
+
+// observeDenial announces a tenant's first denial after a quiet period
+func (rls *RLS) observeDenial(tenantID, reason string, at time.Time) {
+	if rls.notifier == nil {
+		return
+	}
+	state := rls.notifications
+	state.mu.Lock()
+	last, seen := state.lastDenial[tenantID]
+	state.lastDenial[tenantID] = at
+	state.mu.Unlock()
+
+	if seen && at.Sub(last) < rls.notifyQuietPeriod() {
+		return
+	}
+
+	summary := fmt.Sprintf("Tenant %s is being throttled (%s)", tenantID, reason)
+	if seen {
+		summary = fmt.Sprintf("Tenant %s is being throttled again after %s without denials (%s)", tenantID, at.Sub(last).Round(time.Second), reason)
+	}
+	rls.notifier.Notify(notify.Event{
+		Type:      notify.EventFirstDenial,
+		TenantID:  tenantID,
+		Severity:  AlertSeverityWarning,
+		Summary:   summary,
+		Labels:    map[string]string{"reason": reason},
+		Timestamp: at,
+	})
+}
+
+// observeSafetyValve announces a safety valve activation, at most once per quiet period and tenant
+func (rls *RLS) observeSafetyValve(tenantID, originalReason string, recentDenials int) {
+	if rls.notifier == nil {
+		return
+	}
+	now := time.Now()
+	state := rls.notifications
+	state.mu.Lock()
+	last, seen := state.lastSafetyValve[tenantID]
+	if seen && now.Sub(last) < rls.notifyQuietPeriod() {
+		state.mu.Unlock()
+		return
+	}
+	state.lastSafetyValve[tenantID] = now
+	state.mu.Unlock()
+
+	rls.notifier.Notify(notify.Event{
+		Type:     notify.EventSafetyValve,
+		TenantID: tenantID,
+		Severity: AlertSeverityCritical,
+		Summary:  fmt.Sprintf("Safety valve let traffic of tenant %s through despite %s (%d denials in the last 2m)", tenantID, originalReason, recentDenials),
+		Labels:   map[string]string{"reason": originalReason},
+		Details:  map[string]string{"recent_denials": fmt.Sprintf("%d", recentDenials)},
+	})
+}
+
+// observeSeriesLevel announces a tenant crossing 80, 90 or 100% of MaxSeriesPerRequest upwards.
+// Falling below a level re-arms it.
+func (rls *RLS) observeSeriesLevel(tenantID string, series, limit int64) {
+	if rls.notifier == nil || limit <= 0 {
+		return
+	}
+	pct := float64(series) / float64(limit) * 100.0
+	level := 0
+	for i, threshold := range seriesNotifyLevels {
+		if pct >= threshold {
+			level = i + 1
+		}
+	}
+
+	state := rls.notifications
+	state.mu.Lock()
+	previous := state.seriesLevel[tenantID]
+	state.seriesLevel[tenantID] = level
+	state.mu.Unlock()
+
+	if level <= previous {
+		return
+	}
+	threshold := seriesNotifyLevels[level-1]
+	severity := AlertSeverityWarning
+	if threshold >= 100 {
+		severity = AlertSeverityCritical
+	}
+	rls.notifier.Notify(notify.Event{
+		Type:     notify.EventSeriesThreshold,
+		TenantID: tenantID,
+		Severity: severity,
+		Summary:  fmt.Sprintf("Tenant %s crossed %.0f%% of its series limit (%d of %d)", tenantID, threshold, series, limit),
+		Labels:   map[string]string{"threshold": fmt.Sprintf("%.0f", threshold)},
+		Details: map[string]string{
+			"series": fmt.Sprintf("%d", series),
+			"limit":  fmt.Sprintf("%d", limit),
+		},
+	})
+}
+
+// notifyLimitsChanged announces a change to an existing tenant's configuration
+func (rls *RLS) notifyLimitsChanged(event *limits.AuditEvent) {
+	if rls.notifier == nil || event.Before == nil || len(event.Changes) == 0 {
+		return
+	}
+	fields := make([]string, 0, len(event.Changes))
+	for _, change := range event.Changes {
+		fields = append(fields, change.Field)
+	}
+	rls.notifier.Notify(notify.Event{
+		Type:     notify.EventLimitsChanged,
+		TenantID: event.TenantID,
+		Severity: "info",
+		Summary:  fmt.Sprintf("%s of tenant %s changed by %s via %s: %s", event.Action, event.TenantID, event.Actor, event.Source, strings.Join(fields, ", ")),
+		Labels:   map[string]string{"action": event.Action},
+		Details: map[string]string{
+			"actor":  event.Actor,
+			"source": event.Source,
+		},
+		Timestamp: event.Timestamp,
+	})
+}
+
+// notifyAlerts announces cardinality alerts that fired or resolved
+func (rls *RLS) notifyAlerts(fired, resolved []limits.CardinalityAlert) {
+	if rls.notifier == nil {
+		return
+	}
+	for _, alert := range fired {
+		rls.notifier.Notify(alertEvent(notify.EventCardinalityAlert, alert))
+	}
+	for _, alert := range resolved {
+		rls.notifier.Notify(alertEvent(notify.EventCardinalityResolve, alert))
+	}
+}
+
+func alertEvent(eventType string, alert limits.CardinalityAlert) notify.Event {
+	return notify.Event{
+		Type:      eventType,
+		TenantID:  alert.TenantID,
+		Severity:  alert.Severity,
+		Summary:   alert.Message,
+		Labels:    map[string]string{"rule": alert.Rule, "metric": alert.Metric, "alert_id": alert.ID},
+		Timestamp: alert.Timestamp,
+		Resolved:  alert.Resolved,
+	}
+}
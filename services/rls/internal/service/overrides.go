@@ -0,0 +1,118 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/limits"
+)
+
+// overrideExpiryInterval is how often expired temporary overrides are reverted
+const overrideExpiryInterval = 15 * time.Second
+
+// SetTemporaryOverride layers a time-bounded override on top of a tenant's limits
+func (rls *RLS) SetTemporaryOverride(tenantID string, override limits.TemporaryOverride, change ChangeContext) error {
+	now := time.Now()
+	if !override.ExpiresAt.After(now) {
+		return fmt.Errorf("override for tenant %s must expire in the future", tenantID)
+	}
+	if maxDuration := rls.config.MaxOverrideDuration; maxDuration > 0 && override.ExpiresAt.Sub(now) > maxDuration {
+		return fmt.Errorf("override for tenant %s must expire within %s", tenantID, maxDuration)
+	}
+	if override.SamplesPerSecond == nil && override.MaxSeriesPerRequest == nil {
+		return fmt.Errorf("override for tenant %s does not change any limit", tenantID)
+	}
+	override.CreatedAt = now
+	override.CreatedBy = change.Actor
+
+	rls.tenantsMu.Lock()
+	tenant, exists := rls.tenants[tenantID]
+	if !exists {
+		rls.tenantsMu.Unlock()
+		return fmt.Errorf("tenant %s not found", tenantID)
+	}
+
+	before := snapshotTenantConfig(tenant)
+	tenant.Info.TemporaryOverride = &override
+	rls.resolveTenantLimits(tenant)
+	rls.updateTenantBuckets(tenantID, tenant, tenant.Info.Limits)
+	effective := tenant.Info.Limits
+	write := newTenantWrite(tenantID, AuditActionSetOverride, change, before, tenant)
+	rls.tenantsMu.Unlock()
+
+	rls.flushTenantWrites("", []tenantWrite{write})
+
+	rls.logger.Info().
+		Str("tenant_id", tenantID).
+		Time("expires_at", override.ExpiresAt).
+		Str("reason", override.Reason).
+		Str("actor", change.Actor).
+		Float64("effective_samples_per_second", effective.SamplesPerSecond).
+		Int32("effective_max_series_per_request", effective.MaxSeriesPerRequest).
+		Msg("RLS: applied temporary limit override")
+
+	return nil
+}
+
+// ClearTemporaryOverride removes a tenant's temporary override before it expires
+func (rls *RLS) ClearTemporaryOverride(tenantID string, change ChangeContext) error {
+	rls.tenantsMu.Lock()
+	tenant, exists := rls.tenants[tenantID]
+	if !exists {
+		rls.tenantsMu.Unlock()
+		return fmt.Errorf("tenant %s not found", tenantID)
+	}
+	if tenant.Info.TemporaryOverride == nil {
+		rls.tenantsMu.Unlock()
+		return fmt.Errorf("tenant %s has no temporary override", tenantID)
+	}
+	write := rls.revertTemporaryOverride(tenantID, tenant, AuditActionClearOverride, change)
+	rls.tenantsMu.Unlock()
+
+	rls.flushTenantWrites("", []tenantWrite{write})
+	return nil
+}
+
+// revertTemporaryOverride drops the override and restores the base limits. Callers must hold tenantsMu
+// and flush the returned write once they released it.
+func (rls *RLS) revertTemporaryOverride(tenantID string, tenant *TenantState, action string, change ChangeContext) tenantWrite {
+	before := snapshotTenantConfig(tenant)
+	tenant.Info.TemporaryOverride = nil
+	rls.resolveTenantLimits(tenant)
+	rls.updateTenantBuckets(tenantID, tenant, tenant.Info.Limits)
+
+	rls.logger.Info().
+		Str("tenant_id", tenantID).
+		Str("action", action).
+		Float64("samples_per_second", tenant.Info.Limits.SamplesPerSecond).
+		Int32("max_series_per_request", tenant.Info.Limits.MaxSeriesPerRequest).
+		Msg("RLS: reverted temporary limit override")
+	return newTenantWrite(tenantID, action, change, before, tenant)
+}
+
+// startOverrideExpiryLoop periodically reverts temporary overrides that have expired
+func (rls *RLS) startOverrideExpiryLoop() {
+	ticker := time.NewTicker(overrideExpiryInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		rls.expireTemporaryOverrides(time.Now())
+	}
+}
+
+// expireTemporaryOverrides reverts every temporary override that expired before now. The store is
+// written once tenantsMu is released, so Check is not held up by the round trips.
+func (rls *RLS) expireTemporaryOverrides(now time.Time) {
+	change := ChangeContext{Actor: "override-expiry", Source: AuditSourceSystem}
+	var writes []tenantWrite
+
+	rls.tenantsMu.Lock()
+	for tenantID, tenant := range rls.tenants {
+		if tenant.Info.TemporaryOverride != nil && !tenant.Info.TemporaryOverride.Active(now) {
+			writes = append(writes, rls.revertTemporaryOverride(tenantID, tenant, AuditActionOverrideExpired, change))
+		}
+	}
+	rls.tenantsMu.Unlock()
+
+	rls.flushTenantWrites("", writes)
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/limits"
+)
+
+// applyEnforcement sets a tenant's effective enforcement: its policy's while the policy sets one, else its own
+func (rls *RLS) applyEnforcement(tenant *TenantState) {
+	tenant.Info.Enforcement = tenant.Enforcement
+	if policy := tenant.Info.Policy; policy != nil && policy.Enforcement != nil {
+		tenant.Info.Enforcement = *policy.Enforcement
+	}
+}
+
+// selectiveFilteringEnabled reports whether a tenant's requests are filtered rather than denied.
+// Tenants in shadow mode are never filtered, since that would change what they send.
+func (rls *RLS) selectiveFilteringEnabled(tenant *TenantState) bool {
+	policy := tenant.Info.Policy
+	if policy.InShadowMode() {
+		return false
+	}
+	if policy != nil && policy.SelectiveFiltering != nil && policy.SelectiveFiltering.Enabled != nil {
+		return *policy.SelectiveFiltering.Enabled
+	}
+	return rls.config.SelectiveFiltering.Enabled
+}
+
+// SetTenantPolicy sets a tenant's edge policy, creating the tenant if needed; nil clears it
+func (rls *RLS) SetTenantPolicy(tenantID string, policy *limits.EdgePolicy, change ChangeContext) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	rls.tenantsMu.Lock()
+	if policy == nil {
+		if _, exists := rls.tenants[tenantID]; !exists {
+			rls.tenantsMu.Unlock()
+			return fmt.Errorf("tenant %s not found", tenantID)
+		}
+	}
+	write, changed := rls.setTenantPolicyLocked(tenantID, policy, change)
+	rls.limitsVersion = LimitsSetVersion{}
+	rls.tenantsMu.Unlock()
+
+	if changed {
+		rls.flushTenantWrites("", []tenantWrite{write})
+	}
+	return nil
+}
+
+// setTenantPolicyLocked sets a tenant's edge policy in memory and reports whether anything changed; the caller holds
+// tenantsMu and flushes the returned write once it has released it
+func (rls *RLS) setTenantPolicyLocked(tenantID string, policy *limits.EdgePolicy, change ChangeContext) (tenantWrite, bool) {
+	tenant, exists := rls.tenants[tenantID]
+	if exists && reflect.DeepEqual(tenant.Info.Policy, policy) {
+		return tenantWrite{}, false
+	}
+
+	var before *limits.TenantConfigSnapshot
+	if exists {
+		before = snapshotTenantConfig(tenant)
+	} else {
+		tenant = &TenantState{
+			Info: limits.TenantInfo{
+				ID:   tenantID,
+				Name: tenantID,
+			},
+			Enforcement: rls.config.DefaultEnforcement,
+		}
+		rls.tenants[tenantID] = tenant
+		rls.resolveTenantLimits(tenant)
+		rls.updateTenantBuckets(tenantID, tenant, tenant.Info.Limits)
+	}
+
+	// A policy without enforcement, or none, leaves the tenant's own in effect
+	tenant.Info.Policy = policy
+	rls.applyEnforcement(tenant)
+
+	rls.logger.Info().
+		Str("tenant_id", tenantID).
+		Bool("cleared", policy == nil).
+		Bool("shadow_mode", policy.InShadowMode()).
+		Bool("enforcement_enabled", tenant.Info.Enforcement.Enabled).
+		Str("actor", change.Actor).
+		Msg("RLS: set tenant policy")
+	return newTenantWrite(tenantID, AuditActionSetPolicy, change, before, tenant), true
+}
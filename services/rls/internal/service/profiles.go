@@ -0,0 +1,134 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/limits"
+)
+
+// EffectiveLimits describes the resolved limits of a tenant and where each value came from
+type EffectiveLimits struct {
+	TenantID          string                           `json:"tenant_id"`
+	Profile           string                           `json:"profile,omitempty"`
+	Limits            limits.TenantLimits              `json:"limits"`
+	Sources           map[string]limits.EffectiveValue `json:"sources"`
+	TemporaryOverride *limits.TemporaryOverride        `json:"temporary_override,omitempty"`
+}
+
+// resolveTenantLimits merges global defaults, the synced defaults, the tenant's profile, the tenant's own limits
+// and any active temporary override field by field. Callers must hold tenantsMu.
+func (rls *RLS) resolveTenantLimits(tenant *TenantState) {
+	layers := make([]limits.LimitLayer, 0, 4)
+	if rls.syncedDefaults != (limits.LimitOverrides{}) {
+		layers = append(layers, limits.LimitLayer{Source: limits.LayerSynced, Overrides: rls.syncedDefaults})
+	}
+	if tenant.Info.Profile != "" {
+		if profile, ok := rls.config.LimitProfiles[tenant.Info.Profile]; ok {
+			layers = append(layers, limits.LimitLayer{Source: limits.LayerProfile + ":" + tenant.Info.Profile, Overrides: profile})
+		} else {
+			rls.logger.Warn().
+				Str("tenant_id", tenant.Info.ID).
+				Str("profile", tenant.Info.Profile).
+				Msg("RLS: tenant references unknown limit profile - ignoring")
+		}
+	}
+	layers = append(layers, limits.LimitLayer{Source: limits.LayerTenant, Overrides: tenant.Overrides})
+	tenant.Info.HasTenantLimits = tenant.Overrides != (limits.LimitOverrides{})
+	tenant.Info.Overrides = nil
+	if tenant.Info.HasTenantLimits {
+		overrides := tenant.Overrides
+		tenant.Info.Overrides = &overrides
+	}
+
+	tenant.BaseLimits, _ = limits.ResolveLimits(rls.config.DefaultLimits, layers...)
+
+	layers = append(layers, limits.LimitLayer{Source: limits.LayerTemporary, Overrides: tenant.Info.TemporaryOverride.Overrides()})
+	tenant.Info.Limits, tenant.LimitSources = limits.ResolveLimits(rls.config.DefaultLimits, layers...)
+}
+
+// GetLimitProfiles returns the configured limit profiles sorted by name
+func (rls *RLS) GetLimitProfiles() []map[string]any {
+	names := make([]string, 0, len(rls.config.LimitProfiles))
+	for name := range rls.config.LimitProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	profiles := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		profiles = append(profiles, map[string]any{
+			"name":   name,
+			"limits": rls.config.LimitProfiles[name],
+		})
+	}
+	return profiles
+}
+
+// SetTenantProfile assigns a limit profile to a tenant. An empty name removes the profile.
+func (rls *RLS) SetTenantProfile(tenantID, profile string, change ChangeContext) error {
+	if profile != "" {
+		if _, ok := rls.config.LimitProfiles[profile]; !ok {
+			return fmt.Errorf("limit profile %s not found", profile)
+		}
+	}
+
+	rls.tenantsMu.Lock()
+	tenant, exists := rls.tenants[tenantID]
+	if !exists {
+		rls.tenantsMu.Unlock()
+		return fmt.Errorf("tenant %s not found", tenantID)
+	}
+
+	before := snapshotTenantConfig(tenant)
+	tenant.Info.Profile = profile
+	rls.resolveTenantLimits(tenant)
+	rls.updateTenantBuckets(tenantID, tenant, tenant.Info.Limits)
+	write := newTenantWrite(tenantID, AuditActionSetProfile, change, before, tenant)
+	rls.tenantsMu.Unlock()
+
+	rls.flushTenantWrites("", []tenantWrite{write})
+
+	rls.logger.Info().
+		Str("tenant_id", tenantID).
+		Str("profile", profile).
+		Str("actor", change.Actor).
+		Msg("RLS: assigned limit profile to tenant")
+
+	return nil
+}
+
+// GetEffectiveLimits returns the resolved limits of a tenant with the layer each value came from
+func (rls *RLS) GetEffectiveLimits(tenantID string) (EffectiveLimits, bool) {
+	rls.tenantsMu.RLock()
+	defer rls.tenantsMu.RUnlock()
+
+	tenant, exists := rls.tenants[tenantID]
+	if !exists {
+		return EffectiveLimits{}, false
+	}
+	return effectiveLimits(tenantID, tenant), true
+}
+
+// effectiveLimits reads a tenant's effective limits; the caller holds tenantsMu
+func effectiveLimits(tenantID string, tenant *TenantState) EffectiveLimits {
+	return EffectiveLimits{
+		TenantID:          tenantID,
+		Profile:           tenant.Info.Profile,
+		Limits:            tenant.Info.Limits,
+		Sources:           tenant.LimitSources,
+		TemporaryOverride: tenant.Info.TemporaryOverride,
+	}
+}
+
+// GetTenantOverrides returns the limits explicitly set for a tenant
+func (rls *RLS) GetTenantOverrides(tenantID string) (limits.LimitOverrides, bool) {
+	rls.tenantsMu.RLock()
+	defer rls.tenantsMu.RUnlock()
+
+	tenant, exists := rls.tenants[tenantID]
+	if !exists {
+		return limits.LimitOverrides{}, false
+	}
+	return tenant.Overrides, true
+}
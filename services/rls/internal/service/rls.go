@@ -16,13 +16,21 @@ import (
 
 	prompb "github.com/AkshayDubey29/mimir-edge-enforcement/protos/prometheus"
 	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/buckets"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/cardinality"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/decisionlog"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/forensics"
 	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/limits"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/notify"
 	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/parser"
 	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/store"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/tracing"
 	"github.com/golang/snappy"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/structpb"
 
@@ -48,6 +56,36 @@ type RLSConfig struct {
 	NewTenantLeniency bool // Enable lenient limits for new tenants
 	// 🔧 NEW: Configuration for selective filtering
 	SelectiveFiltering SelectiveFilteringConfig // Enable selective filtering instead of binary allow/deny
+	// 🔧 NEW: Named limit profiles (tiers) layered between DefaultLimits and tenant limits
+	LimitProfiles map[string]limits.LimitOverrides
+	// 🔧 NEW: Longest a temporary override may last; 0 allows any expiry
+	MaxOverrideDuration time.Duration
+	// 🔧 NEW: Decision recording pipeline between Check and the admin analytics
+	DecisionBufferSize      int     // Capacity of each decision ring (denials and allows)
+	DecisionAllowSampleRate float64 // Fraction of allowed decisions recorded (0 disables, 1 records all); denials are always recorded
+	// 🔧 NEW: Persistence of time-aggregated analytics across restarts and replicas
+	AggregatorPersistence      string        // "", "file" or "redis"
+	AggregatorSnapshotPath     string        // Snapshot file for the file backend
+	AggregatorSnapshotInterval time.Duration // How often buckets are persisted and peer buckets refreshed
+	ReplicaID                  string        // Identifies this replica to its peers and in decision logs
+	AggregatorReplicaID        string        // Identifies this replica's snapshot in shared persistence; stable across restarts
+	// 🔧 NEW: Thresholds of the cardinality alert rules
+	AlertRules AlertRulesConfig
+	// 🔧 NEW: Outbound notifications of enforcement events
+	Notifications     notify.Config
+	NotifyQuietPeriod time.Duration // Denial-free time after which the next denial is announced again
+	// 🔧 NEW: Per-tenant top metrics and label names
+	CardinalityExplorer cardinality.Config
+	// 🔧 NEW: Opt-in capture of offending series; disabled when Dir is empty
+	Forensics forensics.Config
+	// 🔧 NEW: Structured per-decision log stream
+	DecisionLog decisionlog.Config
+	// 🔧 NEW: OTLP trace export; disabled when Endpoint is empty
+	Tracing tracing.Config
+	// 🔧 NEW: Label cardinality caps for RLS's own metrics
+	SelfMetrics SelfMetricsConfig
+	// 🔧 NEW: HA deduplication for tenants with accept_ha_samples
+	HATracker HATrackerConfig
 }
 
 // 🔧 NEW: SelectiveFilteringConfig holds configuration for selective filtering
@@ -65,6 +103,8 @@ type SeriesCacheEntry struct {
 	GlobalCount  int64
 	MetricCounts map[string]int64
 	LastUpdated  time.Time
+	// 🔧 FIX: Per-metric counts are refreshed independently of the global count
+	MetricCountsUpdated time.Time
 }
 
 // RLS represents the Rate Limit Service
@@ -107,6 +147,9 @@ type TimeAggregator struct {
 	maxBuckets1h    int
 	maxBuckets24h   int
 	maxBuckets1w    int
+
+	// 🔧 NEW: Persisted buckets of other replicas, merged into every read
+	peers map[string]*AggregatorSnapshot
 }
 
 // NewTimeAggregator creates a new time aggregator
@@ -141,6 +184,11 @@ type RLS struct {
 	tenants   map[string]*TenantState
 	tenantsMu sync.RWMutex
 
+	// 🔧 NEW: Version of the last bulk limits set, guarded by tenantsMu
+	limitsVersion LimitsSetVersion
+	// 🔧 NEW: Defaults that came with the bulk limits set, layered between DefaultLimits and profiles; guarded by tenantsMu
+	syncedDefaults limits.LimitOverrides
+
 	// Metrics
 	metrics *Metrics
 
@@ -163,6 +211,39 @@ type RLS struct {
 	// Time-based data aggregation
 	timeAggregator *TimeAggregator
 
+	// 🔧 NEW: Lock-free hand-off of decisions from Check to the analytics consumer
+	decisions *decisionPipeline
+
+	// 🔧 NEW: Optional persistence of aggregated analytics
+	aggregatorPersistence AggregatorPersistence
+
+	// 🔧 NEW: Cardinality alert rule engine
+	alerts *AlertEngine
+
+	// 🔧 NEW: Outbound notifications; notifier is nil when no webhooks are configured
+	notifier      *notify.Notifier
+	notifications *notificationState
+
+	// 🔧 NEW: Top-K cardinality explorer; nil when disabled
+	explorer *cardinality.Explorer
+
+	// 🔧 NEW: Forensic captures of denied and filtered series; nil when disabled
+	forensics *forensics.Store
+
+	// 🔧 NEW: Structured decision log; nil when no sink is configured
+	decisionLog      *decisionlog.Logger
+	decisionLogState decisionLogState
+
+	// 🔧 NEW: Flushes and stops the OTLP span exporter; nil when tracing is not configured
+	stopTracing func(context.Context) error
+
+	// 🔧 NEW: Bounded label values for RLS's own metrics
+	labels *metricLabeler
+
+	// 🔧 NEW: Elected HA replicas as last read from the store
+	haMu        sync.Mutex
+	haElections map[haClusterKey]haElection
+
 	// Cache for API responses
 	cacheMu sync.RWMutex
 	cache   map[string]*CacheEntry
@@ -171,6 +252,10 @@ type RLS struct {
 // TenantState represents the state of a tenant
 type TenantState struct {
 	Info           limits.TenantInfo
+	Enforcement    limits.EnforcementConfig         // The tenant's own enforcement; Info.Enforcement is its policy's while that sets one
+	Overrides      limits.LimitOverrides            // Limits explicitly set for this tenant by overrides-sync or the admin API
+	BaseLimits     limits.TenantLimits              // Defaults, profile and tenant overrides merged, before temporary overrides
+	LimitSources   map[string]limits.EffectiveValue // Effective value of each limit and the layer it came from
 	SamplesBucket  *buckets.TokenBucket
 	BytesBucket    *buckets.TokenBucket
 	RequestsBucket *buckets.TokenBucket
@@ -210,6 +295,28 @@ type Metrics struct {
 
 	// 🔧 NEW: Limit threshold metrics
 	LimitThresholdGauge *prometheus.GaugeVec
+
+	// 🔧 NEW: Decision recording pipeline metrics
+	DecisionEventsDropped *prometheus.CounterVec
+	DecisionLogRecords    *prometheus.CounterVec
+	TracingSpans          *prometheus.CounterVec
+	DecisionQueueDepth    *prometheus.GaugeVec
+
+	// 🔧 NEW: Notification delivery metrics
+	NotificationDeliveries *prometheus.CounterVec
+	NotificationsDropped   prometheus.Counter
+
+	// 🔧 NEW: Cardinality explorer metrics
+	CardinalityExplorerDropped prometheus.Counter
+
+	// 🔧 NEW: Requests of tenants in shadow mode that would have been denied
+	ShadowDenials *prometheus.CounterVec
+
+	// 🔧 NEW: Samples of non-elected HA replicas, not counted against the limits
+	HADeduplicatedSamples *prometheus.CounterVec
+
+	// 🔧 NEW: Label sets folded or skipped to bound RLS's own metrics
+	SelfMetricLabelSetsDropped *prometheus.CounterVec
 }
 
 // NewRLS creates a new RLS service
@@ -237,11 +344,26 @@ func NewRLS(config *RLSConfig, logger zerolog.Logger) *RLS {
 		recentDenials:  make([]limits.DenialInfo, 0, 1000),
 		trafficFlow:    &TrafficFlowState{ResponseTimes: make(map[string]float64)},
 		timeAggregator: NewTimeAggregator(),
+		decisions:      newDecisionPipeline(config.DecisionBufferSize, config.DecisionAllowSampleRate),
+		alerts:         NewAlertEngine(config.AlertRules),
+		notifications:  newNotificationState(),
 		cache:          make(map[string]*CacheEntry),
 		seriesCache:    make(map[string]*SeriesCacheEntry), // 🔧 NEW: Initialize series cache
+		haElections:    make(map[haClusterKey]haElection),
 	}
 
 	rls.metrics = rls.createMetrics()
+	rls.labels = newMetricLabeler(config.SelfMetrics, rls.metrics.SelfMetricLabelSetsDropped)
+
+	// 🔧 NEW: Restore aggregated analytics persisted by this and other replicas
+	rls.aggregatorPersistence, err = NewAggregatorPersistence(config.AggregatorPersistence, config.AggregatorSnapshotPath, config.RedisAddress)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to initialize aggregator persistence")
+	}
+	if rls.aggregatorPersistence != nil {
+		rls.restoreAggregator()
+		go rls.startAggregatorPersistenceLoop()
+	}
 
 	// Start periodic cleanup of expired cache entries
 	go func() {
@@ -258,6 +380,59 @@ func NewRLS(config *RLSConfig, logger zerolog.Logger) *RLS {
 	// Start periodic status logging
 	go rls.startPeriodicStatusLog()
 
+	// 🔧 NEW: Revert temporary overrides once they expire
+	go rls.startOverrideExpiryLoop()
+
+	// 🔧 NEW: Record decisions queued by Check
+	go rls.startDecisionConsumer()
+
+	// 🔧 NEW: Deliver enforcement notifications
+	rls.notifier = notify.NewNotifier(config.Notifications, notify.Metrics{
+		Deliveries: rls.metrics.NotificationDeliveries,
+		Dropped:    rls.metrics.NotificationsDropped,
+	}, logger)
+	rls.startNotifier()
+
+	// 🔧 NEW: Track top metrics and label names per tenant
+	rls.explorer = cardinality.NewExplorer(config.CardinalityExplorer, rls.metrics.CardinalityExplorerDropped)
+	if rls.explorer != nil {
+		go rls.explorer.Run()
+	}
+
+	// 🔧 NEW: Open the forensic capture store
+	if config.Forensics.Dir != "" {
+		// The enabled tenants are shared through Redis; a memory store is private to this replica, so the capture
+		// directory keeps them across restarts instead
+		var modes forensics.ModeStore
+		if config.StoreBackend == "redis" {
+			modes = rls.store
+		}
+		store, err := forensics.Open(config.Forensics, modes, logger)
+		if err != nil {
+			logger.Fatal().Err(err).Str("dir", config.Forensics.Dir).Msg("RLS: failed to open forensics store")
+		}
+		rls.forensics = store
+		go rls.forensics.Run()
+	}
+
+	// 🔧 NEW: Stream decision records to the configured sink
+	decisionLogger, err := decisionlog.NewLogger(config.DecisionLog, decisionlog.Metrics{
+		Records: rls.metrics.DecisionLogRecords,
+	}, logger)
+	if err != nil {
+		logger.Fatal().Err(err).Str("sink", config.DecisionLog.Sink).Msg("RLS: failed to create decision log")
+	}
+	rls.decisionLog = decisionLogger
+	rls.startDecisionLog()
+
+	// 🔧 NEW: Export spans of Check, parsing, Redis lookups and forwarding
+	rls.startTracing()
+
+	// 🔧 NEW: Evaluate cardinality alert rules
+	if config.AlertRules.EvaluationInterval > 0 {
+		go rls.startAlertEvaluationLoop()
+	}
+
 	return rls
 }
 
@@ -405,11 +580,91 @@ func (rls *RLS) createMetrics() *Metrics {
 			},
 			[]string{"tenant", "limit"},
 		),
+		DecisionEventsDropped: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rls_decision_events_dropped_total",
+				Help: "Decision events dropped because the recording ring buffer was full",
+			},
+			[]string{"decision"},
+		),
+		DecisionLogRecords: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rls_decision_log_records_total",
+				Help: "Decision log records by result (written, dropped because the queue was full, failed in the sink)",
+			},
+			[]string{"result"},
+		),
+		TracingSpans: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rls_tracing_spans_total",
+				Help: "Sampled spans handed to the OTLP exporter by result (exported, failed)",
+			},
+			[]string{"result"},
+		),
+		DecisionQueueDepth: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "rls_decision_queue_depth",
+				Help: "Decision events waiting to be recorded",
+			},
+			[]string{"decision"},
+		),
+		NotificationDeliveries: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rls_notification_deliveries_total",
+				Help: "Notification batches delivered to webhooks, by sink and result",
+			},
+			[]string{"sink", "result"},
+		),
+		NotificationsDropped: promauto.NewCounter(
+			prometheus.CounterOpts{
+				Name: "rls_notifications_dropped_total",
+				Help: "Notification events dropped because the notification queue was full",
+			},
+		),
+		CardinalityExplorerDropped: promauto.NewCounter(
+			prometheus.CounterOpts{
+				Name: "rls_cardinality_explorer_dropped_total",
+				Help: "Sampled requests not tracked by the cardinality explorer because its queue was full",
+			},
+		),
+		ShadowDenials: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rls_shadow_denials_total",
+				Help: "Requests allowed only because their tenant's policy is in shadow mode, by tenant and reason",
+			},
+			[]string{"tenant", "reason"},
+		),
+		HADeduplicatedSamples: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rls_ha_deduplicated_samples_total",
+				Help: "Samples from HA replicas that are not the elected replica of their cluster, by tenant; they do not count against the limits",
+			},
+			[]string{"tenant"},
+		),
+		SelfMetricLabelSetsDropped: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rls_self_metric_label_sets_dropped_total",
+				Help: "Distinct label sets of RLS's own metrics folded into an overflow value or not exported, by cause (tenant_overflow, unknown_reason, metric_top_k)",
+			},
+			[]string{"cause"},
+		),
 	}
 }
 
 // Check implements the ext_authz service
 func (rls *RLS) Check(ctx context.Context, req *envoy_service_auth_v3.CheckRequest) (*envoy_service_auth_v3.CheckResponse, error) {
+	// 🔧 NEW: Continue the trace Envoy started, if its headers carry one
+	ctx = tracing.Extract(ctx, propagation.MapCarrier(req.GetAttributes().GetRequest().GetHttp().GetHeaders()))
+	ctx, span := tracing.Start(ctx, "rls.Check", trace.SpanKindServer)
+	defer span.End()
+
+	response, err := rls.check(ctx, req)
+	span.SetAttributes(attribute.Bool("rls.allowed", response.GetStatus().GetCode() == 0))
+	return response, err
+}
+
+// check makes the ext_authz decision; Check wraps it in the request span
+func (rls *RLS) check(ctx context.Context, req *envoy_service_auth_v3.CheckRequest) (*envoy_service_auth_v3.CheckResponse, error) {
 	start := time.Now()
 
 	// 🔧 DEBUG MODE: Add panic recovery for troubleshooting
@@ -432,7 +687,7 @@ func (rls *RLS) Check(ctx context.Context, req *envoy_service_auth_v3.CheckReque
 	// Extract tenant ID from headers
 	tenantID := rls.extractTenantID(req)
 	if tenantID == "" {
-		rls.metrics.DecisionsTotal.WithLabelValues("deny", "unknown", "missing_tenant_header").Inc()
+		rls.metrics.DecisionsTotal.WithLabelValues("deny", "unknown", rls.labels.reason("missing_tenant_header")).Inc()
 		rls.metrics.TrafficFlowTotal.WithLabelValues("unknown", "deny").Inc()
 		rls.metrics.TrafficFlowLatency.WithLabelValues("unknown", "deny").Observe(time.Since(start).Seconds())
 		return rls.denyResponse("missing tenant header", http.StatusBadRequest), nil
@@ -440,13 +695,15 @@ func (rls *RLS) Check(ctx context.Context, req *envoy_service_auth_v3.CheckReque
 
 	// 🔥 ULTRA-FAST PATH: Get tenant state with minimal logging
 	tenant := rls.getTenant(tenantID)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("tenant.id", tenantID))
 
 	// Check if enforcement is enabled
 	if !tenant.Info.Enforcement.Enabled {
-		rls.metrics.DecisionsTotal.WithLabelValues("allow", tenantID, "enforcement_disabled").Inc()
-		rls.metrics.TrafficFlowTotal.WithLabelValues(tenantID, "allow").Inc()
-		rls.metrics.TrafficFlowLatency.WithLabelValues(tenantID, "allow").Observe(time.Since(start).Seconds())
-		rls.metrics.AuthzCheckDuration.WithLabelValues(tenantID).Observe(time.Since(start).Seconds())
+		rls.metrics.DecisionsTotal.WithLabelValues("allow", rls.labels.tenant(tenantID), rls.labels.reason("enforcement_disabled")).Inc()
+		rls.metrics.TrafficFlowTotal.WithLabelValues(rls.labels.tenant(tenantID), "allow").Inc()
+		rls.metrics.TrafficFlowLatency.WithLabelValues(rls.labels.tenant(tenantID), "allow").Observe(time.Since(start).Seconds())
+		rls.metrics.AuthzCheckDuration.WithLabelValues(rls.labels.tenant(tenantID)).Observe(time.Since(start).Seconds())
+		rls.enqueueDecision(tenantID, true, "enforcement_disabled", 0, int64(len(req.Attributes.Request.Http.Body)), nil, nil, start)
 		return rls.allowResponse(), nil
 	}
 
@@ -455,19 +712,21 @@ func (rls *RLS) Check(ctx context.Context, req *envoy_service_auth_v3.CheckReque
 
 	// 🔥 ULTRA-FAST PATH: Skip parsing for very small requests (likely health checks)
 	if bodyBytes < 100 {
-		rls.metrics.DecisionsTotal.WithLabelValues("allow", tenantID, "small_request").Inc()
-		rls.metrics.TrafficFlowTotal.WithLabelValues(tenantID, "allow").Inc()
-		rls.metrics.TrafficFlowLatency.WithLabelValues(tenantID, "allow").Observe(time.Since(start).Seconds())
-		rls.metrics.AuthzCheckDuration.WithLabelValues(tenantID).Observe(time.Since(start).Seconds())
+		rls.metrics.DecisionsTotal.WithLabelValues("allow", rls.labels.tenant(tenantID), rls.labels.reason("small_request")).Inc()
+		rls.metrics.TrafficFlowTotal.WithLabelValues(rls.labels.tenant(tenantID), "allow").Inc()
+		rls.metrics.TrafficFlowLatency.WithLabelValues(rls.labels.tenant(tenantID), "allow").Observe(time.Since(start).Seconds())
+		rls.metrics.AuthzCheckDuration.WithLabelValues(rls.labels.tenant(tenantID)).Observe(time.Since(start).Seconds())
+		rls.enqueueDecision(tenantID, true, "small_request", 0, bodyBytes, nil, nil, start)
 		return rls.allowResponse(), nil
 	}
 
 	// 🔥 ULTRA-FAST PATH: Skip parsing for very large requests to prevent timeouts
 	// Use configured MaxRequestBytes instead of hardcoded 10MB limit
 	if rls.config.MaxRequestBytes > 0 && bodyBytes > rls.config.MaxRequestBytes {
-		rls.metrics.DecisionsTotal.WithLabelValues("deny", tenantID, "request_too_large").Inc()
-		rls.metrics.TrafficFlowTotal.WithLabelValues(tenantID, "deny").Inc()
-		rls.metrics.TrafficFlowLatency.WithLabelValues(tenantID, "deny").Observe(time.Since(start).Seconds())
+		rls.metrics.DecisionsTotal.WithLabelValues("deny", rls.labels.tenant(tenantID), rls.labels.reason("request_too_large")).Inc()
+		rls.metrics.TrafficFlowTotal.WithLabelValues(rls.labels.tenant(tenantID), "deny").Inc()
+		rls.metrics.TrafficFlowLatency.WithLabelValues(rls.labels.tenant(tenantID), "deny").Observe(time.Since(start).Seconds())
+		rls.enqueueDecision(tenantID, false, "request_too_large", 0, bodyBytes, nil, nil, start)
 		return rls.denyResponse("request body too large", http.StatusRequestEntityTooLarge), nil
 	}
 
@@ -477,7 +736,10 @@ func (rls *RLS) Check(ctx context.Context, req *envoy_service_auth_v3.CheckReque
 	var result *parser.ParseResult
 
 	if rls.config.EnforceBodyParsing {
+		_, extractSpan := tracing.Start(ctx, "rls.extract_body", trace.SpanKindInternal)
 		body, err := rls.extractBody(req)
+		tracing.RecordError(extractSpan, err)
+		extractSpan.End()
 		if err != nil {
 			// 🔧 PERFORMANCE OPTIMIZATION: Quick fallback for body extraction failures
 			if rls.config.FailureModeAllow {
@@ -489,31 +751,34 @@ func (rls *RLS) Check(ctx context.Context, req *envoy_service_auth_v3.CheckReque
 					MetricSeriesCounts: make(map[string]int64),
 				}
 
-				decision := rls.checkLimits(tenant, fallbackSamples, bodyBytes, fallbackRequestInfo)
+				decision := rls.checkLimits(ctx, tenant, fallbackSamples, bodyBytes, fallbackRequestInfo)
 
 				if !decision.Allowed {
-					rls.metrics.DecisionsTotal.WithLabelValues("deny", tenantID, "body_extract_failed_limit_exceeded").Inc()
-					rls.metrics.TrafficFlowTotal.WithLabelValues(tenantID, "deny").Inc()
-					rls.metrics.TrafficFlowLatency.WithLabelValues(tenantID, "deny").Observe(time.Since(start).Seconds())
+					rls.metrics.DecisionsTotal.WithLabelValues("deny", rls.labels.tenant(tenantID), rls.labels.reason("body_extract_failed_limit_exceeded")).Inc()
+					rls.metrics.TrafficFlowTotal.WithLabelValues(rls.labels.tenant(tenantID), "deny").Inc()
+					rls.metrics.TrafficFlowLatency.WithLabelValues(rls.labels.tenant(tenantID), "deny").Observe(time.Since(start).Seconds())
+					rls.enqueueDecision(tenantID, false, decision.Reason, fallbackSamples, bodyBytes, fallbackRequestInfo, nil, start)
 					return rls.denyResponse(decision.Reason, int32(decision.Code)), nil
 				}
 
-				rls.metrics.DecisionsTotal.WithLabelValues("allow", tenantID, "body_extract_failed_allow").Inc()
-				rls.metrics.TrafficFlowTotal.WithLabelValues(tenantID, "allow").Inc()
-				rls.metrics.TrafficFlowLatency.WithLabelValues(tenantID, "allow").Observe(time.Since(start).Seconds())
-				rls.metrics.AuthzCheckDuration.WithLabelValues(tenantID).Observe(time.Since(start).Seconds())
+				rls.metrics.DecisionsTotal.WithLabelValues("allow", rls.labels.tenant(tenantID), rls.labels.reason("body_extract_failed_allow")).Inc()
+				rls.metrics.TrafficFlowTotal.WithLabelValues(rls.labels.tenant(tenantID), "allow").Inc()
+				rls.metrics.TrafficFlowLatency.WithLabelValues(rls.labels.tenant(tenantID), "allow").Observe(time.Since(start).Seconds())
+				rls.metrics.AuthzCheckDuration.WithLabelValues(rls.labels.tenant(tenantID)).Observe(time.Since(start).Seconds())
+				rls.enqueueDecision(tenantID, true, "body_extract_failed_allow", fallbackSamples, bodyBytes, fallbackRequestInfo, nil, start)
 				return rls.allowResponse(), nil
 			}
-			rls.metrics.DecisionsTotal.WithLabelValues("deny", tenantID, "body_extract_failed").Inc()
-			rls.metrics.TrafficFlowTotal.WithLabelValues(tenantID, "deny").Inc()
-			rls.metrics.TrafficFlowLatency.WithLabelValues(tenantID, "deny").Observe(time.Since(start).Seconds())
+			rls.metrics.DecisionsTotal.WithLabelValues("deny", rls.labels.tenant(tenantID), rls.labels.reason("body_extract_failed")).Inc()
+			rls.metrics.TrafficFlowTotal.WithLabelValues(rls.labels.tenant(tenantID), "deny").Inc()
+			rls.metrics.TrafficFlowLatency.WithLabelValues(rls.labels.tenant(tenantID), "deny").Observe(time.Since(start).Seconds())
+			rls.enqueueDecision(tenantID, false, "body_extract_failed", 0, bodyBytes, nil, nil, start)
 			return rls.denyResponse("failed to extract request body", http.StatusBadRequest), nil
 		}
 
 		// 🔥 ULTRA-FAST PATH: Parse remote write request with ultra-fast timeout
 		contentEncoding := rls.extractContentEncoding(req)
 
-		result, err = parser.ParseRemoteWriteRequest(body, contentEncoding)
+		result, err = parser.ParseRemoteWriteRequestContext(ctx, body, contentEncoding)
 		if err != nil {
 			rls.metrics.BodyParseErrors.Inc()
 
@@ -526,24 +791,29 @@ func (rls *RLS) Check(ctx context.Context, req *envoy_service_auth_v3.CheckReque
 				MetricSeriesCounts: make(map[string]int64),
 			}
 
-			decision := rls.checkLimits(tenant, fallbackSamples, bodyBytes, fallbackRequestInfo)
+			decision := rls.checkLimits(ctx, tenant, fallbackSamples, bodyBytes, fallbackRequestInfo)
 
 			if !decision.Allowed {
-				rls.metrics.DecisionsTotal.WithLabelValues("deny", tenantID, "parse_failed_limit_exceeded").Inc()
-				rls.metrics.TrafficFlowTotal.WithLabelValues(tenantID, "deny").Inc()
-				rls.metrics.TrafficFlowLatency.WithLabelValues(tenantID, "deny").Observe(time.Since(start).Seconds())
+				rls.metrics.DecisionsTotal.WithLabelValues("deny", rls.labels.tenant(tenantID), rls.labels.reason("parse_failed_limit_exceeded")).Inc()
+				rls.metrics.TrafficFlowTotal.WithLabelValues(rls.labels.tenant(tenantID), "deny").Inc()
+				rls.metrics.TrafficFlowLatency.WithLabelValues(rls.labels.tenant(tenantID), "deny").Observe(time.Since(start).Seconds())
+				rls.enqueueDecision(tenantID, false, decision.Reason, fallbackSamples, bodyBytes, fallbackRequestInfo, nil, start)
 				return rls.denyResponse(decision.Reason, int32(decision.Code)), nil
 			}
 
-			rls.metrics.DecisionsTotal.WithLabelValues("allow", tenantID, "parse_failed_allow").Inc()
-			rls.metrics.TrafficFlowTotal.WithLabelValues(tenantID, "allow").Inc()
-			rls.metrics.TrafficFlowLatency.WithLabelValues(tenantID, "allow").Observe(time.Since(start).Seconds())
-			rls.metrics.AuthzCheckDuration.WithLabelValues(tenantID).Observe(time.Since(start).Seconds())
+			rls.metrics.DecisionsTotal.WithLabelValues("allow", rls.labels.tenant(tenantID), rls.labels.reason("parse_failed_allow")).Inc()
+			rls.metrics.TrafficFlowTotal.WithLabelValues(rls.labels.tenant(tenantID), "allow").Inc()
+			rls.metrics.TrafficFlowLatency.WithLabelValues(rls.labels.tenant(tenantID), "allow").Observe(time.Since(start).Seconds())
+			rls.metrics.AuthzCheckDuration.WithLabelValues(rls.labels.tenant(tenantID)).Observe(time.Since(start).Seconds())
+			rls.enqueueDecision(tenantID, true, "parse_failed_allow", fallbackSamples, bodyBytes, fallbackRequestInfo, nil, start)
 			return rls.allowResponse(), nil
 		}
 
 		samples = result.SamplesCount
 
+		// 🔧 NEW: Hand the series to the cardinality explorer without blocking
+		rls.explorer.Observe(tenantID, result.Series)
+
 		// 🔧 PERFORMANCE OPTIMIZATION: Simplified request info creation
 		requestInfo = &limits.RequestInfo{
 			ObservedSamples:    result.SamplesCount,
@@ -551,6 +821,7 @@ func (rls *RLS) Check(ctx context.Context, req *envoy_service_auth_v3.CheckReque
 			ObservedLabels:     result.LabelsCount,
 			MetricSeriesCounts: rls.extractMetricSeriesCounts(result),
 		}
+		requestInfo.HACluster, requestInfo.HAReplica = haLabels(tenant, result.Series)
 	} else {
 		// Use content length as a proxy for request size
 		samples = 1
@@ -565,24 +836,28 @@ func (rls *RLS) Check(ctx context.Context, req *envoy_service_auth_v3.CheckReque
 	}
 
 	// Check limits with cardinality controls
-	decision := rls.checkLimits(tenant, samples, bodyBytes, requestInfo)
+	decision := rls.checkLimits(ctx, tenant, samples, bodyBytes, requestInfo)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("rls.reason", decision.Reason))
 
 	// 🔧 PERFORMANCE OPTIMIZATION: Simplified metrics recording
 	decisionType := "allow"
 	if !decision.Allowed {
 		decisionType = "deny"
 	}
-	rls.metrics.DecisionsTotal.WithLabelValues(decisionType, tenantID, decision.Reason).Inc()
-	rls.metrics.TrafficFlowTotal.WithLabelValues(tenantID, decisionType).Inc()
-	rls.metrics.TrafficFlowLatency.WithLabelValues(tenantID, decisionType).Observe(time.Since(start).Seconds())
+	rls.metrics.DecisionsTotal.WithLabelValues(decisionType, rls.labels.tenant(tenantID), rls.labels.reason(decision.Reason)).Inc()
+	rls.metrics.TrafficFlowTotal.WithLabelValues(rls.labels.tenant(tenantID), decisionType).Inc()
+	rls.metrics.TrafficFlowLatency.WithLabelValues(rls.labels.tenant(tenantID), decisionType).Observe(time.Since(start).Seconds())
 
-	// 🔧 PERFORMANCE OPTIMIZATION: Disable expensive operations
-	// rls.updateTrafficFlowState(time.Since(start).Seconds(), decision.Allowed)
-	// rls.recordDecision(tenantID, decision.Allowed, decision.Reason, samples, bodyBytes, requestInfo, nil, nil)
+	// 🔧 PERFORMANCE OPTIMIZATION: Counters, recent denials and traffic flow are updated off the hot path
+	rls.enqueueDecision(tenantID, decision.Allowed, decision.Reason, samples, bodyBytes, requestInfo, result, start)
 
-	rls.metrics.AuthzCheckDuration.WithLabelValues(tenantID).Observe(time.Since(start).Seconds())
+	rls.metrics.AuthzCheckDuration.WithLabelValues(rls.labels.tenant(tenantID)).Observe(time.Since(start).Seconds())
 
 	if !decision.Allowed {
+		// 🔧 NEW: Keep the offending series of tenants in forensic mode
+		if result != nil && decision.Reason != haNotElectedReason {
+			rls.forensics.Submit(tenantID, forensics.SourceCheck, decision.Reason, result.Series)
+		}
 		return rls.denyResponse(decision.Reason, int32(decision.Code)), nil
 	}
 
@@ -607,19 +882,9 @@ func convertParserMetrics(parserMetrics []parser.SampleMetricDetail) []limits.Sa
 	return result
 }
 
-// recordDecision updates in-memory counters and recent denials for admin API
-func (rls *RLS) recordDecision(tenantID string, allowed bool, reason string, samples int64, bodyBytes int64, requestInfo *limits.RequestInfo, sampleMetrics []limits.SampleMetric, parseInfo *limits.ParseDiagnostics) {
-	// 🔧 DEBUG: Add logging to track decision recording
-	rls.logger.Info().
-		Str("tenant", tenantID).
-		Bool("allowed", allowed).
-		Str("reason", reason).
-		Int64("samples", samples).
-		Int64("body_bytes", bodyBytes).
-		Msg("RLS: INFO - Recording decision")
-
-	// Record in time aggregator for stable time-based data
-	now := time.Now()
+// recordDecision updates in-memory counters and recent denials for admin API.
+// It runs on the decision consumer goroutine; weight is the number of decisions a sampled event stands for.
+func (rls *RLS) recordDecision(tenantID string, allowed bool, reason string, samples int64, bodyBytes int64, requestInfo *limits.RequestInfo, sampleMetrics []limits.SampleMetric, parseInfo *limits.ParseDiagnostics, timestamp time.Time, responseTime float64, weight int64) {
 	series := int64(0)
 	labels := int64(0)
 	violation := false
@@ -634,8 +899,8 @@ func (rls *RLS) recordDecision(tenantID string, allowed bool, reason string, sam
 		violation = true
 	}
 
-	// Record in time aggregator (response time will be calculated separately)
-	rls.timeAggregator.RecordDecision(tenantID, now, allowed, series, labels, 0.0, violation)
+	// Record in time aggregator for stable time-based data
+	rls.timeAggregator.RecordDecisions(tenantID, timestamp, allowed, weight, series, labels, responseTime, violation)
 
 	rls.countersMu.Lock()
 	defer rls.countersMu.Unlock()
@@ -644,51 +909,43 @@ func (rls *RLS) recordDecision(tenantID string, allowed bool, reason string, sam
 		c = &TenantCounters{}
 		rls.counters[tenantID] = c
 	}
-	c.Total++
+	c.Total += weight
 	if allowed {
-		c.Allowed++
-		rls.logger.Info().
-			Str("tenant", tenantID).
-			Int64("total", c.Total).
-			Int64("allowed", c.Allowed).
-			Int64("denied", c.Denied).
-			Msg("RLS: INFO - Updated counters (allowed)")
-	} else {
-		c.Denied++
-		// 🔧 PERFORMANCE FIX: Use ring buffer for recent denials to avoid array resizing
-		di := limits.DenialInfo{
-			TenantID:          tenantID,
-			Reason:            reason,
-			Timestamp:         time.Now(),
-			ObservedSamples:   samples,
-			ObservedBodyBytes: bodyBytes,
-			SampleMetrics:     sampleMetrics,
-		}
+		c.Allowed += weight
+		return
+	}
 
-		// Add cardinality data if available
-		if requestInfo != nil {
-			di.ObservedSeries = requestInfo.ObservedSeries
-			di.ObservedLabels = requestInfo.ObservedLabels
-		}
+	c.Denied += weight
+	di := limits.DenialInfo{
+		TenantID:          tenantID,
+		Reason:            reason,
+		Timestamp:         timestamp,
+		ObservedSamples:   samples,
+		ObservedBodyBytes: bodyBytes,
+		SampleMetrics:     sampleMetrics,
+	}
 
-		// Attach parse diagnostics if any
-		if parseInfo != nil {
-			di.ParseInfo = parseInfo
-		}
+	// Add cardinality data if available
+	if requestInfo != nil {
+		di.ObservedSeries = requestInfo.ObservedSeries
+		di.ObservedLabels = requestInfo.ObservedLabels
+	}
 
-		rls.recentDenials = append(rls.recentDenials, di)
-		// Keep only last 1000 denials to prevent memory growth (increased from 500)
-		if len(rls.recentDenials) > 1000 {
-			rls.recentDenials = rls.recentDenials[len(rls.recentDenials)-1000:]
-		}
-		rls.logger.Info().
-			Str("tenant", tenantID).
-			Int64("total", c.Total).
-			Int64("allowed", c.Allowed).
-			Int64("denied", c.Denied).
-			Int("total_denials", len(rls.recentDenials)).
-			Msg("RLS: INFO - Updated counters and added denial")
+	// Attach parse diagnostics if any
+	if parseInfo != nil {
+		di.ParseInfo = parseInfo
+	}
+
+	rls.recentDenials = append(rls.recentDenials, di)
+	// Keep only last 1000 denials to prevent memory growth (increased from 500)
+	if len(rls.recentDenials) > 1000 {
+		rls.recentDenials = rls.recentDenials[len(rls.recentDenials)-1000:]
 	}
+	rls.logger.Debug().
+		Str("tenant", tenantID).
+		Str("reason", reason).
+		Int64("denied", c.Denied).
+		Msg("RLS: recorded denial")
 }
 
 // TenantCounters holds simple aggregates per tenant
@@ -1506,19 +1763,38 @@ func (rls *RLS) getTenant(tenantID string) *TenantState {
 		// Tenant exists in store, create TenantState from it
 		tenant = &TenantState{
 			Info: limits.TenantInfo{
-				ID:          storeData.ID,
-				Name:        storeData.Name,
-				Limits:      storeData.Limits,
-				Enforcement: storeData.Enforcement,
+				ID:      storeData.ID,
+				Name:    storeData.Name,
+				Profile: storeData.Profile,
+				Policy:  storeData.Policy,
 			},
+			Enforcement: storeData.Enforcement,
+		}
+		// 🔧 NEW: Tenants persisted before their own enforcement was kept apart only have the effective one
+		if storeData.TenantEnforcement != nil {
+			tenant.Enforcement = *storeData.TenantEnforcement
+		}
+		rls.applyEnforcement(tenant)
+
+		// 🔧 NEW: Tenants persisted before layered limits only have merged limits; treat them as fully explicit
+		if storeData.Overrides != nil {
+			tenant.Overrides = *storeData.Overrides
+		} else {
+			tenant.Overrides = limits.FullOverrides(storeData.Limits)
+		}
+
+		// 🔧 NEW: Restore temporary overrides that have not expired yet
+		if storeData.TemporaryOverride.Active(time.Now()) {
+			tenant.Info.TemporaryOverride = storeData.TemporaryOverride
 		}
+		rls.resolveTenantLimits(tenant)
 
 		// Create buckets if limits are set
-		if storeData.Limits.SamplesPerSecond > 0 {
-			tenant.SamplesBucket = buckets.NewTokenBucket(storeData.Limits.SamplesPerSecond, storeData.Limits.SamplesPerSecond)
+		if tenant.Info.Limits.SamplesPerSecond > 0 {
+			tenant.SamplesBucket = buckets.NewTokenBucket(tenant.Info.Limits.SamplesPerSecond, samplesBucketCapacity(tenant.Info.Limits))
 		}
-		if storeData.Limits.MaxBodyBytes > 0 {
-			tenant.BytesBucket = buckets.NewTokenBucket(float64(storeData.Limits.MaxBodyBytes), float64(storeData.Limits.MaxBodyBytes))
+		if tenant.Info.Limits.MaxBodyBytes > 0 {
+			tenant.BytesBucket = buckets.NewTokenBucket(float64(tenant.Info.Limits.MaxBodyBytes), float64(tenant.Info.Limits.MaxBodyBytes))
 		}
 
 		rls.logger.Info().Str("tenant_id", tenantID).Msg("RLS: loaded tenant from store")
@@ -1531,21 +1807,15 @@ func (rls *RLS) getTenant(tenantID string) *TenantState {
 		// These limits will be overridden by overrides-sync when it runs
 		tenant = &TenantState{
 			Info: limits.TenantInfo{
-				ID:   tenantID,
-				Name: tenantID,
-				// 🔧 FIX: Use default limits to prevent 503 errors
-				Limits:      rls.config.DefaultLimits,
+				ID:          tenantID,
+				Name:        tenantID,
 				Enforcement: rls.config.DefaultEnforcement,
 			},
+			Enforcement: rls.config.DefaultEnforcement,
 		}
-
-		// Create buckets with default limits
-		if rls.config.DefaultLimits.SamplesPerSecond > 0 {
-			tenant.SamplesBucket = buckets.NewTokenBucket(rls.config.DefaultLimits.SamplesPerSecond, rls.config.DefaultLimits.SamplesPerSecond)
-		}
-		if rls.config.DefaultLimits.MaxBodyBytes > 0 {
-			tenant.BytesBucket = buckets.NewTokenBucket(float64(rls.config.DefaultLimits.MaxBodyBytes), float64(rls.config.DefaultLimits.MaxBodyBytes))
-		}
+		// 🔧 FIX: Resolve the synced defaults on top of the CLI defaults, and enforce the result
+		rls.resolveTenantLimits(tenant)
+		rls.updateTenantBuckets(tenantID, tenant, tenant.Info.Limits)
 
 		rls.logger.Info().Str("tenant_id", tenantID).Msg("RLS: created new tenant with default limits")
 	}
@@ -1555,7 +1825,25 @@ func (rls *RLS) getTenant(tenantID string) *TenantState {
 }
 
 // checkLimits checks if the request exceeds any limits
-func (rls *RLS) checkLimits(tenant *TenantState, samples int64, bodyBytes int64, requestInfo *limits.RequestInfo) limits.Decision {
+func (rls *RLS) checkLimits(ctx context.Context, tenant *TenantState, samples int64, bodyBytes int64, requestInfo *limits.RequestInfo) limits.Decision {
+	// 🔧 NEW: Only the elected replica of an HA cluster counts against the limits
+	decision, deduplicated := rls.checkHAReplica(ctx, tenant, requestInfo)
+	if !deduplicated {
+		decision = rls.evaluateLimits(ctx, tenant, samples, bodyBytes, requestInfo)
+	}
+	// 🔧 NEW: Tenants in shadow mode are only observed
+	if !decision.Allowed && tenant.Info.Policy.InShadowMode() {
+		rls.metrics.ShadowDenials.WithLabelValues(rls.labels.tenant(tenant.Info.ID), rls.labels.reason(decision.Reason)).Inc()
+		return limits.Decision{Allowed: true, Reason: "shadow_mode", Code: 200}
+	}
+	return decision
+}
+
+// evaluateLimits decides whether a request is within the tenant's limits
+func (rls *RLS) evaluateLimits(ctx context.Context, tenant *TenantState, samples int64, bodyBytes int64, requestInfo *limits.RequestInfo) limits.Decision {
+	ctx, span := tracing.Start(ctx, "rls.check_limits", trace.SpanKindInternal)
+	defer span.End()
+
 	// 🔧 MIMIR-STYLE CARDINALITY LIMITS: Track global series counts per tenant and per metric
 	// Mimir counts total unique series across the entire tenant's time series database
 	// We need to track this globally and enforce limits when new series are created
@@ -1583,8 +1871,8 @@ func (rls *RLS) checkLimits(tenant *TenantState, samples int64, bodyBytes int64,
 		}()
 
 		rls.tenantsMu.RLock()
-		currentTenantSeries = rls.getTenantGlobalSeriesCount(tenant.Info.ID)
-		currentMetricSeries = rls.getTenantMetricSeriesCount(tenant.Info.ID, requestInfo)
+		currentTenantSeries = rls.getTenantGlobalSeriesCount(ctx, tenant.Info.ID)
+		currentMetricSeries = rls.getTenantMetricSeriesCount(ctx, tenant.Info.ID, requestInfo)
 		rls.tenantsMu.RUnlock()
 	}()
 
@@ -1636,9 +1924,9 @@ func (rls *RLS) checkLimits(tenant *TenantState, samples int64, bodyBytes int64,
 				Msg("DEBUG: Cardinality check - per-user series limit exceeded")
 
 			// 🔧 NEW: Record limit violation metrics
-			rls.metrics.LimitViolationsTotal.WithLabelValues(tenant.Info.ID, "per_user_series_limit_exceeded").Inc()
-			rls.metrics.SeriesCountGauge.WithLabelValues(tenant.Info.ID).Set(float64(projectedTotalSeries))
-			rls.metrics.LimitThresholdGauge.WithLabelValues(tenant.Info.ID, "max_series_per_request").Set(float64(tenant.Info.Limits.MaxSeriesPerRequest))
+			rls.metrics.LimitViolationsTotal.WithLabelValues(rls.labels.tenant(tenant.Info.ID), "per_user_series_limit_exceeded").Inc()
+			rls.metrics.SeriesCountGauge.WithLabelValues(rls.labels.tenant(tenant.Info.ID)).Set(float64(projectedTotalSeries))
+			rls.metrics.LimitThresholdGauge.WithLabelValues(rls.labels.tenant(tenant.Info.ID), "max_series_per_request").Set(float64(tenant.Info.Limits.MaxSeriesPerRequest))
 
 			decision.Allowed = false
 			decision.Reason = "per_user_series_limit_exceeded"
@@ -1673,9 +1961,9 @@ func (rls *RLS) checkLimits(tenant *TenantState, samples int64, bodyBytes int64,
 					Msg("DEBUG: Cardinality check - per-metric series limit exceeded")
 
 				// 🔧 NEW: Record limit violation metrics
-				rls.metrics.LimitViolationsTotal.WithLabelValues(tenant.Info.ID, "per_metric_series_limit_exceeded").Inc()
-				rls.metrics.MetricSeriesCountGauge.WithLabelValues(tenant.Info.ID, metricName).Set(float64(projectedMetricTotal))
-				rls.metrics.LimitThresholdGauge.WithLabelValues(tenant.Info.ID, "max_series_per_metric").Set(float64(tenant.Info.Limits.MaxSeriesPerMetric))
+				rls.metrics.LimitViolationsTotal.WithLabelValues(rls.labels.tenant(tenant.Info.ID), "per_metric_series_limit_exceeded").Inc()
+				rls.labels.setMetricSeries(rls.metrics.MetricSeriesCountGauge, tenant.Info.ID, metricName, float64(projectedMetricTotal))
+				rls.metrics.LimitThresholdGauge.WithLabelValues(rls.labels.tenant(tenant.Info.ID), "max_series_per_metric").Set(float64(tenant.Info.Limits.MaxSeriesPerMetric))
 
 				decision.Allowed = false
 				decision.Reason = "per_metric_series_limit_exceeded"
@@ -1711,9 +1999,9 @@ func (rls *RLS) checkLimits(tenant *TenantState, samples int64, bodyBytes int64,
 
 		if bodyBytes > effectiveBodyLimit {
 			// 🔧 NEW: Record limit violation metrics
-			rls.metrics.LimitViolationsTotal.WithLabelValues(tenant.Info.ID, "body_size_exceeded").Inc()
-			rls.metrics.BodySizeGauge.WithLabelValues(tenant.Info.ID).Set(float64(bodyBytes))
-			rls.metrics.LimitThresholdGauge.WithLabelValues(tenant.Info.ID, "max_body_bytes").Set(float64(tenant.Info.Limits.MaxBodyBytes))
+			rls.metrics.LimitViolationsTotal.WithLabelValues(rls.labels.tenant(tenant.Info.ID), "body_size_exceeded").Inc()
+			rls.metrics.BodySizeGauge.WithLabelValues(rls.labels.tenant(tenant.Info.ID)).Set(float64(bodyBytes))
+			rls.metrics.LimitThresholdGauge.WithLabelValues(rls.labels.tenant(tenant.Info.ID), "max_body_bytes").Set(float64(tenant.Info.Limits.MaxBodyBytes))
 
 			decision.Allowed = false
 			decision.Reason = "body_size_exceeded"
@@ -1725,9 +2013,9 @@ func (rls *RLS) checkLimits(tenant *TenantState, samples int64, bodyBytes int64,
 	if tenant.Info.Enforcement.EnforceMaxLabelsPerSeries && tenant.Info.Limits.MaxLabelsPerSeries > 0 {
 		if requestInfo.ObservedLabels > int64(tenant.Info.Limits.MaxLabelsPerSeries) {
 			// 🔧 NEW: Record limit violation metrics
-			rls.metrics.LimitViolationsTotal.WithLabelValues(tenant.Info.ID, "labels_per_series_exceeded").Inc()
-			rls.metrics.LabelsCountGauge.WithLabelValues(tenant.Info.ID).Set(float64(requestInfo.ObservedLabels))
-			rls.metrics.LimitThresholdGauge.WithLabelValues(tenant.Info.ID, "max_labels_per_series").Set(float64(tenant.Info.Limits.MaxLabelsPerSeries))
+			rls.metrics.LimitViolationsTotal.WithLabelValues(rls.labels.tenant(tenant.Info.ID), "labels_per_series_exceeded").Inc()
+			rls.metrics.LabelsCountGauge.WithLabelValues(rls.labels.tenant(tenant.Info.ID)).Set(float64(requestInfo.ObservedLabels))
+			rls.metrics.LimitThresholdGauge.WithLabelValues(rls.labels.tenant(tenant.Info.ID), "max_labels_per_series").Set(float64(tenant.Info.Limits.MaxLabelsPerSeries))
 
 			decision.Allowed = false
 			decision.Reason = "labels_per_series_exceeded"
@@ -1753,8 +2041,8 @@ func (rls *RLS) checkLimits(tenant *TenantState, samples int64, bodyBytes int64,
 			recoverySamples := int64(float64(samples) * 1.5)
 			if !tenant.SamplesBucket.Take(float64(recoverySamples)) {
 				// Still denied, but with recovery logging
-				rls.metrics.LimitViolationsTotal.WithLabelValues(tenant.Info.ID, "samples_per_second_exceeded_recovery").Inc()
-				rls.metrics.SamplesCountGauge.WithLabelValues(tenant.Info.ID).Set(float64(samples))
+				rls.metrics.LimitViolationsTotal.WithLabelValues(rls.labels.tenant(tenant.Info.ID), "samples_per_second_exceeded_recovery").Inc()
+				rls.metrics.SamplesCountGauge.WithLabelValues(rls.labels.tenant(tenant.Info.ID)).Set(float64(samples))
 
 				decision.Allowed = false
 				decision.Reason = "samples_per_second_exceeded_recovery"
@@ -1765,8 +2053,8 @@ func (rls *RLS) checkLimits(tenant *TenantState, samples int64, bodyBytes int64,
 			// Normal rate limiting
 			if !tenant.SamplesBucket.Take(float64(samples)) {
 				// 🔧 NEW: Record limit violation metrics
-				rls.metrics.LimitViolationsTotal.WithLabelValues(tenant.Info.ID, "samples_per_second_exceeded").Inc()
-				rls.metrics.SamplesCountGauge.WithLabelValues(tenant.Info.ID).Set(float64(samples))
+				rls.metrics.LimitViolationsTotal.WithLabelValues(rls.labels.tenant(tenant.Info.ID), "samples_per_second_exceeded").Inc()
+				rls.metrics.SamplesCountGauge.WithLabelValues(rls.labels.tenant(tenant.Info.ID)).Set(float64(samples))
 
 				decision.Allowed = false
 				decision.Reason = "samples_per_second_exceeded"
@@ -1792,10 +2080,11 @@ func (rls *RLS) checkLimits(tenant *TenantState, samples int64, bodyBytes int64,
 		if len(recentDenials) > 10 {
 			// 🔧 NEW: Safety valve - allow 10% of traffic even when limits are exceeded
 			if rand.Float64() < 0.1 { // 10% chance to allow
+				originalReason := decision.Reason
 				rls.logger.Warn().
 					Str("tenant", tenant.Info.ID).
 					Int("recent_denials", len(recentDenials)).
-					Str("original_reason", decision.Reason).
+					Str("original_reason", originalReason).
 					Msg("RLS: Safety valve activated - allowing traffic despite limits")
 
 				decision.Allowed = true
@@ -1803,7 +2092,8 @@ func (rls *RLS) checkLimits(tenant *TenantState, samples int64, bodyBytes int64,
 				decision.Code = 200
 
 				// Record safety valve usage
-				rls.metrics.LimitViolationsTotal.WithLabelValues(tenant.Info.ID, "safety_valve_activated").Inc()
+				rls.metrics.LimitViolationsTotal.WithLabelValues(rls.labels.tenant(tenant.Info.ID), "safety_valve_activated").Inc()
+				rls.observeSafetyValve(tenant.Info.ID, originalReason, len(recentDenials))
 			}
 		}
 	}
@@ -1832,28 +2122,28 @@ func (rls *RLS) checkLimits(tenant *TenantState, samples int64, bodyBytes int64,
 		}()
 
 		// 🔧 NEW: Record current values for successful requests
-		rls.metrics.SeriesCountGauge.WithLabelValues(tenant.Info.ID).Set(float64(requestInfo.ObservedSeries))
-		rls.metrics.LabelsCountGauge.WithLabelValues(tenant.Info.ID).Set(float64(requestInfo.ObservedLabels))
-		rls.metrics.SamplesCountGauge.WithLabelValues(tenant.Info.ID).Set(float64(requestInfo.ObservedSamples))
-		rls.metrics.BodySizeGauge.WithLabelValues(tenant.Info.ID).Set(float64(bodyBytes))
+		rls.metrics.SeriesCountGauge.WithLabelValues(rls.labels.tenant(tenant.Info.ID)).Set(float64(requestInfo.ObservedSeries))
+		rls.metrics.LabelsCountGauge.WithLabelValues(rls.labels.tenant(tenant.Info.ID)).Set(float64(requestInfo.ObservedLabels))
+		rls.metrics.SamplesCountGauge.WithLabelValues(rls.labels.tenant(tenant.Info.ID)).Set(float64(requestInfo.ObservedSamples))
+		rls.metrics.BodySizeGauge.WithLabelValues(rls.labels.tenant(tenant.Info.ID)).Set(float64(bodyBytes))
 
 		// Record per-metric series counts
 		for metricName, seriesCount := range requestInfo.MetricSeriesCounts {
-			rls.metrics.MetricSeriesCountGauge.WithLabelValues(tenant.Info.ID, metricName).Set(float64(seriesCount))
+			rls.labels.setMetricSeries(rls.metrics.MetricSeriesCountGauge, tenant.Info.ID, metricName, float64(seriesCount))
 		}
 
 		// Record limit thresholds
 		if tenant.Info.Limits.MaxSeriesPerRequest > 0 {
-			rls.metrics.LimitThresholdGauge.WithLabelValues(tenant.Info.ID, "max_series_per_request").Set(float64(tenant.Info.Limits.MaxSeriesPerRequest))
+			rls.metrics.LimitThresholdGauge.WithLabelValues(rls.labels.tenant(tenant.Info.ID), "max_series_per_request").Set(float64(tenant.Info.Limits.MaxSeriesPerRequest))
 		}
 		if tenant.Info.Limits.MaxSeriesPerMetric > 0 {
-			rls.metrics.LimitThresholdGauge.WithLabelValues(tenant.Info.ID, "max_series_per_metric").Set(float64(tenant.Info.Limits.MaxSeriesPerMetric))
+			rls.metrics.LimitThresholdGauge.WithLabelValues(rls.labels.tenant(tenant.Info.ID), "max_series_per_metric").Set(float64(tenant.Info.Limits.MaxSeriesPerMetric))
 		}
 		if tenant.Info.Limits.MaxLabelsPerSeries > 0 {
-			rls.metrics.LimitThresholdGauge.WithLabelValues(tenant.Info.ID, "max_labels_per_series").Set(float64(tenant.Info.Limits.MaxLabelsPerSeries))
+			rls.metrics.LimitThresholdGauge.WithLabelValues(rls.labels.tenant(tenant.Info.ID), "max_labels_per_series").Set(float64(tenant.Info.Limits.MaxLabelsPerSeries))
 		}
 		if tenant.Info.Limits.MaxBodyBytes > 0 {
-			rls.metrics.LimitThresholdGauge.WithLabelValues(tenant.Info.ID, "max_body_bytes").Set(float64(tenant.Info.Limits.MaxBodyBytes))
+			rls.metrics.LimitThresholdGauge.WithLabelValues(rls.labels.tenant(tenant.Info.ID), "max_body_bytes").Set(float64(tenant.Info.Limits.MaxBodyBytes))
 		}
 	}
 
@@ -1880,10 +2170,10 @@ func (rls *RLS) getRecentDenials(tenantID string, window time.Duration) []limits
 
 // 🔧 NEW: CheckRemoteWriteLimitsWithFiltering checks limits and returns filtered body for selective filtering
 // This function supports both traditional deny/allow and selective filtering modes
-func (rls *RLS) CheckRemoteWriteLimitsWithFiltering(tenantID string, body []byte, contentEncoding string) (limits.Decision, []byte) {
+func (rls *RLS) CheckRemoteWriteLimitsWithFiltering(ctx context.Context, tenantID string, body []byte, contentEncoding string) (limits.Decision, []byte) {
 	start := time.Now()
 	defer func() {
-		rls.metrics.AuthzCheckDuration.WithLabelValues(tenantID).Observe(time.Since(start).Seconds())
+		rls.metrics.AuthzCheckDuration.WithLabelValues(rls.labels.tenant(tenantID)).Observe(time.Since(start).Seconds())
 	}()
 
 	// Get tenant state
@@ -1891,25 +2181,25 @@ func (rls *RLS) CheckRemoteWriteLimitsWithFiltering(tenantID string, body []byte
 
 	// Check if enforcement is enabled
 	if !tenant.Info.Enforcement.Enabled {
-		rls.metrics.DecisionsTotal.WithLabelValues("allow", tenantID, "enforcement_disabled").Inc()
+		rls.metrics.DecisionsTotal.WithLabelValues("allow", rls.labels.tenant(tenantID), rls.labels.reason("enforcement_disabled")).Inc()
 		return limits.Decision{Allowed: true, Reason: "enforcement_disabled", Code: 200}, body
 	}
 
 	// Quick body size check
 	bodyBytes := int64(len(body))
 	if bodyBytes < 100 {
-		rls.metrics.DecisionsTotal.WithLabelValues("allow", tenantID, "small_request").Inc()
+		rls.metrics.DecisionsTotal.WithLabelValues("allow", rls.labels.tenant(tenantID), rls.labels.reason("small_request")).Inc()
 		return limits.Decision{Allowed: true, Reason: "small_request", Code: 200}, body
 	}
 
 	// Skip parsing for very large requests
 	if bodyBytes > 10*1024*1024 { // 10MB limit
-		rls.metrics.DecisionsTotal.WithLabelValues("deny", tenantID, "request_too_large").Inc()
+		rls.metrics.DecisionsTotal.WithLabelValues("deny", rls.labels.tenant(tenantID), rls.labels.reason("request_too_large")).Inc()
 		return limits.Decision{Allowed: false, Reason: "request body too large", Code: 413}, body
 	}
 
 	// Parse request for limits checking
-	result, err := parser.ParseRemoteWriteRequest(body, contentEncoding)
+	result, err := parser.ParseRemoteWriteRequestContext(ctx, body, contentEncoding)
 	if err != nil {
 		rls.metrics.BodyParseErrors.Inc()
 
@@ -1922,13 +2212,13 @@ func (rls *RLS) CheckRemoteWriteLimitsWithFiltering(tenantID string, body []byte
 			MetricSeriesCounts: make(map[string]int64),
 		}
 
-		decision := rls.checkLimits(tenant, fallbackSamples, bodyBytes, fallbackRequestInfo)
+		decision := rls.checkLimits(ctx, tenant, fallbackSamples, bodyBytes, fallbackRequestInfo)
 		if !decision.Allowed {
-			rls.metrics.DecisionsTotal.WithLabelValues("deny", tenantID, "body_parse_failed_limit_exceeded").Inc()
+			rls.metrics.DecisionsTotal.WithLabelValues("deny", rls.labels.tenant(tenantID), rls.labels.reason("body_parse_failed_limit_exceeded")).Inc()
 			return decision, body
 		}
 
-		rls.metrics.DecisionsTotal.WithLabelValues("allow", tenantID, "body_parse_failed_allow").Inc()
+		rls.metrics.DecisionsTotal.WithLabelValues("allow", rls.labels.tenant(tenantID), rls.labels.reason("body_parse_failed_allow")).Inc()
 		return limits.Decision{Allowed: true, Reason: "body_parse_failed_allow", Code: 200}, body
 	}
 
@@ -1939,11 +2229,22 @@ func (rls *RLS) CheckRemoteWriteLimitsWithFiltering(tenantID string, body []byte
 		ObservedLabels:     result.LabelsCount,
 		MetricSeriesCounts: result.MetricSeriesCounts,
 	}
+	requestInfo.HACluster, requestInfo.HAReplica = haLabels(tenant, result.Series)
 
 	// Check if selective filtering is enabled
-	if rls.config.SelectiveFiltering.Enabled {
+	if rls.selectiveFilteringEnabled(tenant) {
+		// 🔧 NEW: Traffic of a non-elected HA replica is neither counted nor filtered
+		if decision, deduplicated := rls.checkHAReplica(ctx, tenant, requestInfo); deduplicated {
+			decisionType := "allow"
+			if !decision.Allowed {
+				decisionType = "deny"
+			}
+			rls.metrics.DecisionsTotal.WithLabelValues(decisionType, rls.labels.tenant(tenantID), rls.labels.reason(decision.Reason)).Inc()
+			return decision, body
+		}
+
 		// Use selective filtering instead of binary allow/deny
-		selectiveResult := rls.SelectiveFilterRequest(tenantID, body, contentEncoding)
+		selectiveResult := rls.SelectiveFilterRequest(ctx, tenantID, body, contentEncoding)
 
 		// Convert SelectiveFilterResult to Decision
 		decision := limits.Decision{
@@ -1954,23 +2255,26 @@ func (rls *RLS) CheckRemoteWriteLimitsWithFiltering(tenantID string, body []byte
 
 		// Record selective filtering metrics
 		if selectiveResult.DroppedSeries > 0 {
-			rls.metrics.DecisionsTotal.WithLabelValues("selective_filter", tenantID, "series_filtered").Inc()
-			rls.metrics.SamplesCountGauge.WithLabelValues(tenantID).Set(float64(selectiveResult.FilteredSamples))
-			rls.metrics.SeriesCountGauge.WithLabelValues(tenantID).Set(float64(selectiveResult.FilteredSeries))
+			rls.metrics.DecisionsTotal.WithLabelValues("selective_filter", rls.labels.tenant(tenantID), rls.labels.reason("series_filtered")).Inc()
+			rls.metrics.SamplesCountGauge.WithLabelValues(rls.labels.tenant(tenantID)).Set(float64(selectiveResult.FilteredSamples))
+			rls.metrics.SeriesCountGauge.WithLabelValues(rls.labels.tenant(tenantID)).Set(float64(selectiveResult.FilteredSeries))
 		} else {
-			rls.metrics.DecisionsTotal.WithLabelValues("allow", tenantID, "selective_filter_allowed").Inc()
+			rls.metrics.DecisionsTotal.WithLabelValues("allow", rls.labels.tenant(tenantID), rls.labels.reason("selective_filter_allowed")).Inc()
 		}
 
 		// Return the filtered body for selective filtering
 		return decision, selectiveResult.FilteredBody
 	} else {
 		// Use traditional binary allow/deny logic
-		decision := rls.checkLimits(tenant, result.SamplesCount, bodyBytes, requestInfo)
+		decision := rls.checkLimits(ctx, tenant, result.SamplesCount, bodyBytes, requestInfo)
 
 		if decision.Allowed {
-			rls.metrics.DecisionsTotal.WithLabelValues("allow", tenantID, "allowed").Inc()
+			rls.metrics.DecisionsTotal.WithLabelValues("allow", rls.labels.tenant(tenantID), rls.labels.reason("allowed")).Inc()
 		} else {
-			rls.metrics.DecisionsTotal.WithLabelValues("deny", tenantID, decision.Reason).Inc()
+			rls.metrics.DecisionsTotal.WithLabelValues("deny", rls.labels.tenant(tenantID), rls.labels.reason(decision.Reason)).Inc()
+			if decision.Reason != haNotElectedReason {
+				rls.forensics.Submit(tenantID, forensics.SourceCheck, decision.Reason, result.Series)
+			}
 		}
 
 		return decision, body
@@ -1985,7 +2289,7 @@ func (rls *RLS) GetMimirHost() string {
 // 🔧 NEW: CheckRemoteWriteLimits - backward compatibility function
 // This function maintains backward compatibility while the new function supports selective filtering
 func (rls *RLS) CheckRemoteWriteLimits(tenantID string, body []byte, contentEncoding string) limits.Decision {
-	decision, _ := rls.CheckRemoteWriteLimitsWithFiltering(tenantID, body, contentEncoding)
+	decision, _ := rls.CheckRemoteWriteLimitsWithFiltering(context.Background(), tenantID, body, contentEncoding)
 	return decision
 }
 
@@ -1995,7 +2299,7 @@ func (rls *RLS) GetMimirPort() string {
 }
 
 // getTenantGlobalSeriesCount returns the current global series count for a tenant
-func (rls *RLS) getTenantGlobalSeriesCount(tenantID string) int64 {
+func (rls *RLS) getTenantGlobalSeriesCount(ctx context.Context, tenantID string) int64 {
 	// 🔧 FIX: Use cache first to reduce Redis calls and improve performance
 	rls.seriesCacheMu.RLock()
 	if entry, exists := rls.seriesCache[tenantID]; exists && time.Since(entry.LastUpdated) < 30*time.Second {
@@ -2005,11 +2309,15 @@ func (rls *RLS) getTenantGlobalSeriesCount(tenantID string) int64 {
 	rls.seriesCacheMu.RUnlock()
 
 	// 🔧 FIX: Better Redis error handling with fallback logic
-	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	// The lookup keeps the caller's trace but not its cancellation
+	ctx, span := tracing.Start(ctx, "store.GetGlobalSeriesCount", trace.SpanKindClient, attribute.String("tenant.id", tenantID))
+	defer span.End()
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 500*time.Millisecond)
 	defer cancel()
 
 	count, err := rls.store.GetGlobalSeriesCount(ctx, tenantID)
 	if err != nil {
+		tracing.RecordError(span, err)
 		// 🔧 FIX: Handle Redis nil errors gracefully - this is expected for new tenants
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "nil") {
 			// This is normal for new tenants - no series count exists yet
@@ -2022,12 +2330,17 @@ func (rls *RLS) getTenantGlobalSeriesCount(tenantID string) int64 {
 		return 0
 	}
 
-	// 🔧 FIX: Update cache with successful result
+	// 🔧 FIX: Update cache with successful result, keeping the per-metric counts
 	rls.seriesCacheMu.Lock()
-	rls.seriesCache[tenantID] = &SeriesCacheEntry{
-		GlobalCount:  count,
-		MetricCounts: make(map[string]int64), // Will be updated by getTenantMetricSeriesCount
-		LastUpdated:  time.Now(),
+	if entry, exists := rls.seriesCache[tenantID]; exists {
+		entry.GlobalCount = count
+		entry.LastUpdated = time.Now()
+	} else {
+		rls.seriesCache[tenantID] = &SeriesCacheEntry{
+			GlobalCount:  count,
+			MetricCounts: make(map[string]int64), // Will be updated by getTenantMetricSeriesCount
+			LastUpdated:  time.Now(),
+		}
 	}
 	rls.seriesCacheMu.Unlock()
 
@@ -2035,21 +2348,24 @@ func (rls *RLS) getTenantGlobalSeriesCount(tenantID string) int64 {
 }
 
 // getTenantMetricSeriesCount returns the current series count per metric for a tenant
-func (rls *RLS) getTenantMetricSeriesCount(tenantID string, requestInfo *limits.RequestInfo) map[string]int64 {
+func (rls *RLS) getTenantMetricSeriesCount(ctx context.Context, tenantID string, requestInfo *limits.RequestInfo) map[string]int64 {
 	// 🔧 FIX: Use cache first to reduce Redis calls and improve performance
 	rls.seriesCacheMu.RLock()
-	if entry, exists := rls.seriesCache[tenantID]; exists && time.Since(entry.LastUpdated) < 30*time.Second {
+	if entry, exists := rls.seriesCache[tenantID]; exists && time.Since(entry.MetricCountsUpdated) < 30*time.Second {
 		rls.seriesCacheMu.RUnlock()
 		return entry.MetricCounts
 	}
 	rls.seriesCacheMu.RUnlock()
 
 	// 🔧 FIX: Better Redis error handling with fallback logic
-	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	ctx, span := tracing.Start(ctx, "store.GetAllMetricSeriesCounts", trace.SpanKindClient, attribute.String("tenant.id", tenantID))
+	defer span.End()
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 500*time.Millisecond)
 	defer cancel()
 
 	counts, err := rls.store.GetAllMetricSeriesCounts(ctx, tenantID)
 	if err != nil {
+		tracing.RecordError(span, err)
 		// 🔧 FIX: Handle Redis nil errors gracefully - this is expected for new tenants
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "nil") {
 			// This is normal for new tenants - no metric series counts exist yet
@@ -2066,12 +2382,12 @@ func (rls *RLS) getTenantMetricSeriesCount(tenantID string, requestInfo *limits.
 	rls.seriesCacheMu.Lock()
 	if entry, exists := rls.seriesCache[tenantID]; exists {
 		entry.MetricCounts = counts
-		entry.LastUpdated = time.Now()
+		entry.MetricCountsUpdated = time.Now()
 	} else {
 		rls.seriesCache[tenantID] = &SeriesCacheEntry{
-			GlobalCount:  0, // Will be updated by getTenantGlobalSeriesCount
-			MetricCounts: counts,
-			LastUpdated:  time.Now(),
+			GlobalCount:         0, // Will be updated by getTenantGlobalSeriesCount
+			MetricCounts:        counts,
+			MetricCountsUpdated: time.Now(),
 		}
 	}
 	rls.seriesCacheMu.Unlock()
@@ -2153,13 +2469,13 @@ func (rls *RLS) updateBucketMetrics(tenant *TenantState) {
 
 	// 🔧 FIX: Check for nil buckets before accessing them (prevents panic with zero limits)
 	if tenant.SamplesBucket != nil {
-		rls.metrics.TenantBuckets.WithLabelValues(tenant.Info.ID, "samples").Set(tenant.SamplesBucket.Available())
+		rls.metrics.TenantBuckets.WithLabelValues(rls.labels.tenant(tenant.Info.ID), "samples").Set(tenant.SamplesBucket.Available())
 	}
 	if tenant.BytesBucket != nil {
-		rls.metrics.TenantBuckets.WithLabelValues(tenant.Info.ID, "bytes").Set(tenant.BytesBucket.Available())
+		rls.metrics.TenantBuckets.WithLabelValues(rls.labels.tenant(tenant.Info.ID), "bytes").Set(tenant.BytesBucket.Available())
 	}
 	if tenant.RequestsBucket != nil {
-		rls.metrics.TenantBuckets.WithLabelValues(tenant.Info.ID, "requests").Set(tenant.RequestsBucket.Available())
+		rls.metrics.TenantBuckets.WithLabelValues(rls.labels.tenant(tenant.Info.ID), "requests").Set(tenant.RequestsBucket.Available())
 	}
 }
 
@@ -2272,19 +2588,40 @@ func (rls *RLS) calculateFallbackLabels(body []byte, contentEncoding string) int
 }
 
 // SetTenantLimits sets the limits for a tenant
-func (rls *RLS) SetTenantLimits(tenantID string, newLimits limits.TenantLimits) error {
+// Fields left unset in newLimits inherit from the tenant's profile and the global defaults.
+func (rls *RLS) SetTenantLimits(tenantID string, newLimits limits.LimitOverrides, change ChangeContext) error {
+	if err := newLimits.Validate(); err != nil {
+		return err
+	}
+
 	rls.tenantsMu.Lock()
-	defer rls.tenantsMu.Unlock()
+	write := rls.setTenantLimitsLocked(tenantID, newLimits, change)
+	// The tenant no longer matches the last bulk set, so the next one must be applied even if its version is the same
+	rls.limitsVersion = LimitsSetVersion{}
+	rls.tenantsMu.Unlock()
+
+	rls.flushTenantWrites("", []tenantWrite{write})
+	return nil
+}
 
+// setTenantLimitsLocked sets the tenant limits layer of a tenant in memory; the caller holds tenantsMu and
+// flushes the returned write once it has released it
+func (rls *RLS) setTenantLimitsLocked(tenantID string, newLimits limits.LimitOverrides, change ChangeContext) tenantWrite {
 	tenant, exists := rls.tenants[tenantID]
 	isNewTenant := !exists
 
+	var before *limits.TenantConfigSnapshot
+	if exists {
+		before = snapshotTenantConfig(tenant)
+	}
+
 	if !exists {
 		tenant = &TenantState{
 			Info: limits.TenantInfo{
 				ID:   tenantID,
 				Name: tenantID,
 			},
+			Enforcement: rls.config.DefaultEnforcement,
 		}
 		rls.tenants[tenantID] = tenant
 		rls.logger.Info().
@@ -2292,17 +2629,22 @@ func (rls *RLS) SetTenantLimits(tenantID string, newLimits limits.TenantLimits)
 			Msg("RLS: creating new tenant from overrides-sync")
 	}
 
+	// 🔧 NEW: Tenant limits are one layer; defaults, profile and any active temporary override are merged around them
+	tenant.Overrides = newLimits
+	rls.resolveTenantLimits(tenant)
+
 	// Log the limits being set
 	rls.logger.Info().
 		Str("tenant_id", tenantID).
 		Bool("is_new_tenant", isNewTenant).
-		Float64("samples_per_second", newLimits.SamplesPerSecond).
-		Float64("burst_percent", newLimits.BurstPercent).
-		Int64("max_body_bytes", newLimits.MaxBodyBytes).
-		Int32("max_labels_per_series", newLimits.MaxLabelsPerSeries).
-		Int32("max_label_value_length", newLimits.MaxLabelValueLength).
-		Int32("max_series_per_request", newLimits.MaxSeriesPerRequest).
-		Int32("max_series_per_metric", newLimits.MaxSeriesPerMetric).
+		Str("profile", tenant.Info.Profile).
+		Float64("samples_per_second", tenant.BaseLimits.SamplesPerSecond).
+		Float64("burst_percent", tenant.BaseLimits.BurstPercent).
+		Int64("max_body_bytes", tenant.BaseLimits.MaxBodyBytes).
+		Int32("max_labels_per_series", tenant.BaseLimits.MaxLabelsPerSeries).
+		Int32("max_label_value_length", tenant.BaseLimits.MaxLabelValueLength).
+		Int32("max_series_per_request", tenant.BaseLimits.MaxSeriesPerRequest).
+		Int32("max_series_per_metric", tenant.BaseLimits.MaxSeriesPerMetric).
 		Int("total_tenants_after", len(rls.tenants)).
 		Msg("RLS: received tenant limits from overrides-sync")
 
@@ -2315,10 +2657,9 @@ func (rls *RLS) SetTenantLimits(tenantID string, newLimits limits.TenantLimits)
 			Msg("RLS: DEBUG - New tenant created successfully")
 	}
 
-	tenant.Info.Limits = newLimits
-
-	// 🔧 FIX: Always apply default enforcement configuration (for both new and existing tenants)
-	tenant.Info.Enforcement = rls.config.DefaultEnforcement
+	// New tenants start with the default enforcement configuration; existing ones keep their own, and a policy's
+	// takes precedence over either
+	rls.applyEnforcement(tenant)
 	rls.logger.Info().
 		Str("tenant_id", tenantID).
 		Bool("is_new_tenant", isNewTenant).
@@ -2328,22 +2669,39 @@ func (rls *RLS) SetTenantLimits(tenantID string, newLimits limits.TenantLimits)
 		Bool("enforce_max_series_per_request", tenant.Info.Enforcement.EnforceMaxSeriesPerRequest).
 		Bool("enforce_max_series_per_metric", tenant.Info.Enforcement.EnforceMaxSeriesPerMetric).
 		Bool("enforce_bytes_per_second", tenant.Info.Enforcement.EnforceBytesPerSecond).
-		Msg("RLS: applied default enforcement configuration")
+		Msg("RLS: applied enforcement configuration")
 
+	rls.updateTenantBuckets(tenantID, tenant, tenant.Info.Limits)
+	return newTenantWrite(tenantID, AuditActionSetLimits, change, before, tenant)
+}
+
+// samplesBucketCapacity is the size of a tenant's samples bucket: its ingestion_burst_size like in Mimir, or one
+// second of its rate when the overrides do not set one
+func samplesBucketCapacity(tenantLimits limits.TenantLimits) float64 {
+	if tenantLimits.IngestionBurstSize > 0 {
+		return float64(tenantLimits.IngestionBurstSize)
+	}
+	return tenantLimits.SamplesPerSecond
+}
+
+// updateTenantBuckets resizes the token buckets of a tenant to match its limits
+func (rls *RLS) updateTenantBuckets(tenantID string, tenant *TenantState, newLimits limits.TenantLimits) {
 	// Update buckets only for non-zero limits; nil buckets mean no enforcement for that dimension
 	if newLimits.SamplesPerSecond > 0 {
 		if tenant.SamplesBucket == nil {
-			tenant.SamplesBucket = buckets.NewTokenBucket(newLimits.SamplesPerSecond, newLimits.SamplesPerSecond)
+			tenant.SamplesBucket = buckets.NewTokenBucket(newLimits.SamplesPerSecond, samplesBucketCapacity(newLimits))
 			rls.logger.Debug().
 				Str("tenant_id", tenantID).
 				Float64("rate", newLimits.SamplesPerSecond).
+				Float64("capacity", samplesBucketCapacity(newLimits)).
 				Msg("RLS: created samples bucket")
 		} else {
 			tenant.SamplesBucket.SetRate(newLimits.SamplesPerSecond)
-			tenant.SamplesBucket.SetCapacity(newLimits.SamplesPerSecond)
+			tenant.SamplesBucket.SetCapacity(samplesBucketCapacity(newLimits))
 			rls.logger.Debug().
 				Str("tenant_id", tenantID).
 				Float64("rate", newLimits.SamplesPerSecond).
+				Float64("capacity", samplesBucketCapacity(newLimits)).
 				Msg("RLS: updated samples bucket")
 		}
 	} else {
@@ -2378,39 +2736,37 @@ func (rls *RLS) SetTenantLimits(tenantID string, newLimits limits.TenantLimits)
 		}
 		tenant.BytesBucket = nil
 	}
+}
 
-	// 🔧 STORE: Persist tenant data to store (Redis/Memory)
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	storeData := &store.TenantData{
+// tenantData is the stored form of a tenant
+func tenantData(tenant *TenantState) *store.TenantData {
+	overrides, tenantEnforcement := tenant.Overrides, tenant.Enforcement
+	return &store.TenantData{
 		ID:          tenant.Info.ID,
 		Name:        tenant.Info.Name,
-		Limits:      tenant.Info.Limits,
+		Limits:      tenant.BaseLimits,
 		Enforcement: tenant.Info.Enforcement,
+		// 🔧 NEW: Persist temporary overrides so they survive restarts
+		TemporaryOverride: tenant.Info.TemporaryOverride,
+		Profile:           tenant.Info.Profile,
+		Overrides:         &overrides,
+		Policy:            tenant.Info.Policy,
+		TenantEnforcement: &tenantEnforcement,
 	}
-
-	if err := rls.store.SetTenant(ctx, tenantID, storeData); err != nil {
-		rls.logger.Error().Err(err).Str("tenant_id", tenantID).Msg("RLS: failed to persist tenant to store")
-		// Don't return error - continue with in-memory state
-	} else {
-		rls.logger.Info().Str("tenant_id", tenantID).Msg("RLS: persisted tenant to store")
-	}
-
-	return nil
 }
 
 // SetTenantEnforcement sets the enforcement configuration for a tenant
-func (rls *RLS) SetTenantEnforcement(tenantID string, enforcement limits.EnforcementConfig) error {
+func (rls *RLS) SetTenantEnforcement(tenantID string, enforcement limits.EnforcementConfig, change ChangeContext) error {
 	rls.tenantsMu.Lock()
-	defer rls.tenantsMu.Unlock()
-
 	tenant, exists := rls.tenants[tenantID]
 	if !exists {
+		rls.tenantsMu.Unlock()
 		return fmt.Errorf("tenant %s not found", tenantID)
 	}
 
-	tenant.Info.Enforcement = enforcement
+	before := snapshotTenantConfig(tenant)
+	tenant.Enforcement = enforcement
+	rls.applyEnforcement(tenant)
 	rls.logger.Info().
 		Str("tenant_id", tenantID).
 		Bool("enabled", enforcement.Enabled).
@@ -2421,6 +2777,11 @@ func (rls *RLS) SetTenantEnforcement(tenantID string, enforcement limits.Enforce
 		Bool("enforce_bytes_per_second", enforcement.EnforceBytesPerSecond).
 		Msg("RLS: updated tenant enforcement configuration")
 
+	// 🔧 NEW: Persist enforcement changes so they survive restarts and can be rolled back, once the lock is released
+	write := newTenantWrite(tenantID, AuditActionSetEnforcement, change, before, tenant)
+	rls.tenantsMu.Unlock()
+
+	rls.flushTenantWrites("", []tenantWrite{write})
 	return nil
 }
 
@@ -2460,7 +2821,8 @@ func (rls *RLS) SetEnforcement(tenantID string, enforcement limits.EnforcementCo
 		return fmt.Errorf("tenant not found: %s", tenantID)
 	}
 
-	tenant.Info.Enforcement = enforcement
+	tenant.Enforcement = enforcement
+	rls.applyEnforcement(tenant)
 	return nil
 }
 
@@ -3413,27 +3775,25 @@ func (rls *RLS) getEnvoyResponseTimes() map[string]any {
 	return defaultTimes
 }
 
-// updateTrafficFlowState updates real-time traffic flow metrics
-func (rls *RLS) updateTrafficFlowState(responseTime float64, allowed bool) {
+// updateTrafficFlowState updates real-time traffic flow metrics with weight decisions made at the given time
+func (rls *RLS) updateTrafficFlowState(at time.Time, responseTime float64, allowed bool, weight int64) {
 	rls.trafficFlowMu.Lock()
 	defer rls.trafficFlowMu.Unlock()
 
-	now := time.Now()
-
 	// Update total requests
-	rls.trafficFlow.TotalRequests++
+	rls.trafficFlow.TotalRequests += weight
 
 	// Track Envoy → RLS flow (every request from Envoy comes to RLS)
-	rls.trafficFlow.EnvoyToRLSRequests++
+	rls.trafficFlow.EnvoyToRLSRequests += weight
 
 	// Track RLS decisions
-	rls.trafficFlow.RLSDecisions++
+	rls.trafficFlow.RLSDecisions += weight
 	if allowed {
-		rls.trafficFlow.RLSAllowed++
+		rls.trafficFlow.RLSAllowed += weight
 		// Track RLS → Mimir flow (only allowed requests go to Mimir)
-		rls.trafficFlow.RLSToMimirRequests++
+		rls.trafficFlow.RLSToMimirRequests += weight
 	} else {
-		rls.trafficFlow.RLSDenied++
+		rls.trafficFlow.RLSDenied += weight
 	}
 
 	// 🔧 FIX: Reduced logging to prevent performance issues
@@ -3443,17 +3803,20 @@ func (rls *RLS) updateTrafficFlowState(responseTime float64, allowed bool) {
 		Msg("RLS: DEBUG - Updated traffic flow state")
 
 	// Calculate requests per second (rolling average over last 60 seconds)
+	// Decisions arrive in batches, so the rate is derived from when they were made rather than processed
 	if !rls.trafficFlow.LastRequestTime.IsZero() {
-		timeDiff := now.Sub(rls.trafficFlow.LastRequestTime).Seconds()
+		timeDiff := at.Sub(rls.trafficFlow.LastRequestTime).Seconds()
 		if timeDiff > 0 {
 			// Simple exponential moving average
 			alpha := 0.1 // Smoothing factor
-			rls.trafficFlow.RequestsPerSecond = alpha*(1/timeDiff) + (1-alpha)*rls.trafficFlow.RequestsPerSecond
+			rls.trafficFlow.RequestsPerSecond = alpha*(float64(weight)/timeDiff) + (1-alpha)*rls.trafficFlow.RequestsPerSecond
 		}
 	}
 
-	rls.trafficFlow.LastRequestTime = now
-	rls.trafficFlow.LastUpdate = now
+	if at.After(rls.trafficFlow.LastRequestTime) {
+		rls.trafficFlow.LastRequestTime = at
+	}
+	rls.trafficFlow.LastUpdate = time.Now()
 
 	// Update response times (rolling average)
 	if rls.trafficFlow.ResponseTimes == nil {
@@ -3586,6 +3949,8 @@ func (rls *RLS) GetCardinalityData(timeRange, tenant string) limits.CardinalityD
 			MaxLabelsInSeries:     maxLabelsInSeries,
 			CardinalityViolations: cardinalityViolations,
 			ViolationRate:         violationRate,
+			SampledRequests:       requestCount,
+			TotalRequests:         totalRequests,
 		},
 		Violations: rls.getCardinalityViolations(timeRange),
 		Trends:     rls.getCardinalityTrends(timeRange),
@@ -3794,39 +4159,9 @@ func (rls *RLS) getTenantCardinality() []limits.TenantCardinality {
 	return tenantCardinality
 }
 
+// getCardinalityAlerts returns the alerts maintained by the alert rule engine
 func (rls *RLS) getCardinalityAlerts() []limits.CardinalityAlert {
-	// For now, return mock alerts - this would be replaced with real alerting logic
-	var alerts []limits.CardinalityAlert
-
-	// Check for high violation rates
-	if len(rls.recentDenials) > 0 {
-		cardinalityViolations := 0
-		for _, denial := range rls.recentDenials {
-			if denial.Reason == "max_series_per_request_exceeded" || denial.Reason == "max_labels_per_series_exceeded" {
-				cardinalityViolations++
-			}
-		}
-
-		if cardinalityViolations > 5 {
-			alerts = append(alerts, limits.CardinalityAlert{
-				ID:        "1",
-				Severity:  "warning",
-				Message:   "High cardinality violation rate detected",
-				Timestamp: time.Now(),
-				Metric:    "violation_rate",
-				Value:     int64(cardinalityViolations),
-				Threshold: 5,
-				Resolved:  false,
-			})
-		}
-	}
-
-	// Ensure we always return a slice, not nil
-	if alerts == nil {
-		alerts = []limits.CardinalityAlert{}
-	}
-
-	return alerts
+	return rls.alerts.Alerts()
 }
 
 // TimeAggregator methods
@@ -3893,24 +4228,24 @@ func (ta *TimeAggregator) roundToBucket(t time.Time, duration time.Duration) tim
 }
 
 // updateBucket updates a bucket with new data
-func (ta *TimeAggregator) updateBucket(bucket *TimeBucket, allowed bool, series, labels int64, responseTime float64, violation bool) {
-	bucket.TotalRequests++
+func (ta *TimeAggregator) updateBucket(bucket *TimeBucket, allowed bool, count, series, labels int64, responseTime float64, violation bool) {
+	bucket.TotalRequests += count
 	if allowed {
-		bucket.AllowedRequests++
+		bucket.AllowedRequests += count
 	} else {
-		bucket.DeniedRequests++
+		bucket.DeniedRequests += count
 	}
 
-	bucket.TotalSeries += series
-	bucket.TotalLabels += labels
+	bucket.TotalSeries += series * count
+	bucket.TotalLabels += labels * count
 
 	if violation {
-		bucket.Violations++
+		bucket.Violations += count
 	}
 
 	// Update response time statistics
-	bucket.ResponseTimeCount++
-	if bucket.ResponseTimeCount == 1 {
+	bucket.ResponseTimeCount += count
+	if bucket.ResponseTimeCount == count {
 		bucket.MinResponseTime = responseTime
 		bucket.MaxResponseTime = responseTime
 		bucket.AvgResponseTime = responseTime
@@ -3922,7 +4257,7 @@ func (ta *TimeAggregator) updateBucket(bucket *TimeBucket, allowed bool, series,
 			bucket.MaxResponseTime = responseTime
 		}
 		// Update running average
-		bucket.AvgResponseTime = (bucket.AvgResponseTime*float64(bucket.ResponseTimeCount-1) + responseTime) / float64(bucket.ResponseTimeCount)
+		bucket.AvgResponseTime = (bucket.AvgResponseTime*float64(bucket.ResponseTimeCount-count) + responseTime*float64(count)) / float64(bucket.ResponseTimeCount)
 	}
 }
 
@@ -3962,31 +4297,36 @@ func (ta *TimeAggregator) cleanupBucketMap(buckets map[string]*TimeBucket, cutof
 
 // RecordDecision records a decision in all time buckets for a specific tenant
 func (ta *TimeAggregator) RecordDecision(tenantID string, timestamp time.Time, allowed bool, series, labels int64, responseTime float64, violation bool) {
+	ta.RecordDecisions(tenantID, timestamp, allowed, 1, series, labels, responseTime, violation)
+}
+
+// RecordDecisions records count identical decisions, e.g. one sampled event standing in for several
+func (ta *TimeAggregator) RecordDecisions(tenantID string, timestamp time.Time, allowed bool, count, series, labels int64, responseTime float64, violation bool) {
 	ta.mu.Lock()
 	defer ta.mu.Unlock()
 
 	// Record in 15-minute bucket
 	key15min := ta.getBucketKey(timestamp, 15*time.Minute)
 	bucket15min := ta.getOrCreateBucket(ta.buckets15min, key15min, timestamp, 15*time.Minute)
-	ta.updateBucket(bucket15min, allowed, series, labels, responseTime, violation)
+	ta.updateBucket(bucket15min, allowed, count, series, labels, responseTime, violation)
 
 	// Record in 1-hour bucket
 	key1h := ta.getBucketKey(timestamp, time.Hour)
 	bucket1h := ta.getOrCreateBucket(ta.buckets1h, key1h, timestamp, time.Hour)
-	ta.updateBucket(bucket1h, allowed, series, labels, responseTime, violation)
+	ta.updateBucket(bucket1h, allowed, count, series, labels, responseTime, violation)
 
 	// Record in 24-hour bucket
 	key24h := ta.getBucketKey(timestamp, 24*time.Hour)
 	bucket24h := ta.getOrCreateBucket(ta.buckets24h, key24h, timestamp, 24*time.Hour)
-	ta.updateBucket(bucket24h, allowed, series, labels, responseTime, violation)
+	ta.updateBucket(bucket24h, allowed, count, series, labels, responseTime, violation)
 
 	// Record in 1-week bucket
 	key1w := ta.getBucketKey(timestamp, 7*24*time.Hour)
 	bucket1w := ta.getOrCreateBucket(ta.buckets1w, key1w, timestamp, 7*24*time.Hour)
-	ta.updateBucket(bucket1w, allowed, series, labels, responseTime, violation)
+	ta.updateBucket(bucket1w, allowed, count, series, labels, responseTime, violation)
 
 	// Record in per-tenant buckets
-	ta.recordPerTenantDecision(tenantID, timestamp, allowed, series, labels, responseTime, violation)
+	ta.recordPerTenantDecision(tenantID, timestamp, allowed, count, series, labels, responseTime, violation)
 
 	// Cleanup old buckets
 	ta.cleanupOldBuckets()
@@ -4002,19 +4342,19 @@ func (ta *TimeAggregator) GetAggregatedData(timeRange string) map[string]interfa
 
 	switch timeRange {
 	case "15m":
-		buckets = ta.buckets15min
+		buckets = ta.globalView(granularity15m)
 		duration = 15 * time.Minute
 	case "1h":
-		buckets = ta.buckets1h
+		buckets = ta.globalView(granularity1h)
 		duration = time.Hour
 	case "24h":
-		buckets = ta.buckets24h
+		buckets = ta.globalView(granularity24h)
 		duration = 24 * time.Hour
 	case "1w":
-		buckets = ta.buckets1w
+		buckets = ta.globalView(granularity1w)
 		duration = 7 * 24 * time.Hour
 	default:
-		buckets = ta.buckets1h // Default to 1-hour buckets
+		buckets = ta.globalView(granularity1h) // Default to 1-hour buckets
 		duration = time.Hour
 	}
 
@@ -4093,19 +4433,19 @@ func (ta *TimeAggregator) GetTenantAggregatedData(tenantID string, timeRange str
 
 	switch timeRange {
 	case "15m":
-		tenantBuckets = ta.tenantBuckets15min[tenantID]
+		tenantBuckets = ta.tenantView(granularity15m, tenantID)
 		duration = 15 * time.Minute
 	case "1h":
-		tenantBuckets = ta.tenantBuckets1h[tenantID]
+		tenantBuckets = ta.tenantView(granularity1h, tenantID)
 		duration = time.Hour
 	case "24h":
-		tenantBuckets = ta.tenantBuckets24h[tenantID]
+		tenantBuckets = ta.tenantView(granularity24h, tenantID)
 		duration = 24 * time.Hour
 	case "1w":
-		tenantBuckets = ta.tenantBuckets1w[tenantID]
+		tenantBuckets = ta.tenantView(granularity1w, tenantID)
 		duration = 7 * 24 * time.Hour
 	default:
-		tenantBuckets = ta.tenantBuckets1h[tenantID]
+		tenantBuckets = ta.tenantView(granularity1h, tenantID)
 		duration = time.Hour
 	}
 
@@ -4223,19 +4563,19 @@ func (ta *TimeAggregator) GetTimeSeriesData(timeRange string, metric string) []m
 
 	switch timeRange {
 	case "15m":
-		buckets = ta.buckets15min
+		buckets = ta.globalView(granularity15m)
 		duration = 15 * time.Minute
 	case "1h":
-		buckets = ta.buckets1h
+		buckets = ta.globalView(granularity1h)
 		duration = time.Hour
 	case "24h":
-		buckets = ta.buckets24h
+		buckets = ta.globalView(granularity24h)
 		duration = 24 * time.Hour
 	case "1w":
-		buckets = ta.buckets1w
+		buckets = ta.globalView(granularity1w)
 		duration = 7 * 24 * time.Hour
 	default:
-		buckets = ta.buckets1h
+		buckets = ta.globalView(granularity1h)
 		duration = time.Hour
 	}
 
@@ -4476,7 +4816,7 @@ func (rls *RLS) GetFlowTimelineData(timeRange string) []map[string]interface{} {
 }
 
 // recordPerTenantDecision records a decision in per-tenant time buckets
-func (ta *TimeAggregator) recordPerTenantDecision(tenantID string, timestamp time.Time, allowed bool, series, labels int64, responseTime float64, violation bool) {
+func (ta *TimeAggregator) recordPerTenantDecision(tenantID string, timestamp time.Time, allowed bool, count, series, labels int64, responseTime float64, violation bool) {
 	// Record in per-tenant 15-minute bucket
 	key15min := ta.getBucketKey(timestamp, 15*time.Minute)
 	tenantKey15min := tenantID + ":" + key15min
@@ -4484,7 +4824,7 @@ func (ta *TimeAggregator) recordPerTenantDecision(tenantID string, timestamp tim
 		ta.tenantBuckets15min[tenantID] = make(map[string]*TimeBucket)
 	}
 	bucket15min := ta.getOrCreateBucket(ta.tenantBuckets15min[tenantID], tenantKey15min, timestamp, 15*time.Minute)
-	ta.updateBucket(bucket15min, allowed, series, labels, responseTime, violation)
+	ta.updateBucket(bucket15min, allowed, count, series, labels, responseTime, violation)
 
 	// Record in per-tenant 1-hour bucket
 	key1h := ta.getBucketKey(timestamp, time.Hour)
@@ -4493,7 +4833,7 @@ func (ta *TimeAggregator) recordPerTenantDecision(tenantID string, timestamp tim
 		ta.tenantBuckets1h[tenantID] = make(map[string]*TimeBucket)
 	}
 	bucket1h := ta.getOrCreateBucket(ta.tenantBuckets1h[tenantID], tenantKey1h, timestamp, time.Hour)
-	ta.updateBucket(bucket1h, allowed, series, labels, responseTime, violation)
+	ta.updateBucket(bucket1h, allowed, count, series, labels, responseTime, violation)
 
 	// Record in per-tenant 24-hour bucket
 	key24h := ta.getBucketKey(timestamp, 24*time.Hour)
@@ -4502,7 +4842,7 @@ func (ta *TimeAggregator) recordPerTenantDecision(tenantID string, timestamp tim
 		ta.tenantBuckets24h[tenantID] = make(map[string]*TimeBucket)
 	}
 	bucket24h := ta.getOrCreateBucket(ta.tenantBuckets24h[tenantID], tenantKey24h, timestamp, 24*time.Hour)
-	ta.updateBucket(bucket24h, allowed, series, labels, responseTime, violation)
+	ta.updateBucket(bucket24h, allowed, count, series, labels, responseTime, violation)
 
 	// Record in per-tenant 1-week bucket
 	key1w := ta.getBucketKey(timestamp, 7*24*time.Hour)
@@ -4511,7 +4851,7 @@ func (ta *TimeAggregator) recordPerTenantDecision(tenantID string, timestamp tim
 		ta.tenantBuckets1w[tenantID] = make(map[string]*TimeBucket)
 	}
 	bucket1w := ta.getOrCreateBucket(ta.tenantBuckets1w[tenantID], tenantKey1w, timestamp, 7*24*time.Hour)
-	ta.updateBucket(bucket1w, allowed, series, labels, responseTime, violation)
+	ta.updateBucket(bucket1w, allowed, count, series, labels, responseTime, violation)
 }
 
 // 🔧 NEW: SelectiveFilterResult represents the result of selective filtering
@@ -4534,10 +4874,12 @@ type SelectiveFilterResult struct {
 
 // 🔧 NEW: SelectiveFilterRequest selectively filters metrics/series that exceed limits
 // This is the main function for selective filtering - it filters out only the problematic series
-func (rls *RLS) SelectiveFilterRequest(tenantID string, body []byte, contentEncoding string) *SelectiveFilterResult {
+func (rls *RLS) SelectiveFilterRequest(ctx context.Context, tenantID string, body []byte, contentEncoding string) *SelectiveFilterResult {
+	ctx, span := tracing.Start(ctx, "rls.selective_filter", trace.SpanKindInternal, attribute.String("tenant.id", tenantID))
+	defer span.End()
 	start := time.Now()
 	defer func() {
-		rls.metrics.AuthzCheckDuration.WithLabelValues(tenantID).Observe(time.Since(start).Seconds())
+		rls.metrics.AuthzCheckDuration.WithLabelValues(rls.labels.tenant(tenantID)).Observe(time.Since(start).Seconds())
 	}()
 
 	result := &SelectiveFilterResult{
@@ -4554,20 +4896,20 @@ func (rls *RLS) SelectiveFilterRequest(tenantID string, body []byte, contentEnco
 
 	// Check if enforcement is enabled
 	if !tenant.Info.Enforcement.Enabled {
-		rls.metrics.DecisionsTotal.WithLabelValues("allow", tenantID, "enforcement_disabled").Inc()
+		rls.metrics.DecisionsTotal.WithLabelValues("allow", rls.labels.tenant(tenantID), rls.labels.reason("enforcement_disabled")).Inc()
 		return result
 	}
 
 	// Quick body size check for very small requests
 	bodyBytes := int64(len(body))
 	if bodyBytes < 100 {
-		rls.metrics.DecisionsTotal.WithLabelValues("allow", tenantID, "small_request").Inc()
+		rls.metrics.DecisionsTotal.WithLabelValues("allow", rls.labels.tenant(tenantID), rls.labels.reason("small_request")).Inc()
 		return result
 	}
 
 	// For very large requests, still deny completely (safety measure)
 	if bodyBytes > 10*1024*1024 { // 10MB limit
-		rls.metrics.DecisionsTotal.WithLabelValues("deny", tenantID, "request_too_large").Inc()
+		rls.metrics.DecisionsTotal.WithLabelValues("deny", rls.labels.tenant(tenantID), rls.labels.reason("request_too_large")).Inc()
 		result.Allowed = false
 		result.Reason = "request body too large"
 		result.Code = 413
@@ -4575,11 +4917,11 @@ func (rls *RLS) SelectiveFilterRequest(tenantID string, body []byte, contentEnco
 	}
 
 	// Parse request to understand what's being sent
-	parseResult, err := parser.ParseRemoteWriteRequest(body, contentEncoding)
+	parseResult, err := parser.ParseRemoteWriteRequestContext(ctx, body, contentEncoding)
 	if err != nil {
 		rls.metrics.BodyParseErrors.Inc()
 		// If we can't parse, fall back to original logic
-		decision, _ := rls.CheckRemoteWriteLimitsWithFiltering(tenantID, body, contentEncoding)
+		decision, _ := rls.CheckRemoteWriteLimitsWithFiltering(ctx, tenantID, body, contentEncoding)
 		result.Allowed = decision.Allowed
 		result.Reason = decision.Reason
 		result.Code = decision.Code
@@ -4592,19 +4934,30 @@ func (rls *RLS) SelectiveFilterRequest(tenantID string, body []byte, contentEnco
 	result.FilteredSamples = parseResult.SamplesCount
 	result.FilteredSeries = parseResult.SeriesCount
 
+	// The tenant's policy may override the strategy and how many series may be dropped
+	filtering := rls.selectiveFilteringConfig(tenant)
+	budget := seriesDropBudget(filtering, parseResult.SeriesCount)
+
 	// 🔧 SELECTIVE FILTERING: Check each limit and filter accordingly
 
 	// 1. Check per-user series limit (total series across all metrics)
 	if tenant.Info.Enforcement.EnforceMaxSeriesPerRequest && tenant.Info.Limits.MaxSeriesPerRequest > 0 {
-		currentTenantSeries := rls.getTenantGlobalSeriesCount(tenantID)
+		currentTenantSeries := rls.getTenantGlobalSeriesCount(ctx, tenantID)
 		projectedTotal := currentTenantSeries + parseResult.SeriesCount
 
 		if projectedTotal > int64(tenant.Info.Limits.MaxSeriesPerRequest) {
 			// Calculate how many series we need to drop
 			excessSeries := projectedTotal - int64(tenant.Info.Limits.MaxSeriesPerRequest)
+			toDrop := excessSeries
+			if excessSeries > budget {
+				if rls.exceedsFilteringBudget(result, tenantID, filtering, excessSeries, budget) {
+					return result
+				}
+				toDrop = budget
+			}
 
-			// Apply selective filtering - drop excess series proportionally across metrics
-			filteredBody, droppedSeries := rls.filterExcessSeries(body, contentEncoding, excessSeries, parseResult)
+			// Apply selective filtering - drop excess series across metrics, in the order of the strategy
+			filteredBody, droppedSeries := rls.filterExcessSeries(body, contentEncoding, toDrop, parseResult, filtering)
 
 			result.FilteredBody = filteredBody
 			result.DroppedSeries = droppedSeries
@@ -4624,7 +4977,7 @@ func (rls *RLS) SelectiveFilterRequest(tenantID string, body []byte, contentEnco
 
 	// 2. Check per-metric series limit
 	if tenant.Info.Enforcement.EnforceMaxSeriesPerMetric && tenant.Info.Limits.MaxSeriesPerMetric > 0 {
-		currentMetricSeries := rls.getTenantMetricSeriesCount(tenantID, &limits.RequestInfo{})
+		currentMetricSeries := rls.getTenantMetricSeriesCount(ctx, tenantID, &limits.RequestInfo{})
 
 		for metricName, seriesCount := range parseResult.MetricSeriesCounts {
 			currentMetricTotal := currentMetricSeries[metricName]
@@ -4633,9 +4986,16 @@ func (rls *RLS) SelectiveFilterRequest(tenantID string, body []byte, contentEnco
 			if projectedMetricTotal > int64(tenant.Info.Limits.MaxSeriesPerMetric) {
 				// Calculate excess series for this metric
 				excessSeries := projectedMetricTotal - int64(tenant.Info.Limits.MaxSeriesPerMetric)
+				toDrop := excessSeries
+				if remaining := budget - result.DroppedSeries; excessSeries > remaining {
+					if rls.exceedsFilteringBudget(result, tenantID, filtering, excessSeries, remaining) {
+						return result
+					}
+					toDrop = remaining
+				}
 
 				// Filter out excess series for this specific metric
-				filteredBody, droppedSeries := rls.filterMetricSeries(body, contentEncoding, metricName, excessSeries, parseResult)
+				filteredBody, droppedSeries := rls.filterMetricSeries(body, contentEncoding, metricName, toDrop, parseResult, filtering)
 
 				result.FilteredBody = filteredBody
 				result.DroppedSeries += droppedSeries
@@ -4699,18 +5059,22 @@ func (rls *RLS) SelectiveFilterRequest(tenantID string, body []byte, contentEnco
 	}
 
 	// Record metrics
+	span.SetAttributes(attribute.Int64("dropped_series", result.DroppedSeries))
 	if result.DroppedSeries > 0 {
-		rls.metrics.LimitViolationsTotal.WithLabelValues(tenantID, "selective_filtering").Inc()
-		rls.metrics.SamplesCountGauge.WithLabelValues(tenantID).Set(float64(result.FilteredSamples))
-		rls.metrics.SeriesCountGauge.WithLabelValues(tenantID).Set(float64(result.FilteredSeries))
+		// 🔧 NEW: Keep the dropped series of tenants in forensic mode
+		rls.captureDroppedSeries(tenantID, contentEncoding, parseResult, result.FilteredBody)
+		rls.metrics.LimitViolationsTotal.WithLabelValues(rls.labels.tenant(tenantID), "selective_filtering").Inc()
+		rls.metrics.SamplesCountGauge.WithLabelValues(rls.labels.tenant(tenantID)).Set(float64(result.FilteredSamples))
+		rls.metrics.SeriesCountGauge.WithLabelValues(rls.labels.tenant(tenantID)).Set(float64(result.FilteredSeries))
 	}
 
-	rls.metrics.DecisionsTotal.WithLabelValues("allow", tenantID, "selective_filter_applied").Inc()
+	rls.metrics.DecisionsTotal.WithLabelValues("allow", rls.labels.tenant(tenantID), rls.labels.reason("selective_filter_applied")).Inc()
 	return result
 }
 
-// 🔧 NEW: filterExcessSeries filters out excess series proportionally across all metrics
-func (rls *RLS) filterExcessSeries(body []byte, contentEncoding string, excessSeries int64, parseResult *parser.ParseResult) ([]byte, int64) {
+// 🔧 NEW: filterExcessSeries filters out excess series proportionally across all metrics, or from the lowest-priority
+// metrics first with the priority strategy
+func (rls *RLS) filterExcessSeries(body []byte, contentEncoding string, excessSeries int64, parseResult *parser.ParseResult, filtering SelectiveFilteringConfig) ([]byte, int64) {
 	// Decompress the body first
 	decompressed, err := decompressBody(body, contentEncoding)
 	if err != nil {
@@ -4737,26 +5101,36 @@ func (rls *RLS) filterExcessSeries(body []byte, contentEncoding string, excessSe
 		metricName := extractMetricNameFromLabels(ts.Labels)
 		metricSeries[metricName] = append(metricSeries[metricName], i)
 	}
+	for _, seriesIndices := range metricSeries {
+		orderSeriesForDrop(writeRequest.Timeseries, seriesIndices, filtering.SeriesSelectionStrategy)
+	}
 
-	// Calculate series to drop proportionally
+	// Calculate series to drop proportionally, or by metric priority
 	seriesToDrop := make(map[string]int) // metric -> number of series to drop
 	totalDropped := int64(0)
 
-	for metricName, seriesIndices := range metricSeries {
-		metricSeriesCount := int64(len(seriesIndices))
-		proportionalDrop := int64(float64(excessSeries) * float64(metricSeriesCount) / float64(totalSeries))
-
-		// Ensure we don't drop more than available
-		if proportionalDrop > metricSeriesCount {
-			proportionalDrop = metricSeriesCount
+	if filtering.SeriesSelectionStrategy == "priority" {
+		seriesToDrop = dropByPriority(metricSeries, excessSeries, filtering.MetricPriority)
+		for _, dropCount := range seriesToDrop {
+			totalDropped += int64(dropCount)
 		}
+	} else {
+		for metricName, seriesIndices := range metricSeries {
+			metricSeriesCount := int64(len(seriesIndices))
+			proportionalDrop := int64(float64(excessSeries) * float64(metricSeriesCount) / float64(totalSeries))
 
-		seriesToDrop[metricName] = int(proportionalDrop)
-		totalDropped += proportionalDrop
+			// Ensure we don't drop more than available
+			if proportionalDrop > metricSeriesCount {
+				proportionalDrop = metricSeriesCount
+			}
 
-		// If we've dropped enough, stop
-		if totalDropped >= excessSeries {
-			break
+			seriesToDrop[metricName] = int(proportionalDrop)
+			totalDropped += proportionalDrop
+
+			// If we've dropped enough, stop
+			if totalDropped >= excessSeries {
+				break
+			}
 		}
 	}
 
@@ -4815,8 +5189,8 @@ func (rls *RLS) filterExcessSeries(body []byte, contentEncoding string, excessSe
 	return finalBody, totalDropped
 }
 
-// 🔧 NEW: filterMetricSeries filters out excess series for a specific metric
-func (rls *RLS) filterMetricSeries(body []byte, contentEncoding string, metricName string, excessSeries int64, parseResult *parser.ParseResult) ([]byte, int64) {
+// 🔧 NEW: filterMetricSeries filters out excess series for a specific metric, in the order of the strategy
+func (rls *RLS) filterMetricSeries(body []byte, contentEncoding string, metricName string, excessSeries int64, parseResult *parser.ParseResult, filtering SelectiveFilteringConfig) ([]byte, int64) {
 	// Decompress the body first
 	decompressed, err := decompressBody(body, contentEncoding)
 	if err != nil {
@@ -4838,6 +5212,7 @@ func (rls *RLS) filterMetricSeries(body []byte, contentEncoding string, metricNa
 			metricSeriesIndices = append(metricSeriesIndices, i)
 		}
 	}
+	orderSeriesForDrop(writeRequest.Timeseries, metricSeriesIndices, filtering.SeriesSelectionStrategy)
 
 	// Calculate how many series to drop
 	metricSeriesCount := int64(len(metricSeriesIndices))
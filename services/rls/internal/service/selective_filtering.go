@@ -0,0 +1,129 @@
+package service
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	prompb "github.com/AkshayDubey29/mimir-edge-enforcement/protos/prometheus"
+)
+
+// selectiveFilteringBudgetReason is the decision reason for a request that would need more series dropped than
+// max_filtering_percentage and min_series_to_keep allow
+const selectiveFilteringBudgetReason = "selective_filtering_budget_exceeded"
+
+// selectiveFilteringConfig returns a tenant's selective filtering settings: its policy's where it sets them, the
+// global flags otherwise
+func (rls *RLS) selectiveFilteringConfig(tenant *TenantState) SelectiveFilteringConfig {
+	config := rls.config.SelectiveFiltering
+	policy := tenant.Info.Policy
+	if policy == nil || policy.SelectiveFiltering == nil {
+		return config
+	}
+	sf := policy.SelectiveFiltering
+	if sf.Strategy != "" {
+		config.SeriesSelectionStrategy = sf.Strategy
+	}
+	if len(sf.MetricPriority) > 0 {
+		config.MetricPriority = sf.MetricPriority
+	}
+	if sf.MaxFilteringPercentage != nil {
+		config.MaxFilteringPercentage = *sf.MaxFilteringPercentage
+	}
+	if sf.MinSeriesToKeep != nil {
+		config.MinSeriesToKeep = *sf.MinSeriesToKeep
+	}
+	return config
+}
+
+// seriesDropBudget is how many of a request's series selective filtering may drop: at most MaxFilteringPercentage
+// of them, and never so many that fewer than MinSeriesToKeep are left
+func seriesDropBudget(config SelectiveFilteringConfig, seriesCount int64) int64 {
+	budget := int64(math.Floor(float64(seriesCount) * float64(config.MaxFilteringPercentage) / 100))
+	budget = min(budget, seriesCount-config.MinSeriesToKeep)
+	return max(budget, 0)
+}
+
+// exceedsFilteringBudget handles a request that needs more series dropped than its budget. With FallbackToDeny it
+// is denied and true is returned; otherwise the caller drops only as many as the budget allows.
+func (rls *RLS) exceedsFilteringBudget(result *SelectiveFilterResult, tenantID string, config SelectiveFilteringConfig, needed, budget int64) bool {
+	result.LimitViolations["filtering_budget"] += needed - budget
+	rls.logger.Info().
+		Str("tenant", tenantID).
+		Int64("needed", needed).
+		Int64("budget", budget).
+		Bool("deny", config.FallbackToDeny).
+		Msg("RLS: Selective filtering would drop more series than allowed")
+	if !config.FallbackToDeny {
+		return false
+	}
+	result.Allowed = false
+	result.Reason = selectiveFilteringBudgetReason
+	result.Code = 429
+	rls.metrics.DecisionsTotal.WithLabelValues("deny", rls.labels.tenant(tenantID), rls.labels.reason(selectiveFilteringBudgetReason)).Inc()
+	return true
+}
+
+// orderSeriesForDrop sorts the indices of one metric's series into the order the strategy drops them in. The
+// priority strategy orders metrics rather than series, so its series keep the request's order.
+func orderSeriesForDrop(series []*prompb.TimeSeries, indices []int, strategy string) {
+	switch strategy {
+	case "random":
+		rand.Shuffle(len(indices), func(i, j int) { indices[i], indices[j] = indices[j], indices[i] })
+	case "oldest", "newest":
+		sort.SliceStable(indices, func(i, j int) bool {
+			a, b := latestTimestamp(series[indices[i]]), latestTimestamp(series[indices[j]])
+			if strategy == "oldest" {
+				return a < b
+			}
+			return a > b
+		})
+	}
+}
+
+// latestTimestamp returns the timestamp of a series' newest sample or histogram
+func latestTimestamp(ts *prompb.TimeSeries) int64 {
+	latest := int64(math.MinInt64)
+	for _, sample := range ts.Samples {
+		latest = max(latest, sample.GetTimestamp())
+	}
+	for _, histogram := range ts.Histograms {
+		latest = max(latest, histogram.GetTimestamp())
+	}
+	return latest
+}
+
+// dropByPriority spreads excessSeries over the metrics, emptying the lowest-priority metric first: metrics missing
+// from priority, then the listed ones from last to first
+func dropByPriority(metricSeries map[string][]int, excessSeries int64, priority []string) map[string]int {
+	rank := make(map[string]int, len(priority))
+	for i, metricName := range priority {
+		rank[metricName] = i
+	}
+	metrics := make([]string, 0, len(metricSeries))
+	for metricName := range metricSeries {
+		metrics = append(metrics, metricName)
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		ri, iListed := rank[metrics[i]]
+		rj, jListed := rank[metrics[j]]
+		if iListed != jListed {
+			return !iListed
+		}
+		if ri != rj {
+			return ri > rj
+		}
+		return metrics[i] < metrics[j]
+	})
+
+	seriesToDrop := make(map[string]int, len(metrics))
+	for _, metricName := range metrics {
+		if excessSeries <= 0 {
+			break
+		}
+		drop := min(int64(len(metricSeries[metricName])), excessSeries)
+		seriesToDrop[metricName] = int(drop)
+		excessSeries -= drop
+	}
+	return seriesToDrop
+}
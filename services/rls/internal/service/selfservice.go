@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/cardinality"
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/limits"
+)
+
+// TenantUtilization summarises how close a tenant's traffic is to its effective limits
+type TenantUtilization struct {
+	TimeRange              string  `json:"time_range"`
+	TotalRequests          int64   `json:"total_requests"`
+	AllowedRequests        int64   `json:"allowed_requests"`
+	DeniedRequests         int64   `json:"denied_requests"`
+	DenyRate               float64 `json:"deny_rate"`
+	RPS                    float64 `json:"rps"`
+	SamplesPerSec          float64 `json:"samples_per_sec"`
+	SamplesPerSecondPct    float64 `json:"samples_per_second_pct"`
+	ActiveSeries           int64   `json:"active_series"`
+	MaxSeriesPerRequestPct float64 `json:"max_series_per_request_pct"`
+}
+
+// MetricSeries is the active series count of a single metric
+type MetricSeries struct {
+	Metric   string  `json:"metric"`
+	Series   int64   `json:"series"`
+	LimitPct float64 `json:"limit_pct,omitempty"` // share of MaxSeriesPerMetric, when set
+}
+
+// TenantSelfView is the read-only summary a tenant sees about itself
+type TenantSelfView struct {
+	TenantID        string            `json:"tenant_id"`
+	EffectiveLimits EffectiveLimits   `json:"effective_limits"`
+	Enforcement     bool              `json:"enforcement_enabled"`
+	Utilization     TenantUtilization `json:"utilization"`
+	RecentDenials   int               `json:"recent_denials"`
+	TopSeries       []MetricSeries    `json:"top_series"`
+}
+
+// GetTenantSelfView returns the self-service summary of a tenant
+func (rls *RLS) GetTenantSelfView(tenantID, timeRange string, topN int) (TenantSelfView, bool) {
+	// Look the tenant up once, so it cannot be deleted between reading its limits and its enforcement
+	rls.tenantsMu.RLock()
+	tenant, ok := rls.tenants[tenantID]
+	if !ok {
+		rls.tenantsMu.RUnlock()
+		return TenantSelfView{}, false
+	}
+	effective := effectiveLimits(tenantID, tenant)
+	enforcement := tenant.Info.Enforcement.Enabled
+	rls.tenantsMu.RUnlock()
+
+	return TenantSelfView{
+		TenantID:        tenantID,
+		EffectiveLimits: effective,
+		Enforcement:     enforcement,
+		Utilization:     rls.tenantUtilization(tenantID, timeRange, effective.Limits),
+		RecentDenials:   len(rls.RecentDenials(tenantID, time.Hour)),
+		TopSeries:       rls.GetTenantTopSeries(tenantID, topN),
+	}, true
+}
+
+// tenantUtilization compares a tenant's aggregated traffic with its limits
+func (rls *RLS) tenantUtilization(tenantID, timeRange string, tenantLimits limits.TenantLimits) TenantUtilization {
+	data := rls.timeAggregator.GetTenantAggregatedData(tenantID, timeRange)
+
+	utilization := TenantUtilization{TimeRange: timeRange}
+	utilization.TotalRequests, _ = data["total_requests"].(int64)
+	utilization.AllowedRequests, _ = data["allowed_requests"].(int64)
+	utilization.DeniedRequests, _ = data["denied_requests"].(int64)
+	utilization.DenyRate, _ = data["deny_rate"].(float64)
+	utilization.RPS, _ = data["rps"].(float64)
+	utilization.SamplesPerSec, _ = data["samples_per_sec"].(float64)
+	utilization.ActiveSeries = rls.getTenantGlobalSeriesCount(context.Background(), tenantID)
+
+	if tenantLimits.SamplesPerSecond > 0 {
+		utilization.SamplesPerSecondPct = utilization.SamplesPerSec / tenantLimits.SamplesPerSecond * 100.0
+	}
+	if tenantLimits.MaxSeriesPerRequest > 0 {
+		utilization.MaxSeriesPerRequestPct = float64(utilization.ActiveSeries) / float64(tenantLimits.MaxSeriesPerRequest) * 100.0
+	}
+	return utilization
+}
+
+// GetTenantTopSeries returns the tenant's metrics with the most active series, largest first
+func (rls *RLS) GetTenantTopSeries(tenantID string, topN int) []MetricSeries {
+	counts := rls.getTenantMetricSeriesCount(context.Background(), tenantID, nil)
+
+	var maxPerMetric int32
+	rls.tenantsMu.RLock()
+	if tenant, ok := rls.tenants[tenantID]; ok {
+		maxPerMetric = tenant.Info.Limits.MaxSeriesPerMetric
+	}
+	rls.tenantsMu.RUnlock()
+
+	out := make([]MetricSeries, 0, len(counts))
+	for metric, series := range counts {
+		entry := MetricSeries{Metric: metric, Series: series}
+		if maxPerMetric > 0 {
+			entry.LimitPct = float64(series) / float64(maxPerMetric) * 100.0
+		}
+		out = append(out, entry)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Series != out[j].Series {
+			return out[i].Series > out[j].Series
+		}
+		return out[i].Metric < out[j].Metric
+	})
+
+	if topN > 0 && len(out) > topN {
+		out = out[:topN]
+	}
+	return out
+}
+
+// GetTenantCardinalityTop returns the cardinality explorer report of a tenant.
+// It reports false when the explorer is disabled or has not seen the tenant yet.
+func (rls *RLS) GetTenantCardinalityTop(tenantID string, limit int, labelNames []string) (cardinality.Report, bool) {
+	return rls.explorer.Report(tenantID, limit, labelNames)
+}
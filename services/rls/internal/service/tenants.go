@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ErrTenantNotFound is returned when a mutation targets a tenant RLS does not know
+var ErrTenantNotFound = fmt.Errorf("tenant not found")
+
+// DeleteTenant forgets a tenant's limits, enforcement settings, profile and temporary override, in memory
+// and in the store. Usage counters are kept for the dashboards; further traffic from the tenant recreates
+// it with the default limits.
+func (rls *RLS) DeleteTenant(tenantID string, change ChangeContext) error {
+	rls.tenantsMu.Lock()
+	tenant, exists := rls.tenants[tenantID]
+	if !exists {
+		rls.tenantsMu.Unlock()
+		return fmt.Errorf("%w: %s", ErrTenantNotFound, tenantID)
+	}
+
+	before := snapshotTenantConfig(tenant)
+	delete(rls.tenants, tenantID)
+	rls.limitsVersion = LimitsSetVersion{}
+	remaining := len(rls.tenants)
+	rls.tenantsMu.Unlock()
+
+	// The store and the audit log are written once the lock is released, so Check is not held up by them
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := rls.store.DeleteTenant(ctx, tenantID); err != nil {
+		rls.logger.Error().Err(err).Str("tenant_id", tenantID).Msg("RLS: failed to delete tenant from store")
+	}
+
+	rls.recordAuditEvent(tenantID, AuditActionDeleteTenant, change, before, &TenantState{})
+
+	rls.logger.Info().
+		Str("tenant_id", tenantID).
+		Str("actor", change.Actor).
+		Str("source", change.Source).
+		Int("total_tenants_after", remaining).
+		Msg("RLS: deleted tenant")
+	return nil
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/tracing"
+)
+
+// startTracing installs the OpenTelemetry tracer provider exporting spans over OTLP, when an endpoint is configured
+func (rls *RLS) startTracing() {
+	shutdown, err := tracing.Setup(rls.config.Tracing, tracing.Metrics{Spans: rls.metrics.TracingSpans}, rls.logger)
+	if err != nil {
+		rls.logger.Fatal().Err(err).Msg("RLS: failed to set up tracing")
+	}
+	if shutdown == nil {
+		return
+	}
+	rls.stopTracing = shutdown
+
+	rls.logger.Info().
+		Str("endpoint", rls.config.Tracing.Endpoint).
+		Str("protocol", rls.config.Tracing.Protocol).
+		Float64("sample_ratio", rls.config.Tracing.SampleRatio).
+		Msg("RLS: exporting traces over OTLP")
+}
+
+// StopTracing exports the spans still queued
+func (rls *RLS) StopTracing() {
+	if rls.stopTracing == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := rls.stopTracing(ctx); err != nil {
+		rls.logger.Error().Err(err).Msg("RLS: failed to flush traces")
+	}
+}
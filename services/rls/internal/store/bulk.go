@@ -0,0 +1,121 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-edge-enforcement/services/rls/internal/limits"
+	"github.com/redis/go-redis/v9"
+)
+
+// limitsClaimTTL is how long the claim on a limits set version is kept; replicas get the same version well within it
+const limitsClaimTTL = 24 * time.Hour
+
+// 🔧 NEW: Bulk write methods for MemoryStore
+func (m *MemoryStore) SetTenants(ctx context.Context, tenants map[string]*TenantData) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for tenantID, data := range tenants {
+		data.UpdatedAt = now
+		if data.CreatedAt.IsZero() {
+			data.CreatedAt = now
+		}
+		m.tenants[tenantID] = data
+	}
+	m.logger.Debug().Int("tenants", len(tenants)).Msg("stored tenants in memory")
+	return nil
+}
+
+func (m *MemoryStore) AppendAuditEvents(ctx context.Context, events []*limits.AuditEvent) error {
+	for _, event := range events {
+		if err := m.AppendAuditEvent(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClaimLimitsVersion always succeeds: a memory store is private to its replica
+func (m *MemoryStore) ClaimLimitsVersion(ctx context.Context, version string) (bool, error) {
+	return true, nil
+}
+
+func (m *MemoryStore) ReleaseLimitsVersion(ctx context.Context, version string) error {
+	return nil
+}
+
+// 🔧 NEW: Bulk write methods for RedisStore, one pipeline per call instead of a round trip per tenant
+func (r *RedisStore) SetTenants(ctx context.Context, tenants map[string]*TenantData) error {
+	now := time.Now()
+	pipe := r.client.Pipeline()
+	for tenantID, data := range tenants {
+		data.UpdatedAt = now
+		if data.CreatedAt.IsZero() {
+			data.CreatedAt = now
+		}
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("marshal error for tenant %s: %w", tenantID, err)
+		}
+		// Same 24-hour expiration as SetTenant
+		pipe.Set(ctx, r.prefix+tenantID, jsonData, 24*time.Hour)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("redis set tenants error: %w", err)
+	}
+
+	r.logger.Debug().Int("tenants", len(tenants)).Msg("stored tenants in redis")
+	return nil
+}
+
+func (r *RedisStore) AppendAuditEvents(ctx context.Context, events []*limits.AuditEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	// Revisions first, since each event carries its own
+	pipe := r.client.Pipeline()
+	revisions := make([]*redis.IntCmd, len(events))
+	for i, event := range events {
+		revisions[i] = pipe.Incr(ctx, fmt.Sprintf("rls:audit:revision:%s", event.TenantID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("redis incr audit revisions error: %w", err)
+	}
+
+	pipe = r.client.Pipeline()
+	for i, event := range events {
+		event.Revision = revisions[i].Val()
+		jsonData, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("marshal error: %w", err)
+		}
+		key := fmt.Sprintf("rls:audit:%s", event.TenantID)
+		pipe.LPush(ctx, key, jsonData)
+		pipe.LTrim(ctx, key, 0, maxAuditEventsPerTenant-1)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("redis append audit events error: %w", err)
+	}
+	return nil
+}
+
+// ClaimLimitsVersion lets exactly one replica write a limits set to the shared store
+func (r *RedisStore) ClaimLimitsVersion(ctx context.Context, version string) (bool, error) {
+	claimed, err := r.client.SetNX(ctx, "rls:limits:claim:"+version, time.Now().UnixMilli(), limitsClaimTTL).Result()
+	if err != nil {
+		return false, fmt.Errorf("redis claim limits version error: %w", err)
+	}
+	return claimed, nil
+}
+
+// ReleaseLimitsVersion gives up a claim whose writes failed, so the next replica sent the version writes it
+func (r *RedisStore) ReleaseLimitsVersion(ctx context.Context, version string) error {
+	if err := r.client.Del(ctx, "rls:limits:claim:"+version).Err(); err != nil {
+		return fmt.Errorf("redis release limits version error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// forensicsModesKey is the Redis hash of the tenants with forensic capture switched on: tenant -> enabled until in
+// Unix milliseconds, 0 while enabled indefinitely
+const forensicsModesKey = "rls:forensics:enabled"
+
+// 🔧 NEW: Forensic mode methods for MemoryStore
+func (m *MemoryStore) SetForensicsMode(ctx context.Context, tenantID string, until time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.forensicsModes[tenantID] = until
+	return nil
+}
+
+func (m *MemoryStore) DeleteForensicsMode(ctx context.Context, tenantID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.forensicsModes, tenantID)
+	return nil
+}
+
+func (m *MemoryStore) ListForensicsModes(ctx context.Context) (map[string]time.Time, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	modes := make(map[string]time.Time, len(m.forensicsModes))
+	for tenantID, until := range m.forensicsModes {
+		modes[tenantID] = until
+	}
+	return modes, nil
+}
+
+// 🔧 NEW: Forensic mode methods for RedisStore, shared by every replica
+func (r *RedisStore) SetForensicsMode(ctx context.Context, tenantID string, until time.Time) error {
+	var value int64
+	if !until.IsZero() {
+		value = until.UnixMilli()
+	}
+	if err := r.client.HSet(ctx, forensicsModesKey, tenantID, value).Err(); err != nil {
+		return fmt.Errorf("redis set forensics mode error: %w", err)
+	}
+	return nil
+}
+
+func (r *RedisStore) DeleteForensicsMode(ctx context.Context, tenantID string) error {
+	if err := r.client.HDel(ctx, forensicsModesKey, tenantID).Err(); err != nil {
+		return fmt.Errorf("redis delete forensics mode error: %w", err)
+	}
+	return nil
+}
+
+func (r *RedisStore) ListForensicsModes(ctx context.Context) (map[string]time.Time, error) {
+	values, err := r.client.HGetAll(ctx, forensicsModesKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis list forensics modes error: %w", err)
+	}
+
+	modes := make(map[string]time.Time, len(values))
+	for tenantID, value := range values {
+		millis, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			r.logger.Warn().Err(err).Str("tenant_id", tenantID).Msg("skipping unreadable forensics mode")
+			continue
+		}
+		var until time.Time
+		if millis > 0 {
+			until = time.UnixMilli(millis)
+		}
+		modes[tenantID] = until
+	}
+	return modes, nil
+}
@@ -0,0 +1,183 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// haClusterStaleAfter is how long a cluster may send nothing before it no longer counts towards ha_max_clusters
+// and its election is forgotten, like the cleanup of Mimir's HA tracker
+const haClusterStaleAfter = 30 * time.Minute
+
+// ErrTooManyHAClusters is returned when electing a replica for a new cluster would exceed the tenant's ha_max_clusters
+var ErrTooManyHAClusters = errors.New("too many HA clusters")
+
+// HAReplica is the elected replica of one HA cluster of a tenant
+type HAReplica struct {
+	Cluster   string    `json:"cluster"`
+	Replica   string    `json:"replica"`
+	ElectedAt time.Time `json:"elected_at"`
+	LastSeen  time.Time `json:"last_seen"` // last time the elected replica's traffic was recorded
+}
+
+// electHAReplica applies an election to the current state of a cluster: the elected replica is refreshed, and
+// another replica takes over once the elected one has not been seen for failoverTimeout
+func electHAReplica(current *HAReplica, cluster, replica string, now time.Time, failoverTimeout time.Duration) HAReplica {
+	switch {
+	case current == nil || now.Sub(current.LastSeen) > failoverTimeout:
+		return HAReplica{Cluster: cluster, Replica: replica, ElectedAt: now, LastSeen: now}
+	case current.Replica == replica:
+		elected := *current
+		elected.LastSeen = now
+		return elected
+	default:
+		return *current
+	}
+}
+
+// 🔧 NEW: HA replica election methods for MemoryStore
+func (m *MemoryStore) ElectHAReplica(ctx context.Context, tenantID, cluster, replica string, now time.Time, failoverTimeout time.Duration, maxClusters int) (HAReplica, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	clusters := m.haReplicas[tenantID]
+	if clusters == nil {
+		clusters = make(map[string]*HAReplica)
+		m.haReplicas[tenantID] = clusters
+	}
+	current := clusters[cluster]
+	if current == nil && maxClusters > 0 {
+		for name, elected := range clusters {
+			if now.Sub(elected.LastSeen) > haClusterStaleAfter {
+				delete(clusters, name)
+			}
+		}
+		if len(clusters) >= maxClusters {
+			return HAReplica{}, ErrTooManyHAClusters
+		}
+	}
+
+	elected := electHAReplica(current, cluster, replica, now, failoverTimeout)
+	clusters[cluster] = &elected
+	return elected, nil
+}
+
+func (m *MemoryStore) ListHAReplicas(ctx context.Context, tenantID string) ([]HAReplica, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	replicas := make([]HAReplica, 0, len(m.haReplicas[tenantID]))
+	for _, elected := range m.haReplicas[tenantID] {
+		replicas = append(replicas, *elected)
+	}
+	sortHAReplicas(replicas)
+	return replicas, nil
+}
+
+// electHAReplicaScript elects a replica atomically. The tenant's clusters are fields of one hash holding
+// "electedAtMs|lastSeenMs|replica". It returns the elected replica's field, or false for too many clusters.
+var electHAReplicaScript = redis.NewScript(`
+local key, cluster, replica = KEYS[1], ARGV[1], ARGV[2]
+local nowMs = ARGV[3]
+local now, failover, maxClusters, staleAfter = tonumber(ARGV[3]), tonumber(ARGV[4]), tonumber(ARGV[5]), tonumber(ARGV[6])
+
+local current = redis.call('HGET', key, cluster)
+local elected
+if current then
+  local electedAt, lastSeen, currentReplica = string.match(current, '^(%d+)|(%d+)|(.*)$')
+  if now - tonumber(lastSeen) > failover then
+    elected = nowMs .. '|' .. nowMs .. '|' .. replica
+  elseif currentReplica == replica then
+    elected = electedAt .. '|' .. nowMs .. '|' .. replica
+  else
+    return current
+  end
+else
+  if maxClusters > 0 then
+    local all = redis.call('HGETALL', key)
+    for i = 1, #all, 2 do
+      local lastSeen = string.match(all[i + 1], '^%d+|(%d+)|')
+      if now - tonumber(lastSeen) > staleAfter then
+        redis.call('HDEL', key, all[i])
+      end
+    end
+    if redis.call('HLEN', key) >= maxClusters then
+      return false
+    end
+  end
+  elected = nowMs .. '|' .. nowMs .. '|' .. replica
+end
+
+redis.call('HSET', key, cluster, elected)
+redis.call('PEXPIRE', key, staleAfter)
+return elected
+`)
+
+// 🔧 NEW: HA replica election methods for RedisStore, shared by every RLS replica
+func (r *RedisStore) ElectHAReplica(ctx context.Context, tenantID, cluster, replica string, now time.Time, failoverTimeout time.Duration, maxClusters int) (HAReplica, error) {
+	key := fmt.Sprintf("rls:ha:%s", tenantID)
+	result, err := electHAReplicaScript.Run(ctx, r.client, []string{key},
+		cluster, replica, now.UnixMilli(), failoverTimeout.Milliseconds(), maxClusters, haClusterStaleAfter.Milliseconds(),
+	).Text()
+	if err == redis.Nil {
+		return HAReplica{}, ErrTooManyHAClusters
+	}
+	if err != nil {
+		return HAReplica{}, fmt.Errorf("redis elect HA replica error: %w", err)
+	}
+	return parseHAReplica(cluster, result)
+}
+
+func (r *RedisStore) ListHAReplicas(ctx context.Context, tenantID string) ([]HAReplica, error) {
+	key := fmt.Sprintf("rls:ha:%s", tenantID)
+	entries, err := r.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis list HA replicas error: %w", err)
+	}
+
+	replicas := make([]HAReplica, 0, len(entries))
+	for cluster, entry := range entries {
+		elected, err := parseHAReplica(cluster, entry)
+		if err != nil {
+			r.logger.Error().Err(err).Str("tenant_id", tenantID).Msg("failed to parse HA replica")
+			continue
+		}
+		replicas = append(replicas, elected)
+	}
+	sortHAReplicas(replicas)
+	return replicas, nil
+}
+
+// parseHAReplica decodes "electedAtMs|lastSeenMs|replica"
+func parseHAReplica(cluster, entry string) (HAReplica, error) {
+	parts := strings.SplitN(entry, "|", 3)
+	if len(parts) != 3 {
+		return HAReplica{}, fmt.Errorf("invalid HA replica entry %q for cluster %s", entry, cluster)
+	}
+	electedAt, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return HAReplica{}, fmt.Errorf("invalid HA replica entry %q for cluster %s: %w", entry, cluster, err)
+	}
+	lastSeen, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return HAReplica{}, fmt.Errorf("invalid HA replica entry %q for cluster %s: %w", entry, cluster, err)
+	}
+	return HAReplica{
+		Cluster:   cluster,
+		Replica:   parts[2],
+		ElectedAt: time.UnixMilli(electedAt),
+		LastSeen:  time.UnixMilli(lastSeen),
+	}, nil
+}
+
+func sortHAReplicas(replicas []HAReplica) {
+	sort.Slice(replicas, func(i, j int) bool {
+		return replicas[i].Cluster < replicas[j].Cluster
+	})
+}
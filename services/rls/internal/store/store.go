@@ -38,6 +38,28 @@ type Store interface {
 	IsSeriesHashExists(ctx context.Context, tenantID, metricName, seriesHash string) (bool, error)
 	GetSeriesHashes(ctx context.Context, tenantID, metricName string) ([]string, error)
 
+	// 🔧 NEW: Audit log operations
+	AppendAuditEvent(ctx context.Context, event *limits.AuditEvent) error
+	ListAuditEvents(ctx context.Context, tenantID string, limit int) ([]limits.AuditEvent, error)
+
+	// 🔧 NEW: Bulk writes of a limits set. Every replica is sent the same set, so only the replica that claims its
+	// version writes it; a replica whose writes fail releases the claim.
+	SetTenants(ctx context.Context, tenants map[string]*TenantData) error
+	AppendAuditEvents(ctx context.Context, events []*limits.AuditEvent) error
+	ClaimLimitsVersion(ctx context.Context, version string) (bool, error)
+	ReleaseLimitsVersion(ctx context.Context, version string) error
+
+	// 🔧 NEW: HA replica election per tenant and cluster, like Mimir's HA tracker.
+	// ElectHAReplica records traffic of replica and returns the cluster's elected replica, electing replica when
+	// there is none or the elected one has not been seen for failoverTimeout.
+	ElectHAReplica(ctx context.Context, tenantID, cluster, replica string, now time.Time, failoverTimeout time.Duration, maxClusters int) (HAReplica, error)
+	ListHAReplicas(ctx context.Context, tenantID string) ([]HAReplica, error)
+
+	// 🔧 NEW: Tenants with forensic capture switched on, until a time or indefinitely when it is zero
+	SetForensicsMode(ctx context.Context, tenantID string, until time.Time) error
+	DeleteForensicsMode(ctx context.Context, tenantID string) error
+	ListForensicsModes(ctx context.Context) (map[string]time.Time, error)
+
 	// Health check
 	Ping(ctx context.Context) error
 
@@ -53,8 +75,20 @@ type TenantData struct {
 	Enforcement limits.EnforcementConfig `json:"enforcement"`
 	CreatedAt   time.Time                `json:"created_at"`
 	UpdatedAt   time.Time                `json:"updated_at"`
+	// 🔧 NEW: Temporary override layered on top of Limits until it expires
+	TemporaryOverride *limits.TemporaryOverride `json:"temporary_override,omitempty"`
+	// 🔧 NEW: Limit profile and the fields explicitly set for the tenant; Limits holds the merged result
+	Profile   string                 `json:"profile,omitempty"`
+	Overrides *limits.LimitOverrides `json:"overrides,omitempty"`
+	// 🔧 NEW: Edge-only settings from an EdgeEnforcementPolicy
+	Policy *limits.EdgePolicy `json:"policy,omitempty"`
+	// 🔧 NEW: The tenant's own enforcement; Enforcement holds the policy's while the policy sets one
+	TenantEnforcement *limits.EnforcementConfig `json:"tenant_enforcement,omitempty"`
 }
 
+// maxAuditEventsPerTenant bounds the audit history retained for each tenant
+const maxAuditEventsPerTenant = 500
+
 // MemoryStore implements Store interface using in-memory storage
 type MemoryStore struct {
 	tenants map[string]*TenantData
@@ -65,6 +99,16 @@ type MemoryStore struct {
 	metricSeriesCounts map[string]map[string]int64           // tenantID -> metricName -> count
 	seriesHashes       map[string]map[string]map[string]bool // tenantID -> metricName -> hash -> exists
 	mu                 sync.RWMutex                          // Protect concurrent access
+
+	// 🔧 NEW: Memory-based audit log
+	auditEvents    map[string][]limits.AuditEvent // tenantID -> events (oldest first)
+	auditRevisions map[string]int64               // tenantID -> last assigned revision
+
+	// 🔧 NEW: Memory-based HA replica election
+	haReplicas map[string]map[string]*HAReplica // tenantID -> cluster -> elected replica
+
+	// 🔧 NEW: Memory-based forensic modes
+	forensicsModes map[string]time.Time // tenantID -> enabled until; zero means indefinitely
 }
 
 // NewMemoryStore creates a new in-memory store
@@ -75,6 +119,10 @@ func NewMemoryStore(logger zerolog.Logger) *MemoryStore {
 		globalSeriesCounts: make(map[string]int64),
 		metricSeriesCounts: make(map[string]map[string]int64),
 		seriesHashes:       make(map[string]map[string]map[string]bool),
+		auditEvents:        make(map[string][]limits.AuditEvent),
+		auditRevisions:     make(map[string]int64),
+		haReplicas:         make(map[string]map[string]*HAReplica),
+		forensicsModes:     make(map[string]time.Time),
 	}
 }
 
@@ -221,6 +269,37 @@ func (m *MemoryStore) GetSeriesHashes(ctx context.Context, tenantID, metricName
 	return hashes, nil
 }
 
+// 🔧 NEW: Audit log methods for MemoryStore
+func (m *MemoryStore) AppendAuditEvent(ctx context.Context, event *limits.AuditEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.auditRevisions[event.TenantID]++
+	event.Revision = m.auditRevisions[event.TenantID]
+
+	events := append(m.auditEvents[event.TenantID], *event)
+	if len(events) > maxAuditEventsPerTenant {
+		events = events[len(events)-maxAuditEventsPerTenant:]
+	}
+	m.auditEvents[event.TenantID] = events
+	return nil
+}
+
+func (m *MemoryStore) ListAuditEvents(ctx context.Context, tenantID string, limit int) ([]limits.AuditEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	events := m.auditEvents[tenantID]
+	if limit <= 0 || limit > len(events) {
+		limit = len(events)
+	}
+
+	// Return newest first
+	result := make([]limits.AuditEvent, 0, limit)
+	for i := len(events) - 1; i >= 0 && len(result) < limit; i-- {
+		result = append(result, events[i])
+	}
+	return result, nil
+}
+
 // RedisStore implements Store interface using Redis
 type RedisStore struct {
 	client *redis.Client
@@ -466,6 +545,54 @@ func (r *RedisStore) GetSeriesHashes(ctx context.Context, tenantID, metricName s
 	return hashes, nil
 }
 
+// 🔧 NEW: Audit log methods for RedisStore
+func (r *RedisStore) AppendAuditEvent(ctx context.Context, event *limits.AuditEvent) error {
+	revision, err := r.client.Incr(ctx, fmt.Sprintf("rls:audit:revision:%s", event.TenantID)).Result()
+	if err != nil {
+		return fmt.Errorf("redis incr audit revision error: %w", err)
+	}
+	event.Revision = revision
+
+	jsonData, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+
+	// Newest events are kept at the head of the list
+	key := fmt.Sprintf("rls:audit:%s", event.TenantID)
+	pipe := r.client.TxPipeline()
+	pipe.LPush(ctx, key, jsonData)
+	pipe.LTrim(ctx, key, 0, maxAuditEventsPerTenant-1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("redis append audit event error: %w", err)
+	}
+	return nil
+}
+
+func (r *RedisStore) ListAuditEvents(ctx context.Context, tenantID string, limit int) ([]limits.AuditEvent, error) {
+	stop := int64(-1)
+	if limit > 0 {
+		stop = int64(limit - 1)
+	}
+
+	key := fmt.Sprintf("rls:audit:%s", tenantID)
+	entries, err := r.client.LRange(ctx, key, 0, stop).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis list audit events error: %w", err)
+	}
+
+	events := make([]limits.AuditEvent, 0, len(entries))
+	for _, entry := range entries {
+		var event limits.AuditEvent
+		if err := json.Unmarshal([]byte(entry), &event); err != nil {
+			r.logger.Error().Err(err).Str("tenant_id", tenantID).Msg("failed to unmarshal audit event")
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
 // NewStore creates a new store based on the backend type
 func NewStore(backend, redisAddr string, logger zerolog.Logger) (Store, error) {
 	switch backend {
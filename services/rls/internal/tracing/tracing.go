@@ -0,0 +1,182 @@
+// Package tracing sets up OpenTelemetry tracing for RLS. W3C trace context is always propagated; spans are
+// recorded and exported over OTLP only when an endpoint is configured, otherwise the global tracer provider stays
+// the no-op default and starting a span costs next to nothing.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// scopeName is the instrumentation scope of every RLS span
+const scopeName = "github.com/AkshayDubey29/mimir-edge-enforcement/services/rls"
+
+// OTLP protocols
+const (
+	ProtocolHTTP = "http/protobuf"
+	ProtocolGRPC = "grpc"
+)
+
+// Config selects the OTLP endpoint and sampling
+type Config struct {
+	Endpoint      string            // OTLP endpoint URL, e.g. http://otel-collector:4318/v1/traces or http://otel-collector:4317 for grpc; empty disables export
+	Protocol      string            // ProtocolHTTP (default) or ProtocolGRPC
+	Headers       map[string]string // sent with every export, e.g. authentication
+	SampleRatio   float64           // fraction of new traces sampled; requests with a parent follow its sampled flag
+	ServiceName   string
+	QueueSize     int // finished spans buffered before new ones are dropped
+	BatchSize     int
+	FlushInterval time.Duration
+	Timeout       time.Duration
+}
+
+// Metrics counts exported and failed spans
+type Metrics struct {
+	Spans *prometheus.CounterVec // labels: result (exported, failed)
+}
+
+func init() {
+	// Propagate trace context even while no spans are recorded, so RLS does not break traces passing through it
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Setup installs a tracer provider exporting over OTLP. Without an endpoint it leaves the no-op provider in place
+// and returns a nil shutdown function; otherwise shutdown exports the spans still queued.
+func Setup(config Config, metrics Metrics, logger zerolog.Logger) (shutdown func(context.Context) error, err error) {
+	if config.Endpoint == "" {
+		return nil, nil
+	}
+	config.SampleRatio = min(max(config.SampleRatio, 0), 1)
+	if config.ServiceName == "" {
+		config.ServiceName = "mimir-rls"
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = sdktrace.DefaultMaxQueueSize
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = sdktrace.DefaultMaxExportBatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 5 * time.Second
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	exporter, err := newExporter(config)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attribute.String("service.name", config.ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+		sdktrace.WithBatcher(countingExporter{SpanExporter: exporter, spans: metrics.Spans},
+			sdktrace.WithMaxQueueSize(config.QueueSize),
+			sdktrace.WithMaxExportBatchSize(config.BatchSize),
+			sdktrace.WithBatchTimeout(config.FlushInterval),
+			sdktrace.WithExportTimeout(config.Timeout),
+		),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logger.Error().Err(err).Msg("tracing: OpenTelemetry error")
+	}))
+	return provider.Shutdown, nil
+}
+
+// newExporter creates the OTLP exporter for the configured protocol. A plain http:// endpoint disables TLS.
+func newExporter(config Config) (sdktrace.SpanExporter, error) {
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q: expected a URL such as http://otel-collector:4318/v1/traces", config.Endpoint)
+	}
+	insecure := endpoint.Scheme == "http"
+
+	switch config.Protocol {
+	case "", ProtocolHTTP:
+		options := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(endpoint.Host),
+			otlptracehttp.WithHeaders(config.Headers),
+			otlptracehttp.WithTimeout(config.Timeout),
+		}
+		if endpoint.Path != "" {
+			options = append(options, otlptracehttp.WithURLPath(endpoint.Path))
+		}
+		if insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(context.Background(), options...)
+	case ProtocolGRPC:
+		options := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(endpoint.Host),
+			otlptracegrpc.WithHeaders(config.Headers),
+			otlptracegrpc.WithTimeout(config.Timeout),
+		}
+		if insecure {
+			options = append(options, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(context.Background(), options...)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %q: expected %s or %s", config.Protocol, ProtocolHTTP, ProtocolGRPC)
+	}
+}
+
+// countingExporter counts the spans each export carries by result
+type countingExporter struct {
+	sdktrace.SpanExporter
+	spans *prometheus.CounterVec
+}
+
+func (e countingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	if e.spans != nil {
+		result := "exported"
+		if err != nil {
+			result = "failed"
+		}
+		e.spans.WithLabelValues(result).Add(float64(len(spans)))
+	}
+	return err
+}
+
+// Start begins a span as a child of the span or remote context in ctx, or as a new root
+func Start(ctx context.Context, name string, kind trace.SpanKind, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(scopeName).Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attributes...))
+}
+
+// RecordError records err on the span and marks the span as failed
+func RecordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// Extract returns a context carrying the remote span context found in the carrier, if any
+func Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
+
+// Inject writes the span context of ctx into outgoing headers. Without a recording span the extracted remote
+// context is passed through, so propagation works even when tracing is disabled.
+func Inject(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}